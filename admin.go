@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/utils"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// minAdminPasswordLength 管理员密码的最小长度
+const minAdminPasswordLength = 8
+
+var createAdminCommand = &cli.Command{
+	Name:  "create-admin",
+	Usage: "交互式创建管理员，未通过参数提供的信息会提示输入",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "username", Usage: "用户名"},
+		&cli.StringFlag{Name: "email", Usage: "邮箱"},
+		&cli.StringFlag{Name: "name", Usage: "姓名"},
+	},
+	Action: createAdmin,
+}
+
+func createAdmin(c *cli.Context) error {
+	in := bufio.NewReader(os.Stdin)
+	out := c.App.Writer
+
+	user := &models.User{
+		Username: c.String("username"),
+		Email:    c.String("email"),
+		Name:     c.String("name"),
+	}
+	var err error
+	if user.Username == "" {
+		if user.Username, err = prompt(in, out, "Username: "); err != nil {
+			return err
+		}
+	}
+	if user.Email == "" {
+		if user.Email, err = prompt(in, out, "Email: "); err != nil {
+			return err
+		}
+	}
+	if !c.IsSet("name") {
+		if user.Name, err = prompt(in, out, "Name (optional): "); err != nil {
+			return err
+		}
+	}
+	if err := utils.Validate(user); err != nil {
+		return describeError(err)
+	}
+
+	password, err := promptPassword(in, out)
+	if err != nil {
+		return err
+	}
+	if user.Password, err = utils.HashPassword(password); err != nil {
+		return err
+	}
+
+	if err := setupDatabase(true); err != nil {
+		return err
+	}
+
+	userService := services.NewUserService()
+	err = userService.Transaction(c.Context, func(ctx context.Context) error {
+		if err := userService.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return userService.AssignRole(ctx, user.ID, models.RoleAdmin)
+	})
+	if err != nil {
+		return describeError(err)
+	}
+
+	fmt.Fprintf(out, "admin user %q created (id %d)\n", user.Username, user.ID)
+	return nil
+}
+
+// prompt 输出提示并读取一行输入
+func prompt(in *bufio.Reader, out io.Writer, label string) (string, error) {
+	fmt.Fprint(out, label)
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// promptPassword 读取密码；标准输入为终端时不回显并要求再次输入确认，否则读取一行（便于脚本通过管道传入）
+func promptPassword(in *bufio.Reader, out io.Writer) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		password, err := prompt(in, out, "Password: ")
+		if err != nil {
+			return "", err
+		}
+		return password, checkPassword(password)
+	}
+
+	fmt.Fprint(out, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	if err := checkPassword(string(password)); err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(out, "Confirm password: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	if string(confirm) != string(password) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(password), nil
+}
+
+func checkPassword(password string) error {
+	if len(password) < minAdminPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minAdminPasswordLength)
+	}
+	return nil
+}
+
+// describeError 将 AppError 的消息和字段错误转换为命令行可读的错误
+func describeError(err error) error {
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) {
+		return err
+	}
+	if fields, ok := appErr.Details.([]utils.FieldError); ok {
+		messages := make([]string, len(fields))
+		for i, field := range fields {
+			messages[i] = field.Field + ": " + field.Rule
+		}
+		return fmt.Errorf("%s（%s）", appErr.Message(), strings.Join(messages, ", "))
+	}
+	if appErr.Err != nil {
+		return fmt.Errorf("%s: %w", appErr.Message(), appErr.Err)
+	}
+	return errors.New(appErr.Message())
+}
@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyController() *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: services.NewAPIKeyService(),
+	}
+}
+
+// CreateAPIKeyRequest 创建 API Key 请求
+// @Description 创建 API Key 请求；未指定所有者时属于调用方自己，只有管理员可以为其他用户或服务账号创建
+type CreateAPIKeyRequest struct {
+	Name           string     `json:"name" example:"nightly-export" binding:"required,max=100"`                             // 名称
+	UserID         *uint      `json:"user_id" example:"1" extensions:"x-nullable"`                                          // 所属用户ID
+	ServiceAccount string     `json:"service_account" example:"billing-sync" binding:"omitempty,max=64"`                    // 所属服务账号，与 user_id 二选一
+	Role           string     `json:"role" example:"user" binding:"omitempty,oneof=user admin"`                             // 服务账号的角色，默认 user
+	Scopes         []string   `json:"scopes" example:"users:read" binding:"required,min=1"`                                 // 权限范围：<资源>:read、<资源>:write 或 *
+	ExpiresAt      *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z" format:"date-time" extensions:"x-nullable"` // 过期时间，未指定时按 API_KEY_DEFAULT_TTL 设置
+}
+
+// UpdateAPIKeyRequest 更新 API Key 请求
+// @Description 更新 API Key 请求，所有者、角色和密钥本身不能修改
+type UpdateAPIKeyRequest struct {
+	Name      string     `json:"name" example:"nightly-export" binding:"required,max=100"`                             // 名称
+	Scopes    []string   `json:"scopes" example:"users:read" binding:"required,min=1"`                                 // 权限范围
+	ExpiresAt *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z" format:"date-time" extensions:"x-nullable"` // 过期时间，null 为不过期
+	Version   uint       `json:"version" example:"1" binding:"required"`                                               // 获取密钥时返回的版本号
+}
+
+// CreatedAPIKey 新创建的 API Key
+// @Description 新创建的 API Key，key 为完整密钥，只在创建时返回这一次
+type CreatedAPIKey struct {
+	models.APIKey
+	Key string `json:"key" example:"etk_3f9a1c2b_Jx2bX0sQ4vTq"` // 完整密钥
+}
+
+// apiKeyOwner 调用方可以管理的密钥范围：管理员不限，用户只能管理自己的密钥，服务账号只能管理本账号的密钥
+func apiKeyOwner(c echo.Context) (services.APIKeyOwner, error) {
+	if utils.HasRole(c, models.RoleAdmin) {
+		return services.APIKeyOwner{}, nil
+	}
+	if id, ok := utils.CurrentUserID(c); ok {
+		return services.APIKeyOwner{UserID: id}, nil
+	}
+	if key, ok := utils.CurrentAPIKey(c); ok {
+		return services.APIKeyOwner{ServiceAccount: key.ServiceAccount}, nil
+	}
+	return services.APIKeyOwner{}, utils.ErrUnauthorized("auth.required")
+}
+
+// checkScopes 通过 API Key 调用时，新的权限范围不能超出该密钥自身的权限
+func checkScopes(c echo.Context, scopes []string) error {
+	if key, ok := utils.CurrentAPIKey(c); ok && !key.Covers(scopes) {
+		return utils.ErrForbidden("api_key.scope_exceeds")
+	}
+	return nil
+}
+
+// GetAPIKeys 获取 API Key 列表
+// @Summary      获取 API Key 列表
+// @ID           listAPIKeys
+// @Description  分页获取未吊销的 API Key，管理员可以看到全部密钥，其他调用方只能看到自己的密钥
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        page       query     int  false  "页码"  default(1)
+// @Param        page_size  query     int  false  "每页数量"  default(20)
+// @Success      200  {object}  utils.Response{data=utils.PageResult{items=[]models.APIKey}}  "成功返回 API Key 列表"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/api-keys [get]
+func (kc *APIKeyController) GetAPIKeys(c echo.Context) error {
+	owner, err := apiKeyOwner(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	keys, total, err := kc.apiKeyService.ListAPIKeys(c.Request().Context(), owner, page)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, utils.NewPageResult(keys, total, page), "api_key.listed")
+}
+
+// GetAPIKey 获取单个 API Key
+// @Summary      获取单个 API Key
+// @ID           getAPIKey
+// @Description  根据ID获取 API Key，不包含密钥本身
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "API Key ID"  example(1)
+// @Success      200  {object}  utils.Response{data=models.APIKey}  "成功返回 API Key"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "API Key 不存在"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/api-keys/{id} [get]
+func (kc *APIKeyController) GetAPIKey(c echo.Context) error {
+	owner, err := apiKeyOwner(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	key, err := kc.apiKeyService.GetAPIKey(c.Request().Context(), owner, id)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, *key, "api_key.fetched")
+}
+
+// CreateAPIKey 创建 API Key
+// @Summary      创建 API Key
+// @ID           createAPIKey
+// @Description  为用户或服务账号生成 API Key，响应中的 key 为完整密钥，只返回这一次；通过 API Key 调用时新密钥的权限范围不能超出调用方密钥
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string               false  "幂等键，重试时携带相同的值不会重复创建"
+// @Param        api_key          body      CreateAPIKeyRequest  true   "API Key 信息"
+// @Success      201  {object}  utils.Response{data=CreatedAPIKey}  "成功创建 API Key"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "无权为其他用户或服务账号创建，或权限范围超出调用方密钥"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/api-keys [post]
+func (kc *APIKeyController) CreateAPIKey(c echo.Context) error {
+	owner, err := apiKeyOwner(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	var req CreateAPIKeyRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+	if err := checkScopes(c, req.Scopes); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	key := models.APIKey{
+		Name:           req.Name,
+		UserID:         req.UserID,
+		ServiceAccount: req.ServiceAccount,
+		Role:           req.Role,
+		Scopes:         req.Scopes,
+		ExpiresAt:      req.ExpiresAt,
+	}
+	if key.UserID == nil && key.ServiceAccount == "" {
+		// 未指定所有者时属于调用方自己
+		if id, ok := utils.CurrentUserID(c); ok {
+			key.UserID = &id
+		} else if current, ok := utils.CurrentAPIKey(c); ok {
+			key.ServiceAccount = current.ServiceAccount
+			key.Role = current.Role
+		}
+	} else if owner != (services.APIKeyOwner{}) {
+		if (key.UserID != nil && *key.UserID != owner.UserID) || key.ServiceAccount != owner.ServiceAccount {
+			return utils.HandleError(c, utils.ErrForbidden("api_key.owner_forbidden"))
+		}
+	}
+	// 只有管理员可以指定服务账号的角色
+	if owner != (services.APIKeyOwner{}) && key.ServiceAccount != "" {
+		key.Role = models.RoleUser
+	}
+
+	plaintext, err := kc.apiKeyService.CreateAPIKey(c.Request().Context(), &key)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.SuccessCreated(c, CreatedAPIKey{APIKey: key, Key: plaintext}, "api_key.created")
+}
+
+// UpdateAPIKey 更新 API Key
+// @Summary      更新 API Key
+// @ID           updateAPIKey
+// @Description  更新名称、权限范围和过期时间，请求体需携带获取密钥时返回的 version，版本不一致时返回 409
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                  true  "API Key ID"  example(1)
+// @Param        api_key  body      UpdateAPIKeyRequest  true  "API Key 信息"
+// @Success      200  {object}  utils.Response{data=models.APIKey}  "成功更新 API Key"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限，或权限范围超出调用方密钥"
+// @Failure      404  {object}  utils.ErrorResponse  "API Key 不存在"
+// @Failure      409  {object}  utils.ErrorResponse{data=map[string]int}  "版本冲突，data.current_version 为当前版本号"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/api-keys/{id} [put]
+func (kc *APIKeyController) UpdateAPIKey(c echo.Context) error {
+	owner, err := apiKeyOwner(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	var req UpdateAPIKeyRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+	if err := checkScopes(c, req.Scopes); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	key := models.APIKey{Name: req.Name, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt}
+	key.ID = id
+	key.Version = req.Version
+	if err := kc.apiKeyService.UpdateAPIKey(c.Request().Context(), owner, &key); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, key, "api_key.updated")
+}
+
+// DeleteAPIKey 吊销 API Key
+// @Summary      吊销 API Key
+// @ID           deleteAPIKey
+// @Description  吊销 API Key，立即失效
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "API Key ID"  example(1)
+// @Success      200  {object}  utils.SuccessResponse  "成功吊销 API Key"
+// @Success      204  "成功吊销 API Key（Prefer: envelope=false 或路由组关闭了响应包装）"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "API Key 不存在"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/api-keys/{id} [delete]
+func (kc *APIKeyController) DeleteAPIKey(c echo.Context) error {
+	owner, err := apiKeyOwner(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	if err := kc.apiKeyService.DeleteAPIKey(c.Request().Context(), owner, id); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.SuccessNoContent(c, "api_key.deleted")
+}
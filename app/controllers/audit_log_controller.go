@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"echo-template/app/services"
+	"echo-template/utils"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditLogController struct {
+	auditLogService services.AuditLogServiceInterface
+}
+
+func NewAuditLogController() *AuditLogController {
+	return &AuditLogController{
+		auditLogService: services.NewAuditLogService(),
+	}
+}
+
+// GetAuditLogs 查询审计日志
+// @Summary      查询审计日志
+// @ID           listAuditLogs
+// @Description  按操作人、API Key、动作、表、记录、请求ID和时间范围分页查询审计日志（仅管理员）
+// @Tags         audit-logs
+// @Accept       json
+// @Produce      json
+// @Param        actor_id    query     int     false  "操作人用户ID"
+// @Param        api_key_id  query     int     false  "使用的 API Key ID"
+// @Param        action      query     string  false  "动作"  Enums(create, update, delete, restore, force_delete)
+// @Param        table       query     string  false  "表名"  example(users)
+// @Param        record_id   query     string  false  "记录主键"
+// @Param        request_id  query     string  false  "请求ID"
+// @Param        from        query     string  false  "起始时间（RFC3339，包含）"
+// @Param        to          query     string  false  "结束时间（RFC3339，不包含）"
+// @Param        page        query     int     false  "页码"  default(1)
+// @Param        page_size   query     int     false  "每页数量"  default(20)
+// @Success      200  {object}  utils.Response{data=utils.PageResult{items=[]models.AuditLog}}  "成功返回审计日志"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "无权访问"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/audit-logs [get]
+func (ac *AuditLogController) GetAuditLogs(c echo.Context) error {
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	filter := services.AuditLogFilter{
+		Action:    c.QueryParam("action"),
+		Table:     c.QueryParam("table"),
+		RecordID:  c.QueryParam("record_id"),
+		RequestID: c.QueryParam("request_id"),
+	}
+	if filter.ActorID, err = parseUintQuery(c, "actor_id"); err != nil {
+		return utils.HandleError(c, err)
+	}
+	if filter.APIKeyID, err = parseUintQuery(c, "api_key_id"); err != nil {
+		return utils.HandleError(c, err)
+	}
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return utils.HandleError(c, err)
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	logs, total, err := ac.auditLogService.ListAuditLogs(c.Request().Context(), filter, page)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, utils.NewPageResult(logs, total, page), "audit_log.listed")
+}
+
+func parseUintQuery(c echo.Context, name string) (uint, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, utils.ErrInvalidParam(name)
+	}
+	return uint(id), nil
+}
+
+func parseTimeQuery(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, utils.ErrInvalidParam(name)
+	}
+	return t, nil
+}
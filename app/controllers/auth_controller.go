@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"echo-template/app/services"
+	"echo-template/config"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 保存加密登录状态的 Cookie，路径限定为所属身份提供方的登录和回调接口
+const oidcStateCookie = "oidc_state"
+
+type AuthController struct {
+	authService services.AuthServiceInterface
+	userService services.UserServiceInterface
+}
+
+func NewAuthController() *AuthController {
+	return &AuthController{
+		authService: services.NewAuthService(),
+		userService: services.NewUserService(),
+	}
+}
+
+// LoginProvider 可用于登录的身份提供方
+// @Description 可用于登录的身份提供方，浏览器打开 login_url 开始登录
+type LoginProvider struct {
+	services.OIDCProvider
+	LoginURL string `json:"login_url" example:"/api/v1/auth/oidc/corp/login"` // 登录地址
+}
+
+// OIDCLink 关联身份提供方的授权地址
+// @Description 在发起关联的浏览器中打开 auth_url，身份提供方登录后回调完成关联
+type OIDCLink struct {
+	AuthURL string `json:"auth_url" example:"https://sso.example.com/authorize?client_id=echo-template&state=..."` // 身份提供方的授权地址
+}
+
+// oidcStatePath 登录状态 Cookie 的路径
+func oidcStatePath(provider string) string {
+	return "/api/v1/auth/oidc/" + provider + "/"
+}
+
+// setOIDCState 保存加密的登录状态；身份提供方通过顶层跳转回调，SameSite=Lax 时浏览器会携带该 Cookie
+func setOIDCState(c echo.Context, provider, value string, maxAge int) {
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oidcStatePath(provider),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// GetProviders 获取身份提供方列表
+// @Summary      获取身份提供方列表
+// @ID           listLoginProviders
+// @Description  获取已配置的 OIDC 身份提供方，未配置时为空列表
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  utils.Response{data=[]LoginProvider}  "成功返回身份提供方列表"
+// @Router       /v1/auth/providers [get]
+func (ac *AuthController) GetProviders(c echo.Context) error {
+	providers := ac.authService.Providers()
+	list := make([]LoginProvider, 0, len(providers))
+	for _, provider := range providers {
+		list = append(list, LoginProvider{
+			OIDCProvider: provider,
+			LoginURL:     oidcStatePath(provider.Name) + "login",
+		})
+	}
+	return utils.Success(c, list, "auth.providers_listed")
+}
+
+// Login 跳转到身份提供方登录
+// @Summary      跳转到身份提供方登录
+// @ID           startOIDCLogin
+// @Description  使用授权码 + PKCE 流程登录：生成 state、nonce 和 PKCE 校验码，加密后保存在 Cookie 中，并跳转到身份提供方的授权地址。需要在浏览器中打开
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        provider  path  string  true  "身份提供方名称"  example(corp)
+// @Success      302  "跳转到身份提供方的授权地址"
+// @Header       302  {string}  Location  "身份提供方的授权地址"
+// @Failure      404  {object}  utils.ErrorResponse  "身份提供方不存在"
+// @Failure      502  {object}  utils.ErrorResponse  "无法访问身份提供方"
+// @Router       /v1/auth/oidc/{provider}/login [get]
+func (ac *AuthController) Login(c echo.Context) error {
+	provider := c.Param("provider")
+	authURL, state, err := ac.authService.BeginLogin(c.Request().Context(), provider)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	setOIDCState(c, provider, state, int(config.AppConfig.Auth.StateTTL.Seconds()))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Link 关联身份提供方
+// @Summary      关联身份提供方
+// @ID           linkOIDCIdentity
+// @Description  已登录的用户发起关联：与登录相同，生成 state、nonce 和 PKCE 校验码并加密保存在 Cookie 中，返回身份提供方的授权地址。在同一浏览器中打开 auth_url，身份提供方登录后回调接口将该身份关联到当前用户（不比较邮箱）并签发访问令牌。管理员不会按邮箱自动关联，须通过此接口关联
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        provider  path  string  true  "身份提供方名称"  example(corp)
+// @Success      200  {object}  utils.Response{data=OIDCLink}  "返回身份提供方的授权地址"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或令牌无效"
+// @Failure      403  {object}  utils.ErrorResponse  "服务账号没有对应的用户"
+// @Failure      404  {object}  utils.ErrorResponse  "身份提供方不存在"
+// @Failure      502  {object}  utils.ErrorResponse  "无法访问身份提供方"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/auth/oidc/{provider}/link [post]
+func (ac *AuthController) Link(c echo.Context) error {
+	id, ok := utils.CurrentUserID(c)
+	if !ok {
+		return utils.HandleError(c, utils.ErrForbidden("auth.user_required"))
+	}
+
+	provider := c.Param("provider")
+	authURL, state, err := ac.authService.BeginLink(c.Request().Context(), provider, id)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	setOIDCState(c, provider, state, int(config.AppConfig.Auth.StateTTL.Seconds()))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return utils.Success(c, OIDCLink{AuthURL: authURL}, "auth.oidc_link_started")
+}
+
+// Callback 身份提供方登录回调
+// @Summary      身份提供方登录回调
+// @ID           completeOIDCLogin
+// @Description  身份提供方登录后跳转到此接口（OIDC_<NAME>_REDIRECT_URL）。校验 state 与 Cookie 中的登录状态一致，用授权码和 PKCE 校验码换取 ID Token，校验签名、签发者、受众、有效期和 nonce 后映射为本地用户：已关联的身份直接登录，已验证的邮箱关联已有的普通用户（管理员须显式关联），否则按配置自动创建用户；通过关联接口发起时关联到发起关联的用户。成功后签发访问令牌
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        provider  path   string  true   "身份提供方名称"  example(corp)
+// @Param        code      query  string  false  "授权码"
+// @Param        state     query  string  true   "发起登录时生成的 state"
+// @Param        error     query  string  false  "身份提供方返回的错误，例如 access_denied"
+// @Success      200  {object}  utils.Response{data=services.LoginResult}  "登录成功，返回访问令牌"
+// @Failure      400  {object}  utils.ErrorResponse  "state 无效或登录已过期"
+// @Failure      401  {object}  utils.ErrorResponse  "身份提供方拒绝登录或 ID Token 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "用户已停用或不允许自动注册"
+// @Failure      404  {object}  utils.ErrorResponse  "身份提供方不存在"
+// @Failure      409  {object}  utils.ErrorResponse  "邮箱已被未关联的用户或管理员使用，或身份已关联其他用户"
+// @Failure      502  {object}  utils.ErrorResponse  "无法访问身份提供方"
+// @Router       /v1/auth/oidc/{provider}/callback [get]
+func (ac *AuthController) Callback(c echo.Context) error {
+	provider := c.Param("provider")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	// 登录状态只能使用一次，无论成功与否都清除
+	cookie, err := c.Cookie(oidcStateCookie)
+	sealed := ""
+	if err == nil {
+		sealed = cookie.Value
+		setOIDCState(c, provider, "", -1)
+	}
+
+	if reason := c.QueryParam("error"); reason != "" {
+		return utils.HandleError(c, utils.ErrUnauthorized("auth.oidc_denied", i18n.Args{"Reason": reason}))
+	}
+
+	result, err := ac.authService.CompleteLogin(c.Request().Context(), provider, sealed, c.QueryParam("state"), c.QueryParam("code"))
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, result, "auth.logged_in")
+}
+
+// GetCurrentUser 获取当前登录的用户
+// @Summary      获取当前登录的用户
+// @ID           getCurrentUser
+// @Description  获取访问令牌或用户的 API Key 所属的用户，服务账号的 API Key 没有对应的用户
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  utils.Response{data=models.User}  "成功返回用户信息"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或令牌无效"
+// @Failure      403  {object}  utils.ErrorResponse  "服务账号没有对应的用户"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/auth/me [get]
+func (ac *AuthController) GetCurrentUser(c echo.Context) error {
+	id, ok := utils.CurrentUserID(c)
+	if !ok {
+		return utils.HandleError(c, utils.ErrForbidden("auth.user_required"))
+	}
+
+	user, err := ac.userService.GetUserByID(c.Request().Context(), id)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, *user, "auth.current_user")
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"echo-template/config"
+	"echo-template/docs"
+	"echo-template/openapi"
+	"echo-template/utils"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"sigs.k8s.io/yaml"
+)
+
+type DocsController struct{}
+
+func NewDocsController() *DocsController {
+	return &DocsController{}
+}
+
+// GetSwaggerDoc 返回 Swagger 2.0 文档，host、basePath 和 schemes 按配置或请求生成
+// 未配置 DOCS_HOST/DOCS_SCHEMES 时取请求的 Host 和协议，来自可信代理的请求采信 X-Forwarded-Host/Proto/Prefix，
+// 这样经过反向代理访问时 Swagger UI 的 "Try it out" 也会请求到正确的地址
+func (dc *DocsController) GetSwaggerDoc(c echo.Context) error {
+	target := docsTargetOf(c)
+
+	// 复制一份再修改，避免并发请求相互覆盖
+	spec := *docs.SwaggerInfo
+	spec.Host = target.host
+	spec.Schemes = target.schemes
+	spec.BasePath = target.basePath
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, []byte(spec.ReadDoc()))
+}
+
+// GetOpenAPIJSON 返回 OpenAPI 3.1 文档（JSON），servers 的生成方式与 Swagger 文档相同
+func (dc *DocsController) GetOpenAPIJSON(c echo.Context) error {
+	doc, err := dc.openAPIDocument(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// GetOpenAPIYAML 返回 OpenAPI 3.1 文档（YAML）
+func (dc *DocsController) GetOpenAPIYAML(c echo.Context) error {
+	doc, err := dc.openAPIDocument(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return utils.HandleError(c, utils.ErrInternal("docs.generate_failed", err))
+	}
+	return c.Blob(http.StatusOK, "application/yaml", data)
+}
+
+func (dc *DocsController) openAPIDocument(c echo.Context) (*openapi3.T, error) {
+	doc, err := openapi.Document()
+	if err != nil {
+		return nil, utils.ErrInternal("docs.generate_failed", err)
+	}
+	target := docsTargetOf(c)
+	urls := make([]string, len(target.schemes))
+	for i, scheme := range target.schemes {
+		urls[i] = scheme + "://" + target.host + target.basePath
+	}
+	return openapi.WithServers(doc, urls...), nil
+}
+
+// docsTarget 文档中 API 的访问地址
+type docsTarget struct {
+	host     string
+	schemes  []string
+	basePath string
+}
+
+func docsTargetOf(c echo.Context) docsTarget {
+	cfg := config.AppConfig.Docs
+	req := c.Request()
+	trusted := config.AppConfig.Security.IsTrustedProxy(req.RemoteAddr)
+
+	target := docsTarget{host: cfg.Host, schemes: cfg.Schemes, basePath: cfg.BasePath}
+	if target.host == "" {
+		target.host = req.Host
+		if host := forwardedHeader(req, "X-Forwarded-Host"); trusted && host != "" {
+			target.host = host
+		}
+	}
+
+	if len(target.schemes) == 0 {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		if proto := forwardedHeader(req, echo.HeaderXForwardedProto); trusted && proto != "" {
+			scheme = strings.ToLower(proto)
+		}
+		target.schemes = []string{scheme}
+	}
+
+	if prefix := forwardedHeader(req, "X-Forwarded-Prefix"); trusted && prefix != "" {
+		target.basePath = "/" + strings.Trim(prefix, "/") + target.basePath
+	}
+	return target
+}
+
+// forwardedHeader 取转发头的第一个值，多级代理时为最靠近客户端的一级
+func forwardedHeader(req *http.Request, name string) string {
+	value, _, _ := strings.Cut(req.Header.Get(name), ",")
+	return strings.TrimSpace(value)
+}
@@ -3,7 +3,15 @@ package controllers
 import (
 	"echo-template/app/models"
 	"echo-template/app/services"
+	"echo-template/config"
+	"echo-template/i18n"
 	"echo-template/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,23 +28,83 @@ func NewUserController() *UserController {
 
 // GetUsers 获取用户列表
 // @Summary      获取用户列表
-// @Description  获取所有用户信息
+// @ID           listUsers
+// @Description  获取所有用户信息，trashed=with 包含已删除用户，trashed=only 仅返回已删除用户（仅管理员）。已弃用，请使用分页的 v2 接口
 // @Tags         users
 // @Accept       json
 // @Produce      json
+// @Param        trashed  query     string  false  "已删除用户的查询范围"  Enums(with, only)
 // @Success      200  {object}  utils.Response{data=[]models.User}  "成功返回用户列表"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限，或非管理员查询已删除用户"
 // @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Deprecated
 // @Router       /v1/users [get]
 func (uc *UserController) GetUsers(c echo.Context) error {
-	users, err := uc.userService.GetAllUsers()
+	trashed, err := parseTrashedFilter(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	users, err := uc.userService.GetAllUsers(c.Request().Context(), trashed)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.Success(c, users, "user.listed")
+}
+
+// parseTrashedFilter 解析 trashed 查询参数，只有管理员可以查询已删除的用户
+func parseTrashedFilter(c echo.Context) (services.TrashedFilter, error) {
+	trashed := services.TrashedFilter(c.QueryParam("trashed"))
+	switch trashed {
+	case services.TrashedExclude:
+		return trashed, nil
+	case services.TrashedWith, services.TrashedOnly:
+		if !utils.HasRole(c, models.RoleAdmin) {
+			return "", utils.ErrForbidden("user.trashed_forbidden")
+		}
+		return trashed, nil
+	default:
+		return "", utils.ErrInvalidParam("trashed")
+	}
+}
+
+// SearchUsers 检索用户
+// @Summary      检索用户
+// @ID           searchUsers
+// @Description  按用户名、邮箱、姓名全文检索未删除的用户，结果按相关度排序；highlights 中匹配部分以 <mark> 标记，其余部分已做 HTML 转义
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        q          query     string  true   "检索词，多个词以空格分隔"
+// @Param        page       query     int     false  "页码"  default(1)
+// @Param        page_size  query     int     false  "每页数量"  default(20)  maximum(100)
+// @Success      200  {object}  utils.Response{data=utils.PageResult{items=[]services.UserSearchResult}}  "成功返回检索结果"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Router       /v1/users/search [get]
+func (uc *UserController) SearchUsers(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return utils.HandleError(c, utils.ErrInvalidParam("q"))
+	}
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	results, total, err := uc.userService.SearchUsers(c.Request().Context(), query, page)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
-	return utils.Success(c, users, "获取用户列表成功")
+
+	return utils.Success(c, utils.NewPageResult(results, total, page), "user.searched")
 }
 
 // GetUser 获取单个用户
 // @Summary      获取单个用户
+// @ID           getUser
 // @Description  根据ID获取用户详细信息
 // @Tags         users
 // @Accept       json
@@ -44,6 +112,7 @@ func (uc *UserController) GetUsers(c echo.Context) error {
 // @Param        id   path      int  true  "用户ID"  example(1)
 // @Success      200  {object}  utils.Response{data=models.User}  "成功返回用户信息"
 // @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
 // @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
 // @Router       /v1/users/{id} [get]
 func (uc *UserController) GetUser(c echo.Context) error {
@@ -52,24 +121,32 @@ func (uc *UserController) GetUser(c echo.Context) error {
 		return utils.HandleError(c, err)
 	}
 
-	user, err := uc.userService.GetUserByID(id)
+	user, err := uc.userService.GetUserByID(c.Request().Context(), id)
 	if err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	return utils.Success(c, *user, "获取用户信息成功")
+	return utils.Success(c, *user, "user.fetched")
 }
 
 // CreateUser 创建用户
 // @Summary      创建用户
+// @ID           createUser
 // @Description  创建新用户
 // @Tags         users
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key  header    string       false  "幂等键，重试时携带相同的值不会重复创建"
 // @Param        user  body      models.User  true  "用户信息"
 // @Success      201   {object}  utils.Response{data=models.User} "成功创建用户"
 // @Failure      400   {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401   {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403   {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      409   {object}  utils.ErrorResponse  "用户名或邮箱已存在，或相同幂等键的请求正在处理"
+// @Failure      422   {object}  utils.ErrorResponse  "幂等键已被用于不同的请求"
 // @Failure      500   {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /v1/users [post]
 func (uc *UserController) CreateUser(c echo.Context) error {
 	var user models.User
@@ -77,24 +154,33 @@ func (uc *UserController) CreateUser(c echo.Context) error {
 		return utils.HandleError(c, err)
 	}
 
-	if err := uc.userService.CreateUser(&user); err != nil {
+	if err := uc.userService.CreateUser(c.Request().Context(), &user); err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	return utils.SuccessCreated(c, user, "创建用户成功")
+	return utils.SuccessCreated(c, user, "user.created")
 }
 
 // UpdateUser 更新用户
 // @Summary      更新用户
-// @Description  更新用户信息
+// @ID           updateUser
+// @Description  更新用户信息，请求体需携带获取用户时返回的 version，版本不一致时返回 409（非管理员只能修改自己）
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id    path      int          true  "用户ID"  example(1)
-// @Param        user  body      models.User  true  "用户信息"
-// @Success      200   {object}  utils.Response{data=[]models.User}  "成功更新用户"
+// @Param        id        path      int          true   "用户ID"  example(1)
+// @Param        If-Match  header    string       false  "获取用户时返回的 ETag，不匹配时拒绝更新"
+// @Param        user      body      models.User  true   "用户信息"
+// @Success      200   {object}  utils.Response{data=models.User}  "成功更新用户"
 // @Failure      400   {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401   {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403   {object}  utils.ErrorResponse  "只能修改自己，或 API Key 缺少权限"
+// @Failure      404   {object}  utils.ErrorResponse  "用户不存在"
+// @Failure      409   {object}  utils.ErrorResponse{data=map[string]int}  "版本冲突，data.current_version 为当前版本号"
+// @Failure      412   {object}  utils.ErrorResponse  "用户已被修改"
 // @Failure      500   {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /v1/users/{id} [put]
 func (uc *UserController) UpdateUser(c echo.Context) error {
 	id, err := utils.ParseUintParam(c, "id")
@@ -102,29 +188,49 @@ func (uc *UserController) UpdateUser(c echo.Context) error {
 		return utils.HandleError(c, err)
 	}
 
+	if c.Request().Header.Get(utils.HeaderIfMatch) != "" {
+		current, err := uc.userService.GetUserByID(c.Request().Context(), id)
+		if err != nil {
+			return utils.HandleError(c, err)
+		}
+		if err := utils.CheckIfMatch(c, *current); err != nil {
+			return utils.HandleError(c, err)
+		}
+	}
+
 	var user models.User
 	if err := utils.BindAndValidate(c, &user); err != nil {
 		return utils.HandleError(c, err)
 	}
 
 	user.ID = id
-	if err := uc.userService.UpdateUser(&user); err != nil {
+	if err := uc.userService.UpdateUser(c.Request().Context(), &user); err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	return utils.Success(c, user, "更新用户成功")
+	return utils.Success(c, user, "user.updated")
 }
 
 // DeleteUser 删除用户
 // @Summary      删除用户
-// @Description  根据ID删除用户
+// @ID           deleteUser
+// @Description  根据ID删除用户（软删除），管理员可通过 force=true 彻底删除
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "用户ID"  example(1)
+// @Param        id        path      int     true   "用户ID"  example(1)
+// @Param        force     query     bool    false  "彻底删除（仅管理员）"
+// @Param        If-Match  header    string  false  "获取用户时返回的 ETag，不匹配时拒绝删除"
 // @Success      200  {object}  utils.SuccessResponse  "成功删除用户"
+// @Success      204  "成功删除用户（Prefer: envelope=false 或路由组关闭了响应包装）"
 // @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "只能删除自己、无权彻底删除用户，或 API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
+// @Failure      412  {object}  utils.ErrorResponse  "用户已被修改"
 // @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /v1/users/{id} [delete]
 func (uc *UserController) DeleteUser(c echo.Context) error {
 	id, err := utils.ParseUintParam(c, "id")
@@ -132,9 +238,283 @@ func (uc *UserController) DeleteUser(c echo.Context) error {
 		return utils.HandleError(c, err)
 	}
 
-	if err := uc.userService.DeleteUser(id); err != nil {
+	force := c.QueryParam("force") == "true"
+	if force && !utils.HasRole(c, models.RoleAdmin) {
+		return utils.HandleError(c, utils.ErrForbidden("user.purge_forbidden"))
+	}
+
+	if c.Request().Header.Get(utils.HeaderIfMatch) != "" {
+		current, err := uc.userService.GetUserByID(c.Request().Context(), id)
+		if err != nil {
+			return utils.HandleError(c, err)
+		}
+		if err := utils.CheckIfMatch(c, *current); err != nil {
+			return utils.HandleError(c, err)
+		}
+	}
+
+	if force {
+		if err := uc.userService.ForceDeleteUser(c.Request().Context(), id); err != nil {
+			return utils.HandleError(c, err)
+		}
+		return utils.SuccessNoContent(c, "user.purged")
+	}
+
+	if err := uc.userService.DeleteUser(c.Request().Context(), id); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.SuccessNoContent(c, "user.deleted")
+}
+
+// RestoreUser 恢复用户
+// @Summary      恢复用户
+// @ID           restoreUser
+// @Description  恢复已删除的用户（仅管理员）
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "用户ID"  example(1)
+// @Success      200  {object}  utils.Response{data=models.User}  "成功恢复用户"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "仅管理员可以恢复用户"
+// @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
+// @Failure      409  {object}  utils.ErrorResponse  "用户未被删除，或用户名/邮箱已被占用"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/users/{id}/restore [post]
+func (uc *UserController) RestoreUser(c echo.Context) error {
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	user, err := uc.userService.RestoreUser(c.Request().Context(), id)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, *user, "user.restored")
+}
+
+// BatchUsersRequest 批量操作请求
+type BatchUsersRequest struct {
+	Mode       string                        `json:"mode" enums:"atomic,partial" example:"atomic"` // atomic：任一项失败全部回滚（默认）；partial：允许部分成功
+	Operations []services.BatchUserOperation `json:"operations"`                                   // 按顺序执行的操作
+}
+
+// 批量操作模式
+const (
+	BatchModeAtomic  = "atomic"
+	BatchModePartial = "partial"
+)
+
+// parseBatchMode 解析批量操作模式，返回是否为原子模式
+func parseBatchMode(mode string) (bool, error) {
+	switch mode {
+	case "", BatchModeAtomic:
+		return true, nil
+	case BatchModePartial:
+		return false, nil
+	default:
+		return false, utils.ErrInvalidParam("mode")
+	}
+}
+
+// BatchUsers 批量操作用户
+// @Summary      批量操作用户
+// @ID           batchUsers
+// @Description  在一个事务中按顺序批量创建、更新、删除用户，返回逐项结果。
+// @Description  mode=atomic（默认）时任一项失败即全部回滚并返回 422，data 中未失败的项状态为 424；
+// @Description  mode=partial 时失败项单独回滚，其余操作照常提交。单次操作数受 BATCH_MAX_ITEMS 限制
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string             false  "幂等键，重试时携带相同的值不会重复执行"
+// @Param        request          body      BatchUsersRequest  true   "批量操作"
+// @Success      200  {object}  utils.Response{data=services.BatchResult}  "执行完成，逐项结果见 data.items"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "仅管理员可以批量操作用户"
+// @Failure      413  {object}  utils.ErrorResponse  "请求体过大或操作数量超过上限"
+// @Failure      422  {object}  utils.ErrorResponse{data=services.BatchResult}  "原子模式下有操作失败，所有操作已回滚"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/users/batch [post]
+func (uc *UserController) BatchUsers(c echo.Context) error {
+	var req BatchUsersRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	atomic, err := parseBatchMode(req.Mode)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	if len(req.Operations) == 0 {
+		return utils.HandleError(c, utils.ErrBadRequest("batch.empty"))
+	}
+	cfg := config.AppConfig.Batch
+	if len(req.Operations) > cfg.MaxItems {
+		return utils.HandleError(c, utils.NewAppError(http.StatusRequestEntityTooLarge,
+			"batch.too_many", nil, i18n.Args{i18n.CountArg: cfg.MaxItems}))
+	}
+
+	result, err := uc.userService.BatchUsers(c.Request().Context(), req.Operations, services.BatchOptions{
+		Atomic:    atomic,
+		BatchSize: cfg.Size,
+	})
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	return utils.Success(c, result, "batch.completed", i18n.Args{i18n.CountArg: len(result.Items), "Failed": result.Failed})
+}
+
+// ExportUsers 导出用户
+// @Summary      导出用户
+// @ID           exportUsers
+// @Description  流式导出用户表，不包含密码等敏感字段；导出文件可修改后通过导入接口重新上传（仅管理员）
+// @Tags         users
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      json
+// @Param        format   query     string  false  "导出格式，默认 csv"  Enums(csv, jsonl, xlsx)
+// @Param        trashed  query     string  false  "已删除用户的导出范围"  Enums(with, only)
+// @Success      200  {file}    file                 "导出文件"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "仅管理员可以导出用户"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/users/export [get]
+func (uc *UserController) ExportUsers(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = utils.FormatCSV
+	}
+	contentType := utils.ExportContentType(format)
+	if contentType == "" {
+		return utils.HandleError(c, utils.ErrInvalidParam("format"))
+	}
+	trashed, err := parseTrashedFilter(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	// 第一批数据读取成功后才写出响应头，之前的错误仍可以按普通错误响应返回
+	res := c.Response()
+	var writer utils.RowWriter
+	start := func() error {
+		res.Header().Set(echo.HeaderContentType, contentType)
+		res.Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102150405"), format))
+		res.WriteHeader(http.StatusOK)
+		writer, err = utils.NewRowWriter(res, format, services.UserExportColumns)
+		return err
+	}
+
+	err = uc.userService.ExportUsers(c.Request().Context(), trashed, config.AppConfig.Batch.Size, func(users []models.User) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i := range users {
+			if err := writer.WriteRow(services.UserExportRow(&users[i])); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		res.Flush()
+		return nil
+	})
+	if err != nil {
+		if writer == nil {
+			return utils.HandleError(c, err)
+		}
+		// 响应已开始，只能中断连接并记录错误
+		return err
+	}
+
+	if writer == nil {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// ImportUsers 导入用户
+// @Summary      导入用户
+// @ID           importUsers
+// @Description  上传 CSV（需包含表头）或 JSONL 文件批量导入用户，逐行校验并返回每行的结果（仅管理员）。
+// @Description  不带 id 的行创建用户；带 id 的行更新对应用户，必须同时提供 version。导出文件中的其他列会被忽略。
+// @Description  mode=atomic（默认）时任一行失败即全部回滚并返回 422；mode=partial 时仅跳过失败的行
+// @Tags         users
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData  file    true   "CSV 或 JSONL 文件"
+// @Param        format  formData  string  false  "文件格式，默认根据扩展名判断"  Enums(csv, jsonl)
+// @Param        mode    formData  string  false  "导入模式，默认 atomic"  Enums(atomic, partial)
+// @Success      200  {object}  utils.Response{data=services.ImportResult}  "导入完成，逐行结果见 data.lines"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误或文件格式错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "仅管理员可以导入用户"
+// @Failure      413  {object}  utils.ErrorResponse  "文件过大或行数超过上限"
+// @Failure      422  {object}  utils.ErrorResponse{data=services.ImportResult}  "原子模式下有行失败，所有数据已回滚"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v1/users/import [post]
+func (uc *UserController) ImportUsers(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return utils.HandleError(c, utils.ErrRequestTooLarge())
+		}
+		return utils.HandleError(c, utils.ErrBadRequest("import.missing_file"))
+	}
+
+	format := c.FormValue("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file.Filename)) {
+		case ".csv":
+			format = utils.FormatCSV
+		case ".jsonl", ".ndjson":
+			format = utils.FormatJSONL
+		}
+	}
+	if format != utils.FormatCSV && format != utils.FormatJSONL {
+		return utils.HandleError(c, utils.ErrInvalidParam("format"))
+	}
+	atomic, err := parseBatchMode(c.FormValue("mode"))
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return utils.HandleError(c, utils.ErrInternal("import.read_file", err))
+	}
+	defer src.Close()
+
+	cfg := config.AppConfig.Batch
+	result, err := uc.userService.ImportUsers(c.Request().Context(), src, format, services.ImportOptions{
+		BatchOptions: services.BatchOptions{Atomic: atomic, BatchSize: cfg.Size},
+		MaxRows:      cfg.MaxImportRows,
+	})
+	if err != nil {
 		return utils.HandleError(c, err)
 	}
 
-	return utils.SuccessNoContent(c, "删除用户成功")
+	return utils.Success(c, result, "import.completed", i18n.Args{i18n.CountArg: len(result.Lines), "Failed": result.Failed})
 }
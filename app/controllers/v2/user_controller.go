@@ -0,0 +1,219 @@
+package v2
+
+import (
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UserController v2 用户接口，与 v1 共用 UserService
+type UserController struct {
+	userService services.UserServiceInterface
+}
+
+func NewUserController() *UserController {
+	return &UserController{
+		userService: services.NewUserService(),
+	}
+}
+
+// ListUsers 分页获取用户列表
+// @Summary      获取用户列表
+// @ID           v2ListUsers
+// @Description  分页获取用户，trashed=with 包含已删除用户，trashed=only 仅返回已删除用户（仅管理员）
+// @Tags         users-v2
+// @Accept       json
+// @Produce      json
+// @Param        trashed    query     string  false  "已删除用户的查询范围"  Enums(with, only)
+// @Param        page       query     int     false  "页码"  default(1)
+// @Param        page_size  query     int     false  "每页数量"  default(20)  maximum(100)
+// @Success      200  {object}  utils.Response{data=utils.PageResult{items=[]UserResponse}}  "成功返回用户列表"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限，或非管理员查询已删除用户"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Router       /v2/users [get]
+func (uc *UserController) ListUsers(c echo.Context) error {
+	trashed := services.TrashedFilter(c.QueryParam("trashed"))
+	switch trashed {
+	case services.TrashedExclude, services.TrashedWith, services.TrashedOnly:
+	default:
+		return utils.HandleError(c, utils.ErrInvalidParam("trashed"))
+	}
+	if trashed != services.TrashedExclude && !utils.HasRole(c, models.RoleAdmin) {
+		return utils.HandleError(c, utils.ErrForbidden("user.trashed_forbidden"))
+	}
+	page, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	users, total, err := uc.userService.ListUsers(c.Request().Context(), trashed, page)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.Success(c, utils.NewPageResult(NewUserResponses(users), total, page), "user.listed")
+}
+
+// GetUser 获取单个用户
+// @Summary      获取单个用户
+// @ID           v2GetUser
+// @Description  根据ID获取用户详细信息
+// @Tags         users-v2
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "用户ID"  example(1)
+// @Success      200  {object}  utils.Response{data=UserResponse}  "成功返回用户信息"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
+// @Router       /v2/users/{id} [get]
+func (uc *UserController) GetUser(c echo.Context) error {
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	user, err := uc.userService.GetUserByID(c.Request().Context(), id)
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.Success(c, NewUserResponse(*user), "user.fetched")
+}
+
+// CreateUser 创建用户
+// @Summary      创建用户
+// @ID           v2CreateUser
+// @Description  创建新用户
+// @Tags         users-v2
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string             false  "幂等键，重试时携带相同的值不会重复创建"
+// @Param        user             body      CreateUserRequest  true   "用户信息"
+// @Success      201  {object}  utils.Response{data=UserResponse}  "成功创建用户"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "API Key 缺少权限"
+// @Failure      409  {object}  utils.ErrorResponse  "用户名或邮箱已存在，或相同幂等键的请求正在处理"
+// @Failure      422  {object}  utils.ErrorResponse  "幂等键已被用于不同的请求"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v2/users [post]
+func (uc *UserController) CreateUser(c echo.Context) error {
+	var req CreateUserRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	user := req.Model()
+	if err := uc.userService.CreateUser(c.Request().Context(), &user); err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.SuccessCreated(c, NewUserResponse(user), "user.created")
+}
+
+// UpdateUser 更新用户
+// @Summary      更新用户
+// @ID           v2UpdateUser
+// @Description  更新用户信息，请求体需携带获取用户时返回的 version，版本不一致时返回 409（非管理员只能修改自己）
+// @Tags         users-v2
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                true   "用户ID"  example(1)
+// @Param        If-Match  header    string             false  "获取用户时返回的 ETag，不匹配时拒绝更新"
+// @Param        user      body      UpdateUserRequest  true   "用户信息"
+// @Success      200  {object}  utils.Response{data=UserResponse}  "成功更新用户"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "只能修改自己，或 API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
+// @Failure      409  {object}  utils.ErrorResponse{data=map[string]int}  "版本冲突，data.current_version 为当前版本号"
+// @Failure      412  {object}  utils.ErrorResponse  "用户已被修改"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v2/users/{id} [put]
+func (uc *UserController) UpdateUser(c echo.Context) error {
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	if err := uc.checkIfMatch(c, id); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	var req UpdateUserRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	user := req.Model(id)
+	if err := uc.userService.UpdateUser(c.Request().Context(), &user); err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.Success(c, NewUserResponse(user), "user.updated")
+}
+
+// DeleteUser 删除用户
+// @Summary      删除用户
+// @ID           v2DeleteUser
+// @Description  根据ID删除用户（软删除），管理员可通过 force=true 彻底删除
+// @Tags         users-v2
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int     true   "用户ID"  example(1)
+// @Param        force     query     bool    false  "彻底删除（仅管理员）"
+// @Param        If-Match  header    string  false  "获取用户时返回的 ETag，不匹配时拒绝删除"
+// @Success      200  {object}  utils.SuccessResponse  "成功删除用户"
+// @Success      204  "成功删除用户（Prefer: envelope=false 或路由组关闭了响应包装）"
+// @Failure      400  {object}  utils.ErrorResponse  "请求参数错误"
+// @Failure      401  {object}  utils.ErrorResponse  "未认证或 API Key 无效"
+// @Failure      403  {object}  utils.ErrorResponse  "只能删除自己、无权彻底删除用户，或 API Key 缺少权限"
+// @Failure      404  {object}  utils.ErrorResponse  "用户不存在"
+// @Failure      412  {object}  utils.ErrorResponse  "用户已被修改"
+// @Failure      500  {object}  utils.ErrorResponse  "服务器错误"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /v2/users/{id} [delete]
+func (uc *UserController) DeleteUser(c echo.Context) error {
+	id, err := utils.ParseUintParam(c, "id")
+	if err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	force := c.QueryParam("force") == "true"
+	if force && !utils.HasRole(c, models.RoleAdmin) {
+		return utils.HandleError(c, utils.ErrForbidden("user.purge_forbidden"))
+	}
+
+	if err := uc.checkIfMatch(c, id); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	if force {
+		if err := uc.userService.ForceDeleteUser(c.Request().Context(), id); err != nil {
+			return utils.HandleError(c, err)
+		}
+		return utils.SuccessNoContent(c, "user.purged")
+	}
+
+	if err := uc.userService.DeleteUser(c.Request().Context(), id); err != nil {
+		return utils.HandleError(c, err)
+	}
+	return utils.SuccessNoContent(c, "user.deleted")
+}
+
+// checkIfMatch 请求携带 If-Match 时与当前用户的 ETag 比较；ETag 按 v2 的表示计算，与 GetUser 返回的一致
+func (uc *UserController) checkIfMatch(c echo.Context, id uint) error {
+	if c.Request().Header.Get(utils.HeaderIfMatch) == "" {
+		return nil
+	}
+	current, err := uc.userService.GetUserByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return utils.CheckIfMatch(c, NewUserResponse(*current))
+}
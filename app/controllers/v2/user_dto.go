@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"echo-template/app/models"
+	"time"
+)
+
+// v2 与 v1 共用服务和模型，只在这里转换请求和响应的表示：
+//   - name 改名为 display_name
+//   - 未删除的用户不返回 deleted_at
+//   - 创建和更新使用独立的请求体，不再接受完整的用户模型
+
+// UserResponse 用户信息
+// @Description v2 用户信息
+type UserResponse struct {
+	ID          uint       `json:"id" example:"1"`                                                  // ID
+	Username    string     `json:"username" example:"john_doe"`                                     // 用户名
+	Email       string     `json:"email" example:"john@example.com"`                                // 邮箱
+	DisplayName string     `json:"display_name" example:"John Doe"`                                 // 显示名称
+	Role        string     `json:"role" example:"user"`                                             // 角色：user | admin
+	Locale      string     `json:"locale" example:"zh-CN"`                                          // 偏好的语言
+	Version     uint       `json:"version" example:"1"`                                             // 版本号（乐观锁，更新时必须携带）
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z" format:"date-time"`    // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z" format:"date-time"`    // 更新时间
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" format:"date-time" extensions:"x-nullable"` // 删除时间（仅已删除用户返回）
+}
+
+// NewUserResponse 将用户模型转换为 v2 的响应
+func NewUserResponse(user models.User) UserResponse {
+	res := UserResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		DisplayName: user.Name,
+		Role:        user.Role,
+		Locale:      user.Locale,
+		Version:     user.Version,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		res.DeletedAt = &deletedAt
+	}
+	return res
+}
+
+// NewUserResponses 批量转换用户模型
+func NewUserResponses(users []models.User) []UserResponse {
+	res := make([]UserResponse, len(users))
+	for i, user := range users {
+		res[i] = NewUserResponse(user)
+	}
+	return res
+}
+
+// CreateUserRequest 创建用户请求
+// @Description v2 创建用户请求
+type CreateUserRequest struct {
+	Username    string `json:"username" example:"john_doe" binding:"required"`                // 用户名（未删除用户中唯一）
+	Email       string `json:"email" example:"john@example.com" binding:"required,email"`     // 邮箱（未删除用户中唯一）
+	DisplayName string `json:"display_name" example:"John Doe"`                               // 显示名称
+	Locale      string `json:"locale" example:"zh-CN" binding:"omitempty,bcp47_language_tag"` // 偏好的语言
+}
+
+// Model 转换为用户模型
+func (r CreateUserRequest) Model() models.User {
+	return models.User{Username: r.Username, Email: r.Email, Name: r.DisplayName, Locale: r.Locale}
+}
+
+// UpdateUserRequest 更新用户请求
+// @Description v2 更新用户请求，未携带的字段会被清空
+type UpdateUserRequest struct {
+	Username    string `json:"username" example:"john_doe" binding:"required"`                // 用户名（未删除用户中唯一）
+	Email       string `json:"email" example:"john@example.com" binding:"required,email"`     // 邮箱（未删除用户中唯一）
+	DisplayName string `json:"display_name" example:"John Doe"`                               // 显示名称
+	Locale      string `json:"locale" example:"zh-CN" binding:"omitempty,bcp47_language_tag"` // 偏好的语言
+	Version     uint   `json:"version" example:"1" binding:"required"`                        // 获取用户时返回的版本号
+}
+
+// Model 转换为 id 对应的用户模型
+func (r UpdateUserRequest) Model(id uint) models.User {
+	user := models.User{Username: r.Username, Email: r.Email, Name: r.DisplayName, Locale: r.Locale}
+	user.ID = id
+	user.Version = r.Version
+	return user
+}
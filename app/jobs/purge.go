@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"echo-template/app/services"
+	"echo-template/config"
+	"log"
+	"time"
+)
+
+// StartPurgeDeletedUsers 定期彻底删除软删除超过保留天数的用户
+// 保留天数为 0 时不启动；ctx 取消后任务退出
+func StartPurgeDeletedUsers(ctx context.Context, userService services.UserServiceInterface) {
+	cfg := config.AppConfig.SoftDelete
+	if cfg.RetentionDays <= 0 || cfg.PurgeInterval <= 0 {
+		return
+	}
+
+	retention := time.Duration(cfg.RetentionDays) * 24 * time.Hour
+	purge := func() {
+		count, err := userService.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("purge deleted users: %v", err)
+			return
+		}
+		if count > 0 {
+			log.Printf("purged %d users deleted more than %d days ago", count, cfg.RetentionDays)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.PurgeInterval)
+		defer ticker.Stop()
+
+		purge()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purge()
+			}
+		}
+	}()
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql/driver"
+	"echo-template/utils"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// API Key 可授权的资源（在 ScopeResources 中登记），权限范围为 <资源>:read 或 <资源>:write（包含 read），* 表示全部权限
+const (
+	ScopeResourceUsers     = "users"
+	ScopeResourceAuditLogs = "audit_logs"
+	ScopeResourceAPIKeys   = "api_keys"
+)
+
+// ValidScope 是否为可授予的权限范围
+func ValidScope(scope string) bool {
+	if scope == utils.ScopeAll {
+		return true
+	}
+	resource, access, ok := strings.Cut(scope, ":")
+	return ok && (access == "read" || access == "write") && slices.Contains(ScopeResources(), resource)
+}
+
+// APIKey API Key，属于一个用户或一个服务账号
+// @Description API Key，只保存可见前缀和密钥的哈希，完整密钥只在创建时返回一次
+type APIKey struct {
+	Model
+
+	Name           string     `json:"name" example:"nightly-export" gorm:"not null"`                                          // 名称
+	Prefix         string     `json:"prefix" example:"etk_3f9a1c2b" gorm:"not null;uniqueIndex"`                              // 可见前缀，用于识别密钥
+	SecretHash     string     `json:"-" gorm:"not null" audit:"-"`                                                            // 密钥的 SHA-256 哈希（不返回）
+	UserID         *uint      `json:"user_id" example:"1" extensions:"x-nullable" gorm:"index"`                               // 所属用户ID，服务账号的密钥为 null
+	ServiceAccount string     `json:"service_account" example:"billing-sync" gorm:"index"`                                    // 所属服务账号，用户的密钥为空
+	Role           string     `json:"role" example:"user"`                                                                    // 服务账号的角色：user | admin，用户的密钥使用用户的角色
+	Scopes         StringList `json:"scopes" swaggertype:"array,string" example:"users:read" gorm:"type:text;not null"`       // 权限范围
+	ExpiresAt      *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z" format:"date-time" extensions:"x-nullable"`   // 过期时间，null 为不过期
+	LastUsedAt     *time.Time `json:"last_used_at" example:"2024-01-01T00:00:00Z" format:"date-time" extensions:"x-nullable"` // 最近使用时间
+	LastUsedIP     string     `json:"last_used_ip" example:"127.0.0.1"`                                                       // 最近使用的客户端 IP
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Audited 记录密钥的创建、修改、吊销和使用（最近使用时间的更新）
+func (APIKey) Audited() bool {
+	return true
+}
+
+// Expired 密钥在 now 时是否已过期
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// StringList 以 JSON 数组文本存储的字符串列表
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
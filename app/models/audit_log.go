@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// 审计动作
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"       // 软删除
+	AuditActionRestore     = "restore"      // 恢复软删除
+	AuditActionForceDelete = "force_delete" // 彻底删除
+)
+
+// AuditLog 审计日志
+// @Description 审计日志，记录每次数据变更的操作人和新旧值
+type AuditLog struct {
+	ID        uint      `json:"id" example:"1" gorm:"primarykey"`                                                    // 日志ID
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z" format:"date-time" gorm:"index"`           // 操作时间
+	ActorID   *uint     `json:"actor_id" example:"1" extensions:"x-nullable" gorm:"index"`                           // 操作人用户ID，非用户操作时为 null
+	ActorType string    `json:"actor_type" example:"user" gorm:"not null"`                                           // 操作人类型：user | service_account | anonymous | system
+	APIKeyID  *uint     `json:"api_key_id" example:"1" extensions:"x-nullable" gorm:"index"`                         // 使用的 API Key ID，未使用 API Key 时为 null
+	RequestID string    `json:"request_id" example:"3b1f0c9e" gorm:"index"`                                          // 请求ID
+	IP        string    `json:"ip" example:"127.0.0.1"`                                                              // 客户端 IP
+	Action    string    `json:"action" example:"update" gorm:"not null;index"`                                       // 动作：create | update | delete | restore | force_delete
+	Table     string    `json:"table" example:"users" gorm:"column:table_name;not null;index:idx_audit_logs_record"` // 表名
+	RecordID  string    `json:"record_id" example:"1" gorm:"not null;index:idx_audit_logs_record"`                   // 主键
+	Changes   JSONText  `json:"changes" swaggertype:"object" gorm:"type:text"`                                       // 变更内容：{"字段": {"old": 旧值, "new": 新值}}
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// JSONText 以文本存储的 JSON，序列化时原样输出
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Model 通用基础模型，新资源嵌入后即拥有主键、时间戳、软删除和乐观锁版本号
+type Model struct {
+	ID        uint           `json:"id" example:"1" gorm:"primarykey"`                                                        // ID
+	CreatedAt time.Time      `json:"created_at" example:"2024-01-01T00:00:00Z" format:"date-time"`                            // 创建时间
+	UpdatedAt time.Time      `json:"updated_at" example:"2024-01-01T00:00:00Z" format:"date-time"`                            // 更新时间
+	DeletedAt gorm.DeletedAt `json:"deleted_at" swaggertype:"string" format:"date-time" extensions:"x-nullable" gorm:"index"` // 删除时间（未删除时为 null）
+	Version   uint           `json:"version" example:"1" gorm:"not null;default:1"`                                           // 版本号（乐观锁，更新时必须携带）
+}
+
+// BeforeCreate 新记录的版本号从 1 开始
+func (m *Model) BeforeCreate(tx *gorm.DB) error {
+	if m.Version == 0 {
+		m.Version = 1
+	}
+	return nil
+}
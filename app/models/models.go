@@ -0,0 +1,20 @@
+package models
+
+// All 需要自动迁移的模型列表，新增模型后在此登记
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&AuditLog{},
+		&APIKey{},
+		&OIDCIdentity{},
+	}
+}
+
+// ScopeResources API Key 可授权的资源，权限范围为 <资源>:read 或 <资源>:write，新增资源后在此登记
+func ScopeResources() []string {
+	return []string{
+		ScopeResourceUsers,
+		ScopeResourceAuditLogs,
+		ScopeResourceAPIKeys,
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// OIDCIdentity 本地用户在 OIDC 身份提供方中的身份，同一身份提供方的 sub 只能关联一个用户
+// 首次登录时创建，之后按 (provider, subject) 找到对应的用户；不软删除，用户被彻底删除后在下次登录时重新关联
+type OIDCIdentity struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`                                            // 关联的用户
+	Provider    string     `json:"provider" gorm:"not null;size:32;uniqueIndex:idx_oidc_identities_subject"` // 身份提供方名称（OIDC_PROVIDERS 中的名称）
+	Subject     string     `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_oidc_identities_subject"` // ID Token 的 sub
+	Email       string     `json:"email"`                                                                    // 最近一次登录时身份提供方返回的邮箱
+	LastLoginAt *time.Time `json:"last_login_at"`                                                            // 最近登录时间
+}
+
+func (OIDCIdentity) TableName() string {
+	return "oidc_identities"
+}
+
+// Audited 记录身份的关联和每次登录（最近登录时间的更新）
+func (OIDCIdentity) Audited() bool {
+	return true
+}
@@ -1,26 +1,71 @@
 package models
 
 import (
-	"time"
+	"echo-template/database"
+	"echo-template/utils"
 
 	"gorm.io/gorm"
 )
 
+// 用户角色
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User 用户模型
 // @Description 用户信息
 type User struct {
-	ID        uint           `json:"id" example:"1" gorm:"primarykey"`                                    // 用户ID
-	CreatedAt time.Time      `json:"created_at" example:"2024-01-01T00:00:00Z"`                           // 创建时间
-	UpdatedAt time.Time      `json:"updated_at" example:"2024-01-01T00:00:00Z"`                           // 更新时间
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                                                       // 删除时间（不返回）
-	
-	Username string `json:"username" example:"john_doe" gorm:"uniqueIndex;not null" binding:"required"`   // 用户名
-	Email    string `json:"email" example:"john@example.com" gorm:"uniqueIndex;not null" binding:"required,email"` // 邮箱
-	Password string `json:"-" gorm:"not null"`                                                           // 密码（不返回）
-	Name     string `json:"name" example:"John Doe"`                                                       // 姓名
+	Model
+
+	Username string `json:"username" example:"john_doe" gorm:"not null" binding:"required"`               // 用户名（未删除用户中唯一）
+	Email    string `json:"email" example:"john@example.com" gorm:"not null" binding:"required,email"`    // 邮箱（未删除用户中唯一）
+	Password string `json:"-" gorm:"not null" audit:"-"`                                                  // 密码哈希（不返回），通过 OIDC 登录的用户没有本地密码，为空
+	Name     string `json:"name" example:"John Doe"`                                                      // 姓名
+	Role     string `json:"role" example:"user" gorm:"not null;default:user"`                             // 角色：user | admin（不可通过接口修改）
+	Locale   string `json:"locale" example:"zh-CN" gorm:"size:35" binding:"omitempty,bcp47_language_tag"` // 偏好的语言，认证后用于响应消息
+}
+
+// UserSearchIndex 用户的全文检索定义，检索用户名、邮箱和姓名
+var UserSearchIndex = database.SearchIndex{
+	Table:      "users",
+	Columns:    []string{"username", "email", "name"},
+	SoftDelete: true,
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+// Audited 记录用户的变更审计日志（密码字段通过 audit:"-" 排除）
+func (User) Audited() bool {
+	return true
+}
+
+// PostMigrate 用户名和邮箱只在未删除的用户中唯一，允许重新注册已删除用户的邮箱；并创建全文索引
+func (u *User) PostMigrate(db *gorm.DB) error {
+	table := u.TableName()
+	if err := database.EnsureActiveUniqueIndex(db, table, "idx_users_username_active",
+		[]string{"username"}, "idx_users_username"); err != nil {
+		return err
+	}
+	if err := database.EnsureActiveUniqueIndex(db, table, "idx_users_email_active",
+		[]string{"email"}, "idx_users_email"); err != nil {
+		return err
+	}
+	return UserSearchIndex.Migrate(db)
+}
+
+// BeforeFixture 夹具中的 password 为明文，写入前做哈希；与已有哈希匹配时保持不变，重复加载不会产生变更
+func (u *User) BeforeFixture(row map[string]interface{}) error {
+	password, ok := row["password"].(string)
+	if !ok || (u.Password != "" && utils.CheckPassword(u.Password, password)) {
+		return nil
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
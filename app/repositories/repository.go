@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"echo-template/database"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Repository 通用数据访问接口，T 为模型类型
+// 所有方法通过 database.Conn 获取连接，自动加入 context 中的事务；返回的错误均为 *utils.AppError。
+// 服务层只依赖该接口，单元测试时可以替换为不依赖数据库的实现
+type Repository[T any] interface {
+	// Transaction 在事务中执行 fn，嵌套调用使用保存点
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	FindByID(ctx context.Context, id uint, scopes ...Scope) (*T, error)
+	First(ctx context.Context, scopes ...Scope) (*T, error)
+	Find(ctx context.Context, scopes ...Scope) ([]T, error)
+	FindPage(ctx context.Context, page utils.Pagination, scopes ...Scope) ([]T, int64, error)
+	// FindInBatches 按主键顺序分批读取，fn 返回的错误原样返回
+	FindInBatches(ctx context.Context, batchSize int, fn func(batch []T) error, scopes ...Scope) error
+	Count(ctx context.Context, scopes ...Scope) (int64, error)
+
+	Create(ctx context.Context, entity *T) error
+	CreateInBatches(ctx context.Context, entities []*T, batchSize int) error
+	// Update 按主键更新实体的所有字段（可通过 Omit 排除），返回受影响的行数
+	Update(ctx context.Context, entity *T, scopes ...Scope) (int64, error)
+	// UpdateColumns 按条件更新指定列，返回受影响的行数
+	UpdateColumns(ctx context.Context, values map[string]interface{}, scopes ...Scope) (int64, error)
+	// Delete 按条件删除（模型支持软删除时为软删除），返回受影响的行数
+	Delete(ctx context.Context, scopes ...Scope) (int64, error)
+	// ForceDelete 按条件彻底删除，包括已软删除的记录
+	ForceDelete(ctx context.Context, scopes ...Scope) (int64, error)
+
+	// Search 使用全文索引检索，返回当前页的命中记录和总数
+	Search(ctx context.Context, index database.SearchIndex, query string, page utils.Pagination) ([]database.SearchHit, int64, error)
+}
+
+// Options 仓储选项
+type Options struct {
+	Name     string // 资源名称的消息键，用于错误消息，例如 "resources.user"
+	Conflict string // 唯一约束冲突时的消息键，默认为 "resource.conflict"
+}
+
+// 确保 GormRepository 实现了 Repository
+var _ Repository[struct{}] = (*GormRepository[struct{}])(nil)
+
+// GormRepository 基于 GORM 的 Repository 实现
+type GormRepository[T any] struct {
+	db   *gorm.DB
+	opts Options
+}
+
+// NewRepository 创建基于 GORM 的仓储
+func NewRepository[T any](db *gorm.DB, opts Options) *GormRepository[T] {
+	if opts.Conflict == "" {
+		opts.Conflict = "resource.conflict"
+	}
+	return &GormRepository[T]{db: db, opts: opts}
+}
+
+// conn 返回应用了查询条件的连接
+// 条件立即应用而不是交给 db.Scopes 延迟执行，Count 才能去掉排序等不适用的子句
+func (r *GormRepository[T]) conn(ctx context.Context, scopes []Scope) *gorm.DB {
+	db := database.Conn(ctx, r.db)
+	for _, scope := range scopes {
+		db = scope(db)
+	}
+	return db
+}
+
+func (r *GormRepository[T]) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := database.NewTxManager(r.db).Transaction(ctx, fn)
+	var appErr *utils.AppError
+	if err != nil && !errors.As(err, &appErr) {
+		return utils.ErrInternal("error.transaction", err)
+	}
+	return err
+}
+
+func (r *GormRepository[T]) FindByID(ctx context.Context, id uint, scopes ...Scope) (*T, error) {
+	return r.First(ctx, append(scopes, ByID(id))...)
+}
+
+func (r *GormRepository[T]) First(ctx context.Context, scopes ...Scope) (*T, error) {
+	var entity T
+	if err := r.conn(ctx, scopes).First(&entity).Error; err != nil {
+		return nil, r.translate(err, "query")
+	}
+	return &entity, nil
+}
+
+func (r *GormRepository[T]) Find(ctx context.Context, scopes ...Scope) ([]T, error) {
+	var entities []T
+	if err := r.conn(ctx, scopes).Find(&entities).Error; err != nil {
+		return nil, r.translate(err, "query")
+	}
+	return entities, nil
+}
+
+func (r *GormRepository[T]) FindPage(ctx context.Context, page utils.Pagination, scopes ...Scope) ([]T, int64, error) {
+	total, err := r.Count(ctx, scopes...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entities := make([]T, 0)
+	if err := r.conn(ctx, scopes).Offset(page.Offset()).Limit(page.PageSize).Find(&entities).Error; err != nil {
+		return nil, 0, r.translate(err, "query")
+	}
+	return entities, total, nil
+}
+
+func (r *GormRepository[T]) FindInBatches(ctx context.Context, batchSize int, fn func(batch []T) error, scopes ...Scope) error {
+	var fnErr error
+	var batch []T
+	err := r.conn(ctx, scopes).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		fnErr = fn(batch)
+		return fnErr
+	}).Error
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return r.translate(err, "query")
+	}
+	return nil
+}
+
+func (r *GormRepository[T]) Count(ctx context.Context, scopes ...Scope) (int64, error) {
+	var total int64
+	if err := r.conn(ctx, scopes).Model(new(T)).Count(&total).Error; err != nil {
+		return 0, r.translate(err, "query")
+	}
+	return total, nil
+}
+
+func (r *GormRepository[T]) Create(ctx context.Context, entity *T) error {
+	if err := database.Conn(ctx, r.db).Create(entity).Error; err != nil {
+		return r.translate(err, "create")
+	}
+	return nil
+}
+
+func (r *GormRepository[T]) CreateInBatches(ctx context.Context, entities []*T, batchSize int) error {
+	if err := database.Conn(ctx, r.db).CreateInBatches(entities, batchSize).Error; err != nil {
+		return r.translate(err, "create")
+	}
+	return nil
+}
+
+func (r *GormRepository[T]) Update(ctx context.Context, entity *T, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx, scopes).Model(entity).Select("*").Updates(entity)
+	if result.Error != nil {
+		return 0, r.translate(result.Error, "update")
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *GormRepository[T]) UpdateColumns(ctx context.Context, values map[string]interface{}, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx, scopes).Model(new(T)).Updates(values)
+	if result.Error != nil {
+		return 0, r.translate(result.Error, "update")
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *GormRepository[T]) Delete(ctx context.Context, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx, scopes).Delete(new(T))
+	if result.Error != nil {
+		return 0, r.translate(result.Error, "delete")
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *GormRepository[T]) ForceDelete(ctx context.Context, scopes ...Scope) (int64, error) {
+	result := r.conn(ctx, scopes).Unscoped().Delete(new(T))
+	if result.Error != nil {
+		return 0, r.translate(result.Error, "delete")
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *GormRepository[T]) Search(ctx context.Context, index database.SearchIndex, query string, page utils.Pagination) ([]database.SearchHit, int64, error) {
+	hits, total, err := index.Search(database.Conn(ctx, r.db), query, page.Offset(), page.PageSize)
+	if err != nil {
+		return nil, 0, r.translate(err, "search")
+	}
+	return hits, total, nil
+}
+
+// translate 将 GORM 错误转换为 AppError：记录不存在 404，唯一约束冲突 409，其余 500
+// action 为 actions.* 消息键的后缀，例如 query
+func (r *GormRepository[T]) translate(err error, action string) error {
+	var appErr *utils.AppError
+	args := i18n.Args{"Resource": i18n.Key(r.opts.Name)}
+	switch {
+	case errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.ErrNotFound("resource.not_found", args)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return utils.ErrConflict(r.opts.Conflict, nil, args)
+	default:
+		args["Action"] = i18n.Key("actions." + action)
+		return utils.NewAppError(http.StatusInternalServerError, "resource.action_failed", err, args)
+	}
+}
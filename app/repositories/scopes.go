@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Scope 查询条件，与 gorm 的 Scopes 相同
+type Scope = func(db *gorm.DB) *gorm.DB
+
+// ByID 按主键查询
+func ByID(id uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	}
+}
+
+// Where 任意查询条件
+func Where(query interface{}, args ...interface{}) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+// Filter 等值过滤，值为零值的条件会被忽略，便于直接传入可选的查询参数
+func Filter(conditions map[string]interface{}) Scope {
+	columns := make([]string, 0, len(conditions))
+	for column, value := range conditions {
+		if value != nil && !reflect.ValueOf(value).IsZero() {
+			columns = append(columns, column)
+		}
+	}
+	// 固定条件顺序，生成稳定的 SQL
+	sort.Strings(columns)
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, column := range columns {
+			db = db.Where(db.Statement.Quote(column)+" = ?", conditions[column])
+		}
+		return db
+	}
+}
+
+// WithTrashed 包含已软删除的记录
+func WithTrashed() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// OnlyTrashed 仅查询已软删除的记录
+func OnlyTrashed() Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+}
+
+// Order 排序，例如 Order("created_at DESC")
+func Order(value string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(value)
+	}
+}
+
+// Select 只查询或更新指定列
+func Select(columns ...string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
+
+// Omit 查询或更新时排除指定列
+func Omit(columns ...string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Omit(columns...)
+	}
+}
@@ -1,25 +1,45 @@
 package routes
 
 import (
+	"echo-template/app/controllers"
 	"echo-template/app/routes/v1"
+	"echo-template/app/routes/v2"
+	"echo-template/config"
 	"echo-template/middleware"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func InitRoutes(e *echo.Echo) {
-	// Swagger 文档
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+// Versions 支持的 API 版本，未指定版本的请求使用 API_DEFAULT_VERSION
+var Versions = []string{"v1", "v2"}
 
-	// API 路由组
+func InitRoutes(e *echo.Echo) {
+	// Swagger 文档和 OpenAPI 3.1 文档，host 和 schemes 按请求生成
+	if config.AppConfig.Docs.Enabled {
+		docsController := controllers.NewDocsController()
+		docsAuth := middleware.DocsAuth()
+		swagger := e.Group("/swagger", docsAuth, middleware.ContentSecurityPolicy(middleware.DocsContentSecurityPolicy))
+		swagger.GET("/doc.json", docsController.GetSwaggerDoc)
+		swagger.GET("/*", echoSwagger.WrapHandler)
+		e.GET("/openapi.json", docsController.GetOpenAPIJSON, docsAuth)
+		e.GET("/openapi.yaml", docsController.GetOpenAPIYAML, docsAuth)
+	}
+
+	// API 路由组，路径中未带版本时按 Accept-Version 或厂商媒体类型选择版本
+	e.Pre(middleware.APIVersion("/api", Versions...))
 	api := e.Group("/api")
 
-	// 应用中间件
-	api.Use(middleware.CORS())
+	// 应用中间件（CORS 已在全局注册）
+	api.Use(middleware.RateLimit("api"))
+	api.Use(middleware.RejectUnsupportedAPIVersion())
+	api.Use(middleware.ContentNegotiation())
+	api.Use(middleware.Idempotency())
+	api.Use(middleware.OpenAPIValidator())
 
 	// 注册版本路由
 	v1.RegisterRoutes(api)
+	v2.RegisterRoutes(api)
 
 	// 健康检查
 	e.GET("/health", func(c echo.Context) error {
@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"` // 路由组和路由级中间件，按执行顺序
+}
+
+// RouteTable 记录注册的路由及其中间件，须在注册路由之前创建
+type RouteTable struct {
+	Global []string // 全局中间件
+	routes []RouteInfo
+}
+
+// NewRouteTable 通过 echo.OnAddRouteHandler 记录之后注册的路由
+// Echo 不暴露通过 e.Use 注册的全局中间件，需要由调用方传入
+func NewRouteTable(e *echo.Echo, global ...echo.MiddlewareFunc) *RouteTable {
+	table := &RouteTable{Global: make([]string, len(global))}
+	for i, m := range global {
+		table.Global[i] = FuncName(m)
+	}
+	previous := e.OnAddRouteHandler
+	e.OnAddRouteHandler = func(host string, route echo.Route, handler echo.HandlerFunc, middleware []echo.MiddlewareFunc) {
+		if previous != nil {
+			previous(host, route, handler, middleware)
+		}
+		// 路由组注册中间件时附带的 404 兜底路由
+		if route.Method == echo.RouteNotFound {
+			return
+		}
+
+		names := make([]string, len(middleware))
+		for i, m := range middleware {
+			names[i] = FuncName(m)
+		}
+		table.routes = append(table.routes, RouteInfo{
+			Method:     route.Method,
+			Path:       route.Path,
+			Handler:    FuncName(handler),
+			Middleware: names,
+		})
+	}
+	return table
+}
+
+// Routes 返回按路径和方法排序的路由
+func (t *RouteTable) Routes() []RouteInfo {
+	routes := append([]RouteInfo(nil), t.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// 闭包和方法值的函数名后缀，例如 .func1、-fm
+var funcSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// FuncName 返回函数的简短名称，例如 middleware.RequireRole、controllers.(*UserController).GetUsers
+func FuncName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := funcSuffix.ReplaceAllString(f.Name(), "")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -2,23 +2,69 @@ package v1
 
 import (
 	"echo-template/app/controllers"
+	"echo-template/app/models"
+	"echo-template/middleware"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// 不分页的用户列表已由 v2 的分页列表替代
+var listUsersDeprecation = middleware.Deprecation{
+	Since:     time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	Successor: "/api/v2/users",
+}
+
 // RegisterRoutes 注册 v1 版本的路由
 func RegisterRoutes(api *echo.Group) {
 	v1 := api.Group("/v1")
 
-	// 用户路由
+	// 登录路由（OIDC 身份提供方）
+	authController := controllers.NewAuthController()
+	auth := v1.Group("/auth")
+	{
+		auth.GET("/providers", authController.GetProviders)
+		auth.GET("/oidc/:provider/login", authController.Login)
+		auth.GET("/oidc/:provider/callback", authController.Callback)
+		auth.POST("/oidc/:provider/link", authController.Link, middleware.RequireAuth())
+		auth.GET("/me", authController.GetCurrentUser, middleware.RequireAuth())
+	}
+
+	// 用户路由（修改需要认证，非管理员只能修改和删除自己）
 	userController := controllers.NewUserController()
-	users := v1.Group("/users")
+	requireAdmin := []echo.MiddlewareFunc{middleware.RequireAuth(), middleware.RequireRole(models.RoleAdmin)}
+	requireSelf := []echo.MiddlewareFunc{middleware.RequireAuth(), middleware.RequireSelfOrRole("id", models.RoleAdmin)}
+	users := v1.Group("/users", middleware.RequireScope(models.ScopeResourceUsers))
 	{
-		users.GET("", userController.GetUsers)
+		users.GET("", userController.GetUsers, middleware.Deprecated(listUsersDeprecation))
+		users.GET("/search", userController.SearchUsers)
 		users.GET("/:id", userController.GetUser)
-		users.POST("", userController.CreateUser)
-		users.PUT("/:id", userController.UpdateUser)
-		users.DELETE("/:id", userController.DeleteUser)
+		users.POST("", userController.CreateUser, middleware.RequireAuth(), middleware.RateLimit("users_create"))
+		users.POST("/batch", userController.BatchUsers, append(requireAdmin, middleware.RateLimit("users_create"))...)
+		users.PUT("/:id", userController.UpdateUser, requireSelf...)
+		// If-Match 的检查和删除在同一事务中执行
+		users.DELETE("/:id", userController.DeleteUser, append(requireSelf, middleware.Transactional())...)
+		users.POST("/:id/restore", userController.RestoreUser, requireAdmin...)
+		users.GET("/export", userController.ExportUsers, requireAdmin...)
+		users.POST("/import", userController.ImportUsers, requireAdmin...)
+	}
+
+	// 审计日志路由（仅管理员）
+	auditLogController := controllers.NewAuditLogController()
+	auditLogs := v1.Group("/audit-logs", middleware.RequireRole(models.RoleAdmin), middleware.RequireScope(models.ScopeResourceAuditLogs))
+	{
+		auditLogs.GET("", auditLogController.GetAuditLogs)
+	}
+
+	// API Key 路由（需要认证，非管理员只能管理自己的密钥）
+	apiKeyController := controllers.NewAPIKeyController()
+	apiKeys := v1.Group("/api-keys", middleware.RequireAuth(), middleware.RequireScope(models.ScopeResourceAPIKeys))
+	{
+		apiKeys.GET("", apiKeyController.GetAPIKeys)
+		apiKeys.GET("/:id", apiKeyController.GetAPIKey)
+		apiKeys.POST("", apiKeyController.CreateAPIKey)
+		apiKeys.PUT("/:id", apiKeyController.UpdateAPIKey)
+		apiKeys.DELETE("/:id", apiKeyController.DeleteAPIKey)
 	}
 }
 
@@ -0,0 +1,27 @@
+package v2
+
+import (
+	controllers "echo-template/app/controllers/v2"
+	"echo-template/app/models"
+	"echo-template/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes 注册 v2 版本的路由
+func RegisterRoutes(api *echo.Group) {
+	v2 := api.Group("/v2")
+
+	// 用户路由（修改需要认证，非管理员只能修改和删除自己）
+	userController := controllers.NewUserController()
+	requireSelf := []echo.MiddlewareFunc{middleware.RequireAuth(), middleware.RequireSelfOrRole("id", models.RoleAdmin)}
+	users := v2.Group("/users", middleware.RequireScope(models.ScopeResourceUsers))
+	{
+		users.GET("", userController.ListUsers)
+		users.GET("/:id", userController.GetUser)
+		users.POST("", userController.CreateUser, middleware.RequireAuth(), middleware.RateLimit("users_create"))
+		users.PUT("/:id", userController.UpdateUser, requireSelf...)
+		// If-Match 的检查和删除在同一事务中执行
+		users.DELETE("/:id", userController.DeleteUser, append(requireSelf, middleware.Transactional())...)
+	}
+}
@@ -0,0 +1,68 @@
+package seeders
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/config"
+	"echo-template/database"
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(Seeder{
+		Name: "admin",
+		Sets: []string{config.EnvProduction},
+		Run:  seedAdmin,
+	})
+	Register(Seeder{
+		Name: "fixtures",
+		Run:  seedFixtures,
+	})
+}
+
+// seedAdmin 创建初始管理员，已存在同名用户时保持不变（不会重置密码）
+func seedAdmin(ctx context.Context, db *gorm.DB, _ string) error {
+	cfg := config.AppConfig.Seed
+	if cfg.AdminPassword == "" {
+		return errors.New("SEED_ADMIN_PASSWORD is required to create the admin user")
+	}
+
+	stats, err := database.ApplyFixture(ctx, db, models.All(), &database.Fixture{
+		Model:      "users",
+		Key:        []string{"username"},
+		OnConflict: database.FixtureSkip,
+		Rows: []map[string]interface{}{{
+			"username": cfg.AdminUsername,
+			"email":    cfg.AdminEmail,
+			"password": cfg.AdminPassword,
+			"name":     "管理员",
+			"role":     models.RoleAdmin,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if stats.Created > 0 {
+		log.Printf("admin user %q created", cfg.AdminUsername)
+	}
+	return nil
+}
+
+// seedFixtures 加载 <SEED_FIXTURES_DIR>/<set> 下的夹具文件，目录不存在时跳过
+func seedFixtures(ctx context.Context, db *gorm.DB, set string) error {
+	dir := filepath.Join(config.AppConfig.Seed.FixturesDir, set)
+	stats, err := LoadFixtureDir(ctx, db, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("fixtures %s: %d created, %d updated, %d unchanged", dir, stats.Created, stats.Updated, stats.Unchanged)
+	return nil
+}
@@ -0,0 +1,78 @@
+package seeders
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/database"
+	"fmt"
+	"log"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Seeder 数据填充器，在 init 中通过 Register 注册
+type Seeder struct {
+	Name string
+	Sets []string // 所属数据集（通常为运行环境名），为空时属于所有数据集
+	// Run 在事务中执行，通过 database.Conn(ctx, db) 获取连接；应当是幂等的，重复执行不会产生重复数据
+	Run func(ctx context.Context, db *gorm.DB, set string) error
+}
+
+var registry []Seeder
+
+// Register 注册填充器，按注册顺序执行；名称重复时 panic
+func Register(seeder Seeder) {
+	for _, registered := range registry {
+		if registered.Name == seeder.Name {
+			panic(fmt.Sprintf("seeders: duplicate seeder %q", seeder.Name))
+		}
+	}
+	registry = append(registry, seeder)
+}
+
+// All 返回属于数据集 set 的填充器
+func All(set string) []Seeder {
+	var seeders []Seeder
+	for _, seeder := range registry {
+		if len(seeder.Sets) == 0 || slices.Contains(seeder.Sets, set) {
+			seeders = append(seeders, seeder)
+		}
+	}
+	return seeders
+}
+
+// Run 执行数据集 set 中的填充器，only 不为空时只执行指定名称的填充器
+// 每个填充器在独立的事务中执行，失败时停止并返回错误
+func Run(ctx context.Context, db *gorm.DB, set string, only ...string) error {
+	seeders := All(set)
+	for _, name := range only {
+		if !slices.ContainsFunc(seeders, func(seeder Seeder) bool { return seeder.Name == name }) {
+			return fmt.Errorf("seeder %q not found in set %q", name, set)
+		}
+	}
+
+	for _, seeder := range seeders {
+		if len(only) > 0 && !slices.Contains(only, seeder.Name) {
+			continue
+		}
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return seeder.Run(database.ContextWithTx(ctx, tx), db, set)
+		})
+		if err != nil {
+			return fmt.Errorf("seeder %s: %w", seeder.Name, err)
+		}
+		log.Printf("seeder %s done", seeder.Name)
+	}
+	return nil
+}
+
+// LoadFixtureDir 加载目录下的全部夹具文件，模型为 models.All()
+// 测试中可以对 SQLite 数据库直接调用：迁移后加载 fixtures/<set> 即得到与开发环境相同的数据
+func LoadFixtureDir(ctx context.Context, db *gorm.DB, dir string) (database.FixtureStats, error) {
+	files, err := database.FixtureFiles(dir)
+	if err != nil {
+		return database.FixtureStats{}, err
+	}
+	return database.LoadFixtures(ctx, db, models.All(), files...)
+}
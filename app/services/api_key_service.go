@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/audit"
+	"echo-template/config"
+	"echo-template/database"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 确保 APIKeyService 实现了 APIKeyServiceInterface
+var _ APIKeyServiceInterface = (*APIKeyService)(nil)
+
+type APIKeyService struct {
+	keys  repositories.Repository[models.APIKey]
+	users repositories.Repository[models.User]
+}
+
+func NewAPIKeyService() *APIKeyService {
+	db := database.GetDB()
+	return &APIKeyService{
+		keys:  repositories.NewRepository[models.APIKey](db, repositories.Options{Name: "resources.api_key"}),
+		users: repositories.NewRepository[models.User](db, repositories.Options{Name: "resources.user"}),
+	}
+}
+
+// Scope 转换为仓储的查询条件
+func (o APIKeyOwner) Scope() repositories.Scope {
+	return repositories.Filter(map[string]interface{}{
+		"user_id":         o.UserID,
+		"service_account": o.ServiceAccount,
+	})
+}
+
+// ListAPIKeys 分页获取未吊销的密钥，按ID排序
+func (ks *APIKeyService) ListAPIKeys(ctx context.Context, owner APIKeyOwner, page utils.Pagination) ([]models.APIKey, int64, error) {
+	return ks.keys.FindPage(ctx, page, owner.Scope(), repositories.Order("id"))
+}
+
+func (ks *APIKeyService) GetAPIKey(ctx context.Context, owner APIKeyOwner, id uint) (*models.APIKey, error) {
+	key, err := ks.keys.First(ctx, owner.Scope(), repositories.ByID(id))
+	if isNotFound(err) {
+		return nil, utils.ErrNotFound("api_key.not_found")
+	}
+	return key, err
+}
+
+// CreateAPIKey 密钥必须且只能属于一个用户或一个服务账号；未指定过期时间时按 API_KEY_DEFAULT_TTL 设置
+func (ks *APIKeyService) CreateAPIKey(ctx context.Context, key *models.APIKey) (string, error) {
+	if (key.UserID == nil) == (key.ServiceAccount == "") {
+		return "", utils.ErrBadRequest("api_key.owner_required")
+	}
+	if key.UserID != nil {
+		if key.Role != "" {
+			return "", utils.ErrBadRequest("api_key.invalid_role")
+		}
+		if _, err := ks.users.FindByID(ctx, *key.UserID); err != nil {
+			if isNotFound(err) {
+				return "", utils.ErrBadRequest("api_key.unknown_user", i18n.Args{"ID": *key.UserID})
+			}
+			return "", err
+		}
+	} else {
+		if key.Role == "" {
+			key.Role = models.RoleUser
+		}
+		if key.Role != models.RoleUser && key.Role != models.RoleAdmin {
+			return "", utils.ErrBadRequest("api_key.invalid_role")
+		}
+	}
+
+	var err error
+	if key.Scopes, err = normalizeScopes(key.Scopes); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if key.ExpiresAt == nil {
+		if ttl := config.AppConfig.APIKey.DefaultTTL; ttl > 0 {
+			expiresAt := now.Add(ttl)
+			key.ExpiresAt = &expiresAt
+		}
+	} else if key.Expired(now) {
+		return "", utils.ErrBadRequest("api_key.invalid_expiry")
+	}
+
+	generated, err := utils.GenerateAPIKey(config.AppConfig.APIKey.Prefix)
+	if err != nil {
+		return "", utils.ErrInternal("error.internal", err)
+	}
+	key.ID = 0
+	key.Prefix = generated.Prefix
+	key.SecretHash = generated.SecretHash
+	key.LastUsedAt = nil
+	key.LastUsedIP = ""
+	key.DeletedAt = gorm.DeletedAt{}
+	if err := ks.keys.Create(ctx, key); err != nil {
+		return "", err
+	}
+	return generated.Key, nil
+}
+
+// UpdateAPIKey 仅当数据库中的版本号与 key.Version 一致时更新，成功后 key 为最新数据；
+// 所有者、角色和密钥本身不能修改，需要时吊销后重新创建
+func (ks *APIKeyService) UpdateAPIKey(ctx context.Context, owner APIKeyOwner, key *models.APIKey) error {
+	scopes, err := normalizeScopes(key.Scopes)
+	if err != nil {
+		return err
+	}
+	if key.Expired(time.Now()) {
+		return utils.ErrBadRequest("api_key.invalid_expiry")
+	}
+
+	rows, err := ks.keys.UpdateColumns(ctx, map[string]interface{}{
+		"name":       key.Name,
+		"scopes":     scopes,
+		"expires_at": key.ExpiresAt,
+		"version":    gorm.Expr("version + 1"),
+	}, owner.Scope(), repositories.ByID(key.ID), repositories.Where("version = ?", key.Version))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		current, err := ks.GetAPIKey(ctx, owner, key.ID)
+		if err != nil {
+			return err
+		}
+		return utils.ErrConflict("api_key.version_conflict", map[string]uint{
+			"current_version": current.Version,
+		})
+	}
+
+	current, err := ks.GetAPIKey(ctx, owner, key.ID)
+	if err != nil {
+		return err
+	}
+	*key = *current
+	return nil
+}
+
+func (ks *APIKeyService) DeleteAPIKey(ctx context.Context, owner APIKeyOwner, id uint) error {
+	rows, err := ks.keys.Delete(ctx, owner.Scope(), repositories.ByID(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.ErrNotFound("api_key.not_found")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey 格式不符、不存在、已吊销、密钥不匹配以及所属用户已删除时都返回同一错误，不泄露密钥是否存在
+func (ks *APIKeyService) AuthenticateAPIKey(ctx context.Context, key, ip string) (*utils.APIKeyIdentity, error) {
+	invalid := utils.ErrUnauthorized("auth.invalid_api_key")
+	prefix, secret, ok := utils.ParseAPIKey(config.AppConfig.APIKey.Prefix, key)
+	if !ok {
+		return nil, invalid
+	}
+	apiKey, err := ks.keys.First(ctx, repositories.Filter(map[string]interface{}{"prefix": prefix}))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if !utils.CheckAPIKeySecret(apiKey.SecretHash, secret) {
+		return nil, invalid
+	}
+	now := time.Now()
+	if apiKey.Expired(now) {
+		return nil, utils.ErrUnauthorized("auth.api_key_expired")
+	}
+
+	identity := &utils.APIKeyIdentity{
+		ID:             apiKey.ID,
+		Prefix:         apiKey.Prefix,
+		ServiceAccount: apiKey.ServiceAccount,
+		Role:           apiKey.Role,
+		Scopes:         apiKey.Scopes,
+	}
+	if apiKey.UserID != nil {
+		user, err := ks.users.FindByID(ctx, *apiKey.UserID)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, invalid
+			}
+			return nil, err
+		}
+		identity.UserID = user.ID
+		identity.Role = user.Role
+		identity.Locale = user.Locale
+	}
+
+	ks.touch(ctx, apiKey, identity, ip, now)
+	return identity, nil
+}
+
+// touch 记录最近使用时间和 IP，距上次记录不足 API_KEY_LAST_USED_INTERVAL 且 IP 未变时不写库。
+// 不增加版本号，使用密钥不会与管理操作产生版本冲突；以密钥本身为操作人写入审计日志，作为密钥的使用记录。
+// 写入失败只记录日志，不影响本次认证
+func (ks *APIKeyService) touch(ctx context.Context, key *models.APIKey, identity *utils.APIKeyIdentity, ip string, now time.Time) {
+	if key.LastUsedAt != nil && key.LastUsedIP == ip && now.Sub(*key.LastUsedAt) < config.AppConfig.APIKey.LastUsedInterval {
+		return
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	actor.UserID = identity.UserID
+	actor.APIKeyID = identity.ID
+	actor.Type = audit.ActorService
+	if identity.UserID != 0 {
+		actor.Type = audit.ActorUser
+	}
+	ctx = audit.WithActorFunc(ctx, func() audit.Actor { return actor })
+
+	if _, err := ks.keys.UpdateColumns(ctx, map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	}, repositories.ByID(key.ID)); err != nil {
+		log.Printf("api key %s: record last use: %v", key.Prefix, err)
+	}
+}
+
+// normalizeScopes 校验权限范围并去重
+func normalizeScopes(scopes []string) (models.StringList, error) {
+	normalized := make(models.StringList, 0, len(scopes))
+	for _, scope := range scopes {
+		if !models.ValidScope(scope) {
+			return nil, utils.ErrBadRequest("api_key.invalid_scope", i18n.Args{"Scope": scope})
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, utils.ErrInvalidParam("scopes")
+	}
+	return normalized, nil
+}
+
+// isNotFound 仓储返回的错误是否为记录不存在
+func isNotFound(err error) bool {
+	var appErr *utils.AppError
+	return errors.As(err, &appErr) && appErr.Code == http.StatusNotFound
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/database"
+	"echo-template/utils"
+)
+
+// 确保 AuditLogService 实现了 AuditLogServiceInterface
+var _ AuditLogServiceInterface = (*AuditLogService)(nil)
+
+type AuditLogService struct {
+	logs repositories.Repository[models.AuditLog]
+}
+
+func NewAuditLogService() *AuditLogService {
+	return &AuditLogService{
+		logs: repositories.NewRepository[models.AuditLog](database.GetDB(), repositories.Options{Name: "resources.audit_log"}),
+	}
+}
+
+func (as *AuditLogService) ListAuditLogs(ctx context.Context, filter AuditLogFilter, page utils.Pagination) ([]models.AuditLog, int64, error) {
+	scopes := []repositories.Scope{
+		repositories.Filter(map[string]interface{}{
+			"actor_id":   filter.ActorID,
+			"api_key_id": filter.APIKeyID,
+			"action":     filter.Action,
+			"table_name": filter.Table,
+			"record_id":  filter.RecordID,
+			"request_id": filter.RequestID,
+		}),
+		repositories.Order("id DESC"),
+	}
+	if !filter.From.IsZero() {
+		scopes = append(scopes, repositories.Where("created_at >= ?", filter.From))
+	}
+	if !filter.To.IsZero() {
+		scopes = append(scopes, repositories.Where("created_at < ?", filter.To))
+	}
+	return as.logs.FindPage(ctx, page, scopes...)
+}
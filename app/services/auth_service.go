@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/audit"
+	"echo-template/config"
+	"echo-template/database"
+	"echo-template/i18n"
+	"echo-template/oidc"
+	"echo-template/utils"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// 确保 AuthService 实现了 AuthServiceInterface
+var _ AuthServiceInterface = (*AuthService)(nil)
+
+// 新用户的用户名冲突时追加序号的最大尝试次数，超出后追加随机后缀
+const maxUsernameAttempts = 20
+
+type AuthService struct {
+	users      repositories.Repository[models.User]
+	identities repositories.Repository[models.OIDCIdentity]
+	providers  *oidc.Registry
+	tokenKey   []byte // 访问令牌的签名密钥
+	stateKey   []byte // 登录状态的加密密钥
+}
+
+func NewAuthService() *AuthService {
+	return NewAuthServiceWithProviders(oidc.NewRegistry(config.AppConfig.Auth.OIDCProviders, nil))
+}
+
+// NewAuthServiceWithProviders 使用指定的身份提供方创建服务，测试时可传入指向 oidctest 服务的身份提供方
+func NewAuthServiceWithProviders(providers *oidc.Registry) *AuthService {
+	db := database.GetDB()
+	secret := config.AppConfig.Auth.Secret
+	return &AuthService{
+		users: repositories.NewRepository[models.User](db, repositories.Options{
+			Name:     "resources.user",
+			Conflict: "user.conflict",
+		}),
+		identities: repositories.NewRepository[models.OIDCIdentity](db, repositories.Options{Name: "resources.oidc_identity"}),
+		providers:  providers,
+		tokenKey:   utils.DeriveKey(secret, "access-token"),
+		stateKey:   utils.DeriveKey(secret, "oidc-state"),
+	}
+}
+
+func (as *AuthService) Providers() []OIDCProvider {
+	list := as.providers.List()
+	providers := make([]OIDCProvider, 0, len(list))
+	for _, provider := range list {
+		cfg := provider.Config()
+		providers = append(providers, OIDCProvider{Name: cfg.Name, DisplayName: cfg.DisplayName})
+	}
+	return providers
+}
+
+func (as *AuthService) provider(name string) (*oidc.Provider, error) {
+	provider, ok := as.providers.Get(name)
+	if !ok {
+		return nil, utils.ErrNotFound("auth.oidc_unknown_provider", i18n.Args{"Provider": name})
+	}
+	return provider, nil
+}
+
+func (as *AuthService) BeginLogin(ctx context.Context, name string) (string, string, error) {
+	return as.begin(ctx, name, 0)
+}
+
+func (as *AuthService) BeginLink(ctx context.Context, name string, userID uint) (string, string, error) {
+	return as.begin(ctx, name, userID)
+}
+
+// begin 生成登录状态和授权地址，linkUserID 不为 0 时回调将身份关联到该用户
+func (as *AuthService) begin(ctx context.Context, name string, linkUserID uint) (string, string, error) {
+	provider, err := as.provider(name)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := oidc.NewLoginState(name, config.AppConfig.Auth.StateTTL)
+	if err != nil {
+		return "", "", utils.ErrInternal("error.internal", err)
+	}
+	state.LinkUserID = linkUserID
+	sealed, err := state.Seal(as.stateKey)
+	if err != nil {
+		return "", "", utils.ErrInternal("error.internal", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, state)
+	if err != nil {
+		return "", "", loginError(name, err)
+	}
+	return authURL, sealed, nil
+}
+
+func (as *AuthService) CompleteLogin(ctx context.Context, name, sealedState, state, code string) (*LoginResult, error) {
+	provider, err := as.provider(name)
+	if err != nil {
+		return nil, err
+	}
+	loginState, err := oidc.OpenLoginState(as.stateKey, name, sealedState)
+	if err != nil || subtle.ConstantTimeCompare([]byte(loginState.State), []byte(state)) != 1 {
+		return nil, utils.ErrBadRequest("auth.oidc_invalid_state")
+	}
+	if code == "" {
+		return nil, utils.ErrInvalidParam("code")
+	}
+
+	claims, err := provider.Exchange(ctx, loginState, code)
+	if err != nil {
+		return nil, loginError(name, err)
+	}
+	var user *models.User
+	var created bool
+	if loginState.LinkUserID != 0 {
+		user, err = as.linkUser(ctx, provider.Config(), claims, loginState.LinkUserID)
+	} else {
+		user, created, err = as.resolveUser(ctx, provider.Config(), claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := config.AppConfig.Auth.TokenTTL
+	token, expiresAt, err := utils.IssueAccessToken(as.tokenKey, user.ID, ttl)
+	if err != nil {
+		return nil, utils.ErrInternal("error.internal", err)
+	}
+	return &LoginResult{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		ExpiresAt:   expiresAt,
+		User:        user,
+		Created:     created,
+	}, nil
+}
+
+// loginError 将登录流程中的错误转换为响应，身份提供方的原始错误只记录日志
+func loginError(provider string, err error) error {
+	log.Printf("oidc %s: %v", provider, err)
+	switch {
+	case errors.Is(err, oidc.ErrUnavailable), errors.Is(err, oidc.ErrUnsupportedFlow):
+		return utils.NewAppError(http.StatusBadGateway, "auth.oidc_unavailable", err, i18n.Args{"Provider": provider})
+	case errors.Is(err, oidc.ErrExchange):
+		return utils.ErrUnauthorized("auth.oidc_exchange_failed")
+	case errors.Is(err, oidc.ErrNonceMismatch):
+		return utils.ErrUnauthorized("auth.oidc_invalid_nonce")
+	case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrMissingSubject):
+		return utils.ErrUnauthorized("auth.oidc_invalid_id_token")
+	default:
+		return utils.ErrInternal("error.internal", err)
+	}
+}
+
+// resolveUser 将身份提供方的声明映射为本地用户，返回用户以及是否为新建的用户：
+//  1. 已关联的身份：使用关联的用户，已软删除的用户不能登录；
+//  2. 邮箱已验证且与未删除的普通用户一致：关联该用户；
+//  3. 允许自动注册时创建用户（没有本地密码），否则拒绝登录。
+//
+// 未验证的邮箱不会关联已有用户，邮箱已被使用时也不会新建用户，避免通过身份提供方冒用他人的账号；
+// 管理员不按邮箱自动关联，须登录后通过 BeginLink 显式关联
+func (as *AuthService) resolveUser(ctx context.Context, cfg config.OIDCConfig, claims *oidc.Claims) (*models.User, bool, error) {
+	var user *models.User
+	var created bool
+	err := as.users.Transaction(ctx, func(ctx context.Context) error {
+		identity, err := as.identities.First(ctx, repositories.Filter(map[string]interface{}{
+			"provider": cfg.Name,
+			"subject":  claims.Subject,
+		}))
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if identity != nil {
+			linked, err := as.users.First(ctx, repositories.WithTrashed(), repositories.ByID(identity.UserID))
+			if err != nil && !isNotFound(err) {
+				return err
+			}
+			if linked != nil {
+				if linked.DeletedAt.Valid {
+					return utils.ErrForbidden("auth.account_disabled")
+				}
+				user = linked
+				return as.recordLogin(ctx, identity, claims)
+			}
+			// 关联的用户已被彻底删除，按首次登录处理
+			if _, err := as.identities.ForceDelete(ctx, repositories.ByID(identity.ID)); err != nil {
+				return err
+			}
+		}
+
+		user, created, err = as.findOrCreateUser(ctx, cfg, claims)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return as.identities.Create(asUser(ctx, user.ID), &models.OIDCIdentity{
+			UserID:      user.ID,
+			Provider:    cfg.Name,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: &now,
+		})
+	})
+	return user, created, err
+}
+
+// findOrCreateUser 按已验证的邮箱查找用户，找不到时按配置自动注册
+func (as *AuthService) findOrCreateUser(ctx context.Context, cfg config.OIDCConfig, claims *oidc.Claims) (*models.User, bool, error) {
+	if claims.Email == "" {
+		return nil, false, utils.ErrForbidden("auth.oidc_email_required")
+	}
+	existing, err := as.users.First(ctx, repositories.Where("LOWER(email) = ?", strings.ToLower(claims.Email)))
+	if err != nil && !isNotFound(err) {
+		return nil, false, err
+	}
+	if existing != nil {
+		if !claims.EmailVerified {
+			return nil, false, utils.ErrConflict("auth.oidc_email_unverified", nil)
+		}
+		// 邮箱可能被他人修改或在身份提供方中伪造，管理员权限过高，不自动关联
+		if existing.Role == models.RoleAdmin {
+			return nil, false, utils.ErrConflict("auth.oidc_link_required", nil)
+		}
+		// 每个用户在同一身份提供方中只关联一个身份
+		linked, err := as.identities.Count(ctx, repositories.Filter(map[string]interface{}{
+			"user_id":  existing.ID,
+			"provider": cfg.Name,
+		}))
+		if err != nil {
+			return nil, false, err
+		}
+		if linked > 0 {
+			return nil, false, utils.ErrConflict("auth.oidc_already_linked", nil)
+		}
+		return existing, false, nil
+	}
+	if !cfg.AllowSignup {
+		return nil, false, utils.ErrForbidden("auth.oidc_signup_disabled")
+	}
+
+	username, err := as.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, false, err
+	}
+	user := &models.User{
+		Username: username,
+		Email:    claims.Email,
+		Name:     claims.Name,
+		Role:     models.RoleUser,
+	}
+	if tag, err := language.Parse(claims.Locale); err == nil {
+		user.Locale = tag.String()
+	}
+	if err := as.users.Create(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// linkUser 将身份关联到发起关联的用户，不比较邮箱；身份已关联其他用户，或该用户已关联同一身份提供方的其他身份时返回 409
+func (as *AuthService) linkUser(ctx context.Context, cfg config.OIDCConfig, claims *oidc.Claims, userID uint) (*models.User, error) {
+	var user *models.User
+	err := as.users.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = as.users.FindByID(ctx, userID)
+		if err != nil {
+			if isNotFound(err) {
+				return utils.ErrForbidden("auth.account_disabled")
+			}
+			return err
+		}
+
+		identity, err := as.identities.First(ctx, repositories.Filter(map[string]interface{}{
+			"provider": cfg.Name,
+			"subject":  claims.Subject,
+		}))
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if identity != nil {
+			if identity.UserID == userID {
+				return as.recordLogin(ctx, identity, claims)
+			}
+			linked, err := as.users.Count(ctx, repositories.WithTrashed(), repositories.ByID(identity.UserID))
+			if err != nil {
+				return err
+			}
+			if linked > 0 {
+				return utils.ErrConflict("auth.oidc_identity_taken", nil)
+			}
+			// 关联的用户已被彻底删除，改为关联当前用户
+			if _, err := as.identities.ForceDelete(ctx, repositories.ByID(identity.ID)); err != nil {
+				return err
+			}
+		}
+
+		count, err := as.identities.Count(ctx, repositories.Filter(map[string]interface{}{
+			"user_id":  userID,
+			"provider": cfg.Name,
+		}))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return utils.ErrConflict("auth.oidc_already_linked", nil)
+		}
+		now := time.Now()
+		return as.identities.Create(asUser(ctx, userID), &models.OIDCIdentity{
+			UserID:      userID,
+			Provider:    cfg.Name,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: &now,
+		})
+	})
+	return user, err
+}
+
+// availableUsername 新用户的用户名：依次取用户名声明、邮箱的本地部分，已被使用时追加序号
+func (as *AuthService) availableUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := strings.TrimSpace(claims.Username)
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	candidate := base
+	for i := 2; i <= maxUsernameAttempts+1; i++ {
+		count, err := as.users.Count(ctx, repositories.Filter(map[string]interface{}{"username": candidate}))
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", utils.ErrInternal("error.internal", err)
+	}
+	return base + "_" + hex.EncodeToString(suffix), nil
+}
+
+// recordLogin 记录最近登录时间和身份提供方返回的邮箱，以用户本人为操作人写入审计日志，作为登录记录
+func (as *AuthService) recordLogin(ctx context.Context, identity *models.OIDCIdentity, claims *oidc.Claims) error {
+	_, err := as.identities.UpdateColumns(asUser(ctx, identity.UserID), map[string]interface{}{
+		"email":         claims.Email,
+		"last_login_at": time.Now(),
+	}, repositories.ByID(identity.ID))
+	return err
+}
+
+// asUser 以登录的用户为审计日志的操作人，登录请求本身未认证
+func asUser(ctx context.Context, userID uint) context.Context {
+	actor := audit.ActorFromContext(ctx)
+	actor.UserID = userID
+	actor.Type = audit.ActorUser
+	return audit.WithActorFunc(ctx, func() audit.Actor { return actor })
+}
+
+// AuthenticateToken 令牌无效、已过期以及所属用户已删除时都返回同一错误
+func (as *AuthService) AuthenticateToken(ctx context.Context, token string) (*utils.UserIdentity, error) {
+	id, err := utils.ParseAccessToken(as.tokenKey, token)
+	if err != nil {
+		return nil, utils.ErrUnauthorized("auth.invalid_token")
+	}
+	user, err := as.users.FindByID(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, utils.ErrUnauthorized("auth.invalid_token")
+		}
+		return nil, err
+	}
+	return &utils.UserIdentity{ID: user.ID, Role: user.Role, Locale: user.Locale}, nil
+}
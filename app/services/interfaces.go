@@ -1,13 +1,167 @@
 package services
 
-import "echo-template/app/models"
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrashedFilter 软删除记录的查询范围
+type TrashedFilter string
+
+const (
+	TrashedExclude TrashedFilter = ""     // 仅未删除的记录（默认）
+	TrashedWith    TrashedFilter = "with" // 包含已删除的记录
+	TrashedOnly    TrashedFilter = "only" // 仅已删除的记录
+)
+
+// Scope 转换为仓储的查询条件
+func (f TrashedFilter) Scope() repositories.Scope {
+	switch f {
+	case TrashedWith:
+		return repositories.WithTrashed()
+	case TrashedOnly:
+		return repositories.OnlyTrashed()
+	default:
+		return func(db *gorm.DB) *gorm.DB { return db }
+	}
+}
 
 // UserServiceInterface 用户服务接口
 type UserServiceInterface interface {
-	GetAllUsers() ([]models.User, error)
-	GetUserByID(id uint) (*models.User, error)
-	CreateUser(user *models.User) error
-	UpdateUser(user *models.User) error
-	DeleteUser(id uint) error
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	GetAllUsers(ctx context.Context, trashed TrashedFilter) ([]models.User, error)
+	ListUsers(ctx context.Context, trashed TrashedFilter, page utils.Pagination) ([]models.User, int64, error)
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+	DeleteUser(ctx context.Context, id uint) error
+	RestoreUser(ctx context.Context, id uint) (*models.User, error)
+	AssignRole(ctx context.Context, id uint, role string) error
+	ForceDeleteUser(ctx context.Context, id uint) error
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+	BatchUsers(ctx context.Context, ops []BatchUserOperation, opts BatchOptions) (*BatchResult, error)
+	ExportUsers(ctx context.Context, trashed TrashedFilter, batchSize int, fn func(users []models.User) error) error
+	ImportUsers(ctx context.Context, r io.Reader, format string, opts ImportOptions) (*ImportResult, error)
+	SearchUsers(ctx context.Context, query string, page utils.Pagination) ([]UserSearchResult, int64, error)
+}
+
+// BatchOp 批量操作类型
+type BatchOp string
+
+const (
+	BatchOpCreate BatchOp = "create"
+	BatchOpUpdate BatchOp = "update"
+	BatchOpDelete BatchOp = "delete"
+)
+
+// BatchUserOperation 批量操作中的单个操作
+// create 需要 user；update 需要 id 和携带 version 的 user；delete 需要 id
+type BatchUserOperation struct {
+	Op   BatchOp      `json:"op" enums:"create,update,delete" example:"create"` // 操作类型
+	ID   uint         `json:"id,omitempty" example:"1"`                         // 用户ID（update/delete）
+	User *models.User `json:"user,omitempty"`                                   // 用户信息（create/update）
+
+	Columns []string `json:"-"` // update 时只写入这些列，nil 表示全部可修改列；导入时为文件中出现的列
+}
+
+// BatchOptions 批量操作选项
+type BatchOptions struct {
+	Atomic    bool // 为 true 时任一项失败即全部回滚
+	BatchSize int  // 批量创建时每批写入的记录数
+}
+
+// BatchItemResult 单个操作的执行结果
+type BatchItemResult struct {
+	Index   int          `json:"index" example:"0"`                      // 在请求中的位置
+	Op      BatchOp      `json:"op,omitempty" example:"create"`          // 操作类型（无法解析的导入行为空）
+	ID      uint         `json:"id,omitempty" example:"1"`               // 用户ID
+	Success bool         `json:"success" example:"true"`                 // 是否成功
+	Status  int          `json:"status" example:"201"`                   // 与单个接口一致的 HTTP 状态码
+	Error   string       `json:"error,omitempty"`                        // 失败原因
+	Details interface{}  `json:"details,omitempty" swaggerignore:"true"` // 失败详情，例如字段校验错误
+	Data    *models.User `json:"data,omitempty"`                         // 操作后的用户信息
+
+	message i18n.Message // 失败原因的消息键，写出响应时按请求的语言渲染到 Error
 }
 
+// BatchResult 批量操作结果
+type BatchResult struct {
+	Atomic    bool              `json:"atomic" example:"true"` // 是否为原子模式
+	Succeeded int               `json:"succeeded" example:"2"` // 成功数
+	Failed    int               `json:"failed" example:"0"`    // 失败数
+	Items     []BatchItemResult `json:"items"`                 // 逐项结果，顺序与请求一致
+}
+
+// AuditLogFilter 审计日志查询条件，零值表示不过滤
+type AuditLogFilter struct {
+	ActorID   uint
+	APIKeyID  uint
+	Action    string
+	Table     string
+	RecordID  string
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditLogServiceInterface 审计日志服务接口
+type AuditLogServiceInterface interface {
+	ListAuditLogs(ctx context.Context, filter AuditLogFilter, page utils.Pagination) ([]models.AuditLog, int64, error)
+}
+
+// APIKeyOwner 管理接口中可访问的密钥范围，零值表示不限（管理员）
+type APIKeyOwner struct {
+	UserID         uint   // 只能访问该用户的密钥
+	ServiceAccount string // 只能访问该服务账号的密钥
+}
+
+// APIKeyServiceInterface API Key 服务接口
+type APIKeyServiceInterface interface {
+	ListAPIKeys(ctx context.Context, owner APIKeyOwner, page utils.Pagination) ([]models.APIKey, int64, error)
+	GetAPIKey(ctx context.Context, owner APIKeyOwner, id uint) (*models.APIKey, error)
+	// CreateAPIKey 生成并保存密钥，返回完整的明文密钥，之后无法再次获取
+	CreateAPIKey(ctx context.Context, key *models.APIKey) (string, error)
+	// UpdateAPIKey 更新名称、权限范围和过期时间（乐观锁）
+	UpdateAPIKey(ctx context.Context, owner APIKeyOwner, key *models.APIKey) error
+	// DeleteAPIKey 吊销密钥（软删除），吊销后立即失效
+	DeleteAPIKey(ctx context.Context, owner APIKeyOwner, id uint) error
+	// AuthenticateAPIKey 校验完整的明文密钥，成功时记录最近使用时间和 IP，返回调用方身份
+	AuthenticateAPIKey(ctx context.Context, key, ip string) (*utils.APIKeyIdentity, error)
+}
+
+// OIDCProvider 可用于登录的身份提供方
+type OIDCProvider struct {
+	Name        string `json:"name" example:"corp"`           // 名称
+	DisplayName string `json:"display_name" example:"公司 SSO"` // 展示名称
+}
+
+// LoginResult 登录成功后签发的访问令牌
+type LoginResult struct {
+	AccessToken string       `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // 访问令牌，通过 Authorization: Bearer 传递
+	TokenType   string       `json:"token_type" example:"Bearer"`                                    // 令牌类型
+	ExpiresIn   int          `json:"expires_in" example:"43200"`                                     // 有效期（秒）
+	ExpiresAt   time.Time    `json:"expires_at" example:"2024-01-01T12:00:00Z" format:"date-time"`   // 过期时间
+	User        *models.User `json:"user"`                                                           // 登录的用户
+	Created     bool         `json:"created" example:"false"`                                        // 是否为首次登录时自动创建的用户
+}
+
+// AuthServiceInterface 用户登录服务接口
+type AuthServiceInterface interface {
+	// Providers 可用于登录的身份提供方，按名称排序
+	Providers() []OIDCProvider
+	// BeginLogin 发起登录，返回身份提供方的授权地址和加密的登录状态，登录状态须保存到回调时使用
+	BeginLogin(ctx context.Context, provider string) (authURL, sealedState string, err error)
+	// BeginLink 已登录的用户发起关联身份提供方，回调时将身份关联到该用户，返回值同 BeginLogin
+	BeginLink(ctx context.Context, provider string, userID uint) (authURL, sealedState string, err error)
+	// CompleteLogin 校验回调的 state 与登录状态一致，换取并校验 ID Token，将声明映射为本地用户（或关联到发起关联的用户）后签发访问令牌
+	CompleteLogin(ctx context.Context, provider, sealedState, state, code string) (*LoginResult, error)
+	// AuthenticateToken 校验访问令牌，返回所属的用户
+	AuthenticateToken(ctx context.Context, token string) (*utils.UserIdentity, error)
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/database"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"errors"
+	"net/http"
+)
+
+// BatchUsers 在一个事务中按顺序执行批量操作，遇到死锁等可重试错误时整体重试
+// 连续的创建操作按 opts.BatchSize 分批写入，某一批失败时逐条重试以定位失败项；
+// 原子模式下任一项失败即回滚全部操作并返回 422，Details 为逐项结果；
+// 部分成功模式下失败项回滚到各自的保存点，其余操作照常提交。
+// 每次尝试都使用 ops 中用户的副本，调用方传入的 ops 不会被修改
+func (us *UserService) BatchUsers(ctx context.Context, ops []BatchUserOperation, opts BatchOptions) (*BatchResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+
+	result := &BatchResult{Atomic: opts.Atomic, Items: make([]BatchItemResult, len(ops))}
+	for i, op := range ops {
+		result.Items[i] = BatchItemResult{Index: i, Op: op.Op, ID: op.ID}
+		if err := validateBatchOperation(op); err != nil {
+			result.Items[i].fail(err)
+		}
+	}
+	if opts.Atomic && result.hasFailure() {
+		result.abort("batch.skipped")
+		return result, batchFailed(result)
+	}
+
+	// 重试时从校验后的状态和原始的用户数据重新开始，上一次尝试回填的主键和版本号不会保留
+	validated := append([]BatchItemResult(nil), result.Items...)
+	err := us.Transaction(ctx, func(ctx context.Context) error {
+		copy(result.Items, validated)
+		ops := cloneBatchOperations(ops)
+
+		for start := 0; start < len(ops); {
+			if result.Items[start].Status != 0 {
+				start++
+				continue
+			}
+
+			var err error
+			// 连续的创建操作合并为一批
+			end := start + 1
+			if ops[start].Op == BatchOpCreate {
+				for end < len(ops) && end-start < opts.BatchSize &&
+					ops[end].Op == BatchOpCreate && result.Items[end].Status == 0 {
+					end++
+				}
+				err = us.batchCreate(ctx, ops[start:end], result.Items[start:end], opts.BatchSize)
+			} else {
+				op := ops[start]
+				err = us.runBatchStep(ctx, &result.Items[start], func(ctx context.Context) (*models.User, error) {
+					return us.applyBatchOperation(ctx, op)
+				})
+			}
+			// 死锁等错误交给最外层事务整体重试，而不是作为该项的失败结果
+			if database.IsRetryableError(err) {
+				return err
+			}
+
+			if opts.Atomic && result.hasFailure() {
+				return errBatchAborted
+			}
+			start = end
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchAborted) {
+		result.abort("batch.rolled_back")
+		return result, batchFailed(result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.count()
+	return result, nil
+}
+
+// errBatchAborted 原子模式下有操作失败，用于回滚事务
+var errBatchAborted = errors.New("batch aborted")
+
+// cloneBatchOperations 复制操作及其中的用户，执行时对用户的修改不影响原始数据
+func cloneBatchOperations(ops []BatchUserOperation) []BatchUserOperation {
+	cloned := make([]BatchUserOperation, len(ops))
+	for i, op := range ops {
+		if op.User != nil {
+			user := *op.User
+			op.User = &user
+		}
+		cloned[i] = op
+	}
+	return cloned
+}
+
+// batchCreate 批量创建一组用户，整批失败时逐条重试以确定每一项的结果
+// 遇到可重试的错误时立即返回该错误
+func (us *UserService) batchCreate(ctx context.Context, ops []BatchUserOperation, items []BatchItemResult, batchSize int) error {
+	users := make([]*models.User, len(ops))
+	for i, op := range ops {
+		users[i] = op.User
+	}
+
+	err := us.Transaction(ctx, func(ctx context.Context) error {
+		return us.createUsers(ctx, users, batchSize)
+	})
+	if err == nil {
+		for i, user := range users {
+			items[i].succeed(http.StatusCreated, user)
+		}
+		return nil
+	}
+	if database.IsRetryableError(err) {
+		return err
+	}
+
+	for i, user := range users {
+		// 清除整批插入时可能已回填的主键
+		user.ID = 0
+		err := us.runBatchStep(ctx, &items[i], func(ctx context.Context) (*models.User, error) {
+			return user, us.CreateUser(ctx, user)
+		})
+		if database.IsRetryableError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// runBatchStep 在保存点中执行单个操作，失败时回滚到保存点、记录并返回错误
+func (us *UserService) runBatchStep(ctx context.Context, item *BatchItemResult, step func(ctx context.Context) (*models.User, error)) error {
+	var user *models.User
+	err := us.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = step(ctx)
+		return err
+	})
+	if err != nil {
+		item.fail(err)
+		return err
+	}
+
+	status := http.StatusOK
+	if item.Op == BatchOpCreate {
+		status = http.StatusCreated
+	}
+	item.succeed(status, user)
+	return nil
+}
+
+// applyBatchOperation 执行单个更新或删除操作
+func (us *UserService) applyBatchOperation(ctx context.Context, op BatchUserOperation) (*models.User, error) {
+	switch op.Op {
+	case BatchOpCreate:
+		return op.User, us.CreateUser(ctx, op.User)
+	case BatchOpUpdate:
+		op.User.ID = op.ID
+		return op.User, us.updateUser(ctx, op.User, op.Columns)
+	default:
+		return nil, us.DeleteUser(ctx, op.ID)
+	}
+}
+
+// validateBatchOperation 校验单个操作，错误会作为该项的结果返回
+func validateBatchOperation(op BatchUserOperation) error {
+	switch op.Op {
+	case BatchOpCreate, BatchOpUpdate, BatchOpDelete:
+	default:
+		return utils.ErrInvalidParam("op")
+	}
+	if op.Op != BatchOpCreate && op.ID == 0 {
+		return utils.ErrBadRequest("batch.missing_id")
+	}
+	if op.Op == BatchOpDelete {
+		return nil
+	}
+	if op.User == nil {
+		return utils.ErrBadRequest("batch.missing_user")
+	}
+	return utils.Validate(op.User)
+}
+
+// batchFailed 原子模式下的失败响应，data 为逐项结果
+func batchFailed(result *BatchResult) error {
+	result.count()
+	appErr := utils.NewAppError(http.StatusUnprocessableEntity, "batch.failed", nil)
+	appErr.Details = result
+	return appErr
+}
+
+func (item *BatchItemResult) succeed(status int, user *models.User) {
+	item.Status = status
+	item.Success = true
+	item.Data = user
+	if user != nil {
+		item.ID = user.ID
+	}
+}
+
+func (item *BatchItemResult) fail(err error) {
+	item.Success = false
+	item.Data = nil
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		item.Status = appErr.Code
+		item.message = appErr.I18nMessage()
+		item.Error = appErr.Message()
+		item.Details = appErr.Details
+		return
+	}
+	item.Status = http.StatusInternalServerError
+	item.message = i18n.Message{Key: "error.internal"}
+	item.Error = i18n.T(item.message.Key)
+}
+
+// localize 按请求的语言渲染失败原因
+func (item *BatchItemResult) localize(localizer *i18n.Localizer) {
+	if item.message.Key != "" {
+		item.Error = localizer.Message(item.message)
+	}
+}
+
+// Localize 实现 utils.Localizable
+func (r *BatchResult) Localize(localizer *i18n.Localizer) {
+	for i := range r.Items {
+		r.Items[i].localize(localizer)
+	}
+}
+
+func (r *BatchResult) hasFailure() bool {
+	for _, item := range r.Items {
+		if item.Status != 0 && !item.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// abort 原子模式失败后，将成功或未执行的项标记为 424
+func (r *BatchResult) abort(reason string) {
+	for i := range r.Items {
+		item := &r.Items[i]
+		if item.Status == 0 || item.Success {
+			item.fail(utils.NewAppError(http.StatusFailedDependency, reason, nil))
+			if item.Op == BatchOpCreate {
+				item.ID = 0
+			}
+		}
+	}
+}
+
+func (r *BatchResult) count() {
+	r.Succeeded, r.Failed = 0, 0
+	for _, item := range r.Items {
+		if item.Success {
+			r.Succeeded++
+		} else {
+			r.Failed++
+		}
+	}
+}
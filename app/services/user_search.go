@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/database"
+	"echo-template/utils"
+)
+
+// UserSearchResult 用户检索结果
+type UserSearchResult struct {
+	User       models.User       `json:"user"`
+	Rank       float64           `json:"rank" example:"0.42"`                                           // 相关度，越大越相关
+	Highlights map[string]string `json:"highlights,omitempty" example:"username:<mark>joh</mark>n_doe"` // 命中的字段，匹配部分以 <mark> 标记
+}
+
+// SearchUsers 按相关度检索未删除的用户，返回当前页结果和总数
+func (us *UserService) SearchUsers(ctx context.Context, query string, page utils.Pagination) ([]UserSearchResult, int64, error) {
+	hits, total, err := us.users.Search(ctx, models.UserSearchIndex, query, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []UserSearchResult{}, total, nil
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	users, err := us.users.Find(ctx, repositories.Where("id IN ?", ids))
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	// 保持检索结果的相关度顺序
+	terms := database.SearchTerms(query)
+	results := make([]UserSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		user, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		highlights := make(map[string]string)
+		for field, value := range map[string]string{"username": user.Username, "email": user.Email, "name": user.Name} {
+			if marked := database.Highlight(value, terms); marked != "" {
+				highlights[field] = marked
+			}
+		}
+		results = append(results, UserSearchResult{User: user, Rank: hit.Rank, Highlights: highlights})
+	}
+	return results, total, nil
+}
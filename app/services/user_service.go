@@ -1,10 +1,15 @@
 package services
 
 import (
+	"context"
 	"echo-template/app/models"
+	"echo-template/app/repositories"
 	"echo-template/database"
 	"echo-template/utils"
 	"errors"
+	"net/http"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -13,51 +18,169 @@ import (
 var _ UserServiceInterface = (*UserService)(nil)
 
 type UserService struct {
-	db *gorm.DB
+	users repositories.Repository[models.User]
 }
 
 func NewUserService() *UserService {
-	return &UserService{
-		db: database.GetDB(),
-	}
+	return NewUserServiceWithRepository(repositories.NewRepository[models.User](database.GetDB(), repositories.Options{
+		Name:     "resources.user",
+		Conflict: "user.conflict",
+	}))
+}
+
+// NewUserServiceWithRepository 使用指定的仓储创建用户服务，单元测试时可传入不依赖数据库的实现
+func NewUserServiceWithRepository(users repositories.Repository[models.User]) *UserService {
+	return &UserService{users: users}
+}
+
+// Transaction 在事务中执行 fn，fn 中通过 ctx 调用的服务方法都在同一事务中
+func (us *UserService) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return us.users.Transaction(ctx, fn)
+}
+
+func (us *UserService) GetAllUsers(ctx context.Context, trashed TrashedFilter) ([]models.User, error) {
+	return us.users.Find(ctx, trashed.Scope())
+}
+
+// ListUsers 分页获取用户，按ID排序
+func (us *UserService) ListUsers(ctx context.Context, trashed TrashedFilter, page utils.Pagination) ([]models.User, int64, error) {
+	return us.users.FindPage(ctx, page, trashed.Scope(), repositories.Order("id"))
 }
 
-func (us *UserService) GetAllUsers() ([]models.User, error) {
-	var users []models.User
-	if err := us.db.Find(&users).Error; err != nil {
-		return nil, utils.ErrInternal("查询用户列表失败", err)
+func (us *UserService) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return us.users.FindByID(ctx, id)
+}
+
+func (us *UserService) CreateUser(ctx context.Context, user *models.User) error {
+	return us.createUsers(ctx, []*models.User{user}, 1)
+}
+
+// createUsers 按批次创建用户
+func (us *UserService) createUsers(ctx context.Context, users []*models.User, batchSize int) error {
+	for _, user := range users {
+		// 角色和删除状态不能通过接口指定
+		user.Role = models.RoleUser
+		user.DeletedAt = gorm.DeletedAt{}
 	}
-	return users, nil
+	return us.users.CreateInBatches(ctx, users, batchSize)
 }
 
-func (us *UserService) GetUserByID(id uint) (*models.User, error) {
-	var user models.User
-	if err := us.db.First(&user, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, utils.ErrNotFound("用户不存在")
+// UpdateUser 更新用户（乐观锁）
+// 仅当数据库中的版本号与 user.Version 一致时更新，成功后版本号加一；
+// 版本不一致时返回 409，并在错误中附带当前版本号
+func (us *UserService) UpdateUser(ctx context.Context, user *models.User) error {
+	return us.updateUser(ctx, user, nil)
+}
+
+// userEditableColumns 可以通过接口修改的列
+var userEditableColumns = []string{"username", "email", "name", "locale"}
+
+// updateUser 按版本号更新用户；columns 不为 nil 时只写入其中的可修改列，其余列保持不变
+func (us *UserService) updateUser(ctx context.Context, user *models.User, columns []string) error {
+	omit := []string{"id", "created_at", "deleted_at", "password", "role"}
+	if columns != nil {
+		for _, column := range userEditableColumns {
+			if !slices.Contains(columns, column) {
+				omit = append(omit, column)
+			}
+		}
+	}
+
+	expected := user.Version
+	user.Version = expected + 1
+
+	rows, err := us.users.Update(ctx, user,
+		repositories.Where("version = ?", expected),
+		repositories.Omit(omit...))
+	if err != nil {
+		user.Version = expected
+		return err
+	}
+	if rows == 0 {
+		user.Version = expected
+		current, err := us.users.FindByID(ctx, user.ID)
+		if err != nil {
+			return err
 		}
-		return nil, utils.ErrInternal("查询用户失败", err)
+		return utils.ErrConflict("user.version_conflict", map[string]uint{
+			"current_version": current.Version,
+		})
+	}
+
+	// 重新加载，返回完整的最新数据
+	current, err := us.users.FindByID(ctx, user.ID)
+	if err != nil {
+		return err
 	}
-	return &user, nil
+	*user = *current
+	return nil
 }
 
-func (us *UserService) CreateUser(user *models.User) error {
-	if err := us.db.Create(user).Error; err != nil {
-		return utils.ErrInternal("创建用户失败", err)
+func (us *UserService) DeleteUser(ctx context.Context, id uint) error {
+	rows, err := us.users.Delete(ctx, repositories.ByID(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.ErrNotFound("user.not_found")
 	}
 	return nil
 }
 
-func (us *UserService) UpdateUser(user *models.User) error {
-	if err := us.db.Save(user).Error; err != nil {
-		return utils.ErrInternal("更新用户失败", err)
+// RestoreUser 恢复已软删除的用户，恢复后版本号加一
+func (us *UserService) RestoreUser(ctx context.Context, id uint) (*models.User, error) {
+	rows, err := us.users.UpdateColumns(ctx, map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}, repositories.OnlyTrashed(), repositories.ByID(id))
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == http.StatusConflict {
+			return nil, utils.ErrConflict("user.restore_conflict", nil)
+		}
+		return nil, err
+	}
+	if rows == 0 {
+		// 区分用户不存在和用户未被删除
+		if _, err := us.users.FindByID(ctx, id, repositories.WithTrashed()); err != nil {
+			return nil, err
+		}
+		return nil, utils.ErrConflict("user.not_deleted", nil)
+	}
+	return us.GetUserByID(ctx, id)
+}
+
+// AssignRole 设置用户角色，版本号加一
+func (us *UserService) AssignRole(ctx context.Context, id uint, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return utils.ErrInvalidParam("role")
+	}
+	rows, err := us.users.UpdateColumns(ctx, map[string]interface{}{
+		"role":    role,
+		"version": gorm.Expr("version + 1"),
+	}, repositories.ByID(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.ErrNotFound("user.not_found")
 	}
 	return nil
 }
 
-func (us *UserService) DeleteUser(id uint) error {
-	if err := us.db.Delete(&models.User{}, id).Error; err != nil {
-		return utils.ErrInternal("删除用户失败", err)
+// ForceDeleteUser 彻底删除用户（包括已软删除的用户）
+func (us *UserService) ForceDeleteUser(ctx context.Context, id uint) error {
+	rows, err := us.users.ForceDelete(ctx, repositories.ByID(id))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.ErrNotFound("user.not_found")
 	}
 	return nil
 }
+
+// PurgeDeletedUsers 彻底删除在 before 之前软删除的用户，返回删除的行数
+func (us *UserService) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	return us.users.ForceDelete(ctx, repositories.Where("deleted_at IS NOT NULL AND deleted_at < ?", before))
+}
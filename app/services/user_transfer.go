@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/repositories"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// UserExportColumns 导出的列，密码等敏感字段不在其中
+var UserExportColumns = []string{"id", "username", "email", "name", "locale", "role", "version", "created_at", "updated_at", "deleted_at"}
+
+// UserExportRow 按 UserExportColumns 的顺序返回一行数据
+func UserExportRow(user *models.User) []interface{} {
+	var deletedAt interface{}
+	if user.DeletedAt.Valid {
+		deletedAt = user.DeletedAt.Time
+	}
+	return []interface{}{user.ID, user.Username, user.Email, user.Name, user.Locale, user.Role, user.Version,
+		user.CreatedAt, user.UpdatedAt, deletedAt}
+}
+
+// ExportUsers 按主键顺序分批读取用户并交给 fn 处理，内存中最多保留一批数据
+// 查询只选择导出列，密码哈希不会被读取
+func (us *UserService) ExportUsers(ctx context.Context, trashed TrashedFilter, batchSize int, fn func(users []models.User) error) error {
+	return us.users.FindInBatches(ctx, batchSize, fn, repositories.Select(UserExportColumns...), trashed.Scope())
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	BatchOptions
+	MaxRows int // 单个文件允许的最大行数
+}
+
+// ImportLineResult 单行的导入结果，Line 为文件中的行号（从 1 开始，包含表头）
+type ImportLineResult struct {
+	Line int `json:"line" example:"2"`
+	BatchItemResult
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Atomic    bool               `json:"atomic" example:"true"` // 是否为原子模式
+	Succeeded int                `json:"succeeded" example:"2"` // 成功行数
+	Failed    int                `json:"failed" example:"0"`    // 失败行数
+	Lines     []ImportLineResult `json:"lines"`                 // 逐行结果，顺序与文件一致
+}
+
+// importRecord 导入文件中的一行，未知列（如 created_at、role）会被忽略
+// 带 id 的行更新已有用户，此时必须携带 version，且只写入文件中出现的列；不带 id 的行创建新用户
+type importRecord struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Locale   string `json:"locale"`
+	Version  uint   `json:"version"`
+
+	columns []string // 该行中出现的列名（小写）
+}
+
+// ImportUsers 解析 CSV 或 JSONL 文件并通过 BatchUsers 导入，每行的解析和校验错误单独报告
+// 原子模式下任一行失败即全部回滚并返回 422，Details 为逐行结果
+func (us *UserService) ImportUsers(ctx context.Context, r io.Reader, format string, opts ImportOptions) (*ImportResult, error) {
+	var rows []importRow
+	var err error
+	switch format {
+	case utils.FormatCSV:
+		rows, err = parseCSVImport(r, opts.MaxRows)
+	case utils.FormatJSONL:
+		rows, err = parseJSONLImport(r, opts.MaxRows)
+	default:
+		return nil, utils.ErrInvalidParam("format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrBadRequest("import.empty")
+	}
+
+	result := &ImportResult{Atomic: opts.Atomic, Lines: make([]ImportLineResult, len(rows))}
+	var ops []BatchUserOperation
+	var opRows []int
+	for i, row := range rows {
+		line := &result.Lines[i]
+		line.Line = row.line
+		line.Index = i
+		if row.err != nil {
+			line.fail(row.err)
+			continue
+		}
+		op := row.operation()
+		line.Op, line.ID = op.Op, op.ID
+		ops = append(ops, op)
+		opRows = append(opRows, i)
+	}
+
+	if opts.Atomic && len(ops) < len(rows) {
+		for j, i := range opRows {
+			if err := validateBatchOperation(ops[j]); err != nil {
+				result.Lines[i].fail(err)
+				continue
+			}
+			result.Lines[i].fail(utils.NewAppError(http.StatusFailedDependency, "import.skipped", nil))
+		}
+		return result, importFailed(result)
+	}
+
+	var batch *BatchResult
+	if len(ops) > 0 {
+		batch, err = us.BatchUsers(ctx, ops, opts.BatchOptions)
+		if batch == nil {
+			return nil, err
+		}
+		for j, item := range batch.Items {
+			line := &result.Lines[opRows[j]]
+			item.Index = line.Index
+			line.BatchItemResult = item
+		}
+	}
+
+	if err != nil {
+		return result, importFailed(result)
+	}
+	result.count()
+	return result, nil
+}
+
+// importRow 解析后的一行，err 不为空时表示该行无法解析
+type importRow struct {
+	line   int
+	record importRecord
+	err    error
+}
+
+func (row importRow) operation() BatchUserOperation {
+	user := &models.User{Username: row.record.Username, Email: row.record.Email, Name: row.record.Name, Locale: row.record.Locale}
+	if row.record.ID == 0 {
+		return BatchUserOperation{Op: BatchOpCreate, User: user}
+	}
+	user.Version = row.record.Version
+	// 文件中没有的列保持原值，不会被清空
+	return BatchUserOperation{Op: BatchOpUpdate, ID: row.record.ID, User: user, Columns: row.record.columns}
+}
+
+// appendImportRow 追加一行，超过 maxRows 时返回 413
+func appendImportRow(rows []importRow, row importRow, maxRows int) ([]importRow, error) {
+	if len(rows) >= maxRows {
+		return nil, utils.NewAppError(http.StatusRequestEntityTooLarge, "import.too_many_rows", nil, i18n.Args{i18n.CountArg: maxRows})
+	}
+	if row.err == nil && row.record.ID != 0 && row.record.Version == 0 {
+		row.err = utils.ErrBadRequest("import.missing_version")
+	}
+	return append(rows, row), nil
+}
+
+// parseCSVImport 解析带表头的 CSV，必须包含 username 和 email 列
+func parseCSVImport(r io.Reader, maxRows int) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, utils.ErrBadRequest("import.csv_header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"username", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, utils.ErrBadRequest("import.csv_missing_column", i18n.Args{"Column": required})
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var row importRow
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			row.line = parseErr.Line
+			row.err = utils.ErrBadRequest("import.csv_malformed")
+		case err != nil:
+			return nil, utils.ErrBadRequest("import.csv_read")
+		default:
+			row.line, _ = reader.FieldPos(0)
+			row.record, row.err = csvRecord(columns, record)
+		}
+		if rows, err = appendImportRow(rows, row, maxRows); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func csvRecord(columns map[string]int, record []string) (importRecord, error) {
+	present := []string{}
+	for name, i := range columns {
+		if i < len(record) {
+			present = append(present, name)
+		}
+	}
+	get := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(utils.UnescapeCSVCell(record[i]))
+		}
+		return ""
+	}
+	parseUint := func(name string) (uint, error) {
+		value := get(name)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, utils.ErrInvalidParam(name)
+		}
+		return uint(n), nil
+	}
+
+	id, err := parseUint("id")
+	if err != nil {
+		return importRecord{}, err
+	}
+	version, err := parseUint("version")
+	if err != nil {
+		return importRecord{}, err
+	}
+	return importRecord{ID: id, Username: get("username"), Email: get("email"), Name: get("name"), Locale: get("locale"),
+		Version: version, columns: present}, nil
+}
+
+// maxImportLineSize JSONL 单行的最大长度
+const maxImportLineSize = 1 << 20
+
+// parseJSONLImport 解析 JSON Lines，空行会被跳过
+func parseJSONLImport(r io.Reader, maxRows int) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+
+	var rows []importRow
+	var err error
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := importRow{line: line}
+		if record, err := jsonlRecord([]byte(text)); err != nil {
+			row.err = utils.ErrBadRequest("import.json_malformed")
+		} else {
+			row.record = record
+		}
+		if rows, err = appendImportRow(rows, row, maxRows); err != nil {
+			return nil, err
+		}
+	}
+	if scanner.Err() != nil {
+		return nil, utils.ErrBadRequest("import.jsonl_read")
+	}
+	return rows, nil
+}
+
+// jsonlRecord 解析一行 JSON 对象，并记录其中出现的列
+func jsonlRecord(data []byte) (importRecord, error) {
+	var record importRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return importRecord{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return importRecord{}, err
+	}
+	record.columns = make([]string, 0, len(fields))
+	for name := range fields {
+		record.columns = append(record.columns, strings.ToLower(name))
+	}
+	return record, nil
+}
+
+// importFailed 原子模式下的失败响应，data 为逐行结果
+func importFailed(result *ImportResult) error {
+	result.count()
+	appErr := utils.NewAppError(http.StatusUnprocessableEntity, "import.failed", nil)
+	appErr.Details = result
+	return appErr
+}
+
+// Localize 实现 utils.Localizable
+func (r *ImportResult) Localize(localizer *i18n.Localizer) {
+	for i := range r.Lines {
+		r.Lines[i].localize(localizer)
+	}
+}
+
+func (r *ImportResult) count() {
+	r.Succeeded, r.Failed = 0, 0
+	for _, line := range r.Lines {
+		if line.Success {
+			r.Succeeded++
+		} else {
+			r.Failed++
+		}
+	}
+}
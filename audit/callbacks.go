@@ -0,0 +1,326 @@
+package audit
+
+import (
+	"bytes"
+	"echo-template/app/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Auditable 实现该接口并返回 true 的模型会记录审计日志
+// 字段使用 `audit:"-"` 标签排除（例如密码）
+type Auditable interface {
+	Audited() bool
+}
+
+// maxAuditRows 单条语句最多记录的行数，避免批量操作时把整张表读入内存
+const maxAuditRows = 1000
+
+const beforeRowsKey = "audit:before_rows"
+
+// Register 注册审计回调
+// 创建、更新、删除都在同一事务内写入审计日志，写入失败时整个操作回滚
+func Register(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", captureBefore); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", captureBefore); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+func auditable(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return false
+	}
+	model, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Auditable)
+	return ok && model.Audited()
+}
+
+func afterCreate(db *gorm.DB) {
+	if !auditable(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	var logs []models.AuditLog
+	eachStruct(db.Statement.ReflectValue, func(value reflect.Value) {
+		row := structValues(db, value)
+		logs = append(logs, newLog(db, models.AuditActionCreate, row, nil, row))
+	})
+	writeLogs(db, logs)
+}
+
+// captureBefore 在更新/删除前按相同条件读取受影响的行
+func captureBefore(db *gorm.DB) {
+	if !auditable(db) {
+		return
+	}
+
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if stmt.Unscoped {
+		query = query.Unscoped()
+	}
+	hasConditions := false
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if expr, ok := where.Expression.(clause.Where); ok && len(expr.Exprs) > 0 {
+			query = query.Clauses(expr)
+			hasConditions = true
+		}
+	}
+
+	// 通过 Model(&user) 指定的主键在 GORM 内部回调中才会加入条件，这里手动补上
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		for _, field := range stmt.Schema.PrimaryFields {
+			if value, zero := field.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+				query = query.Where(clause.Eq{Column: clause.Column{Table: stmt.Table, Name: field.DBName}, Value: value})
+				hasConditions = true
+			}
+		}
+	}
+	// 没有条件的语句会被 GORM 拒绝（ErrMissingWhereClause），无需记录
+	if !hasConditions {
+		return
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Limit(maxAuditRows + 1).Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: load rows before change: %w", err))
+		return
+	}
+	if len(rows) > maxAuditRows {
+		log.Printf("audit: %s statement affects more than %d rows, only the first %d are recorded",
+			stmt.Table, maxAuditRows, maxAuditRows)
+		rows = rows[:maxAuditRows]
+	}
+	db.InstanceSet(beforeRowsKey, rows)
+}
+
+func afterUpdate(db *gorm.DB) {
+	before := beforeRows(db)
+	if len(before) == 0 || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	after := make(map[string]map[string]interface{})
+	var rows []map[string]interface{}
+	err := db.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Model(reflect.New(db.Statement.Schema.ModelType).Interface()).
+		Where(primaryKeyCondition(db.Statement.Schema, before)).
+		Find(&rows).Error
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: load rows after update: %w", err))
+		return
+	}
+	for _, row := range rows {
+		after[recordID(db.Statement.Schema, row)] = row
+	}
+
+	var logs []models.AuditLog
+	for _, old := range before {
+		current, ok := after[recordID(db.Statement.Schema, old)]
+		if !ok {
+			continue
+		}
+		action := models.AuditActionUpdate
+		if old["deleted_at"] != nil && current["deleted_at"] == nil {
+			action = models.AuditActionRestore
+		}
+		if changes := diff(db, old, current); len(changes) > 0 {
+			logs = append(logs, newLogFromChanges(db, action, old, changes))
+		}
+	}
+	writeLogs(db, logs)
+}
+
+func afterDelete(db *gorm.DB) {
+	before := beforeRows(db)
+	if len(before) == 0 || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	action := models.AuditActionForceDelete
+	if db.Statement.Schema.LookUpField("DeletedAt") != nil && !db.Statement.Unscoped {
+		action = models.AuditActionDelete
+	}
+
+	logs := make([]models.AuditLog, 0, len(before))
+	for _, row := range before {
+		logs = append(logs, newLog(db, action, row, row, nil))
+	}
+	writeLogs(db, logs)
+}
+
+func beforeRows(db *gorm.DB) []map[string]interface{} {
+	if db.Error != nil {
+		return nil
+	}
+	value, ok := db.InstanceGet(beforeRowsKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]interface{})
+	return rows
+}
+
+// newLog 创建日志，old/current 为整行数据（创建时 old 为 nil，删除时 current 为 nil）
+func newLog(db *gorm.DB, action string, row, old, current map[string]interface{}) models.AuditLog {
+	changes := make(map[string]change)
+	for column := range row {
+		if excluded(db.Statement.Schema, column) {
+			continue
+		}
+		c := change{}
+		if old != nil {
+			c.Old = old[column]
+		}
+		if current != nil {
+			c.New = current[column]
+		}
+		changes[column] = c
+	}
+	return newLogFromChanges(db, action, row, changes)
+}
+
+func newLogFromChanges(db *gorm.DB, action string, row map[string]interface{}, changes map[string]change) models.AuditLog {
+	actor := ActorFromContext(db.Statement.Context)
+	entry := models.AuditLog{
+		ActorType: actor.Type,
+		RequestID: actor.RequestID,
+		IP:        actor.IP,
+		Action:    action,
+		Table:     db.Statement.Table,
+		RecordID:  recordID(db.Statement.Schema, row),
+	}
+	if actor.UserID != 0 {
+		entry.ActorID = &actor.UserID
+	}
+	if actor.APIKeyID != 0 {
+		entry.APIKeyID = &actor.APIKeyID
+	}
+
+	if data, err := json.Marshal(changes); err == nil {
+		entry.Changes = models.JSONText(data)
+	} else {
+		db.AddError(fmt.Errorf("audit: marshal changes: %w", err))
+	}
+	return entry
+}
+
+// change 单个字段的新旧值
+type change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// diff 比较新旧两行，仅返回发生变化的字段
+func diff(db *gorm.DB, old, current map[string]interface{}) map[string]change {
+	changes := make(map[string]change)
+	for column, newValue := range current {
+		if excluded(db.Statement.Schema, column) {
+			continue
+		}
+		oldValue := old[column]
+		oldJSON, _ := json.Marshal(oldValue)
+		newJSON, _ := json.Marshal(newValue)
+		if !bytes.Equal(oldJSON, newJSON) {
+			changes[column] = change{Old: oldValue, New: newValue}
+		}
+	}
+	return changes
+}
+
+func excluded(s *schema.Schema, column string) bool {
+	field := s.LookUpField(column)
+	return field != nil && field.Tag.Get("audit") == "-"
+}
+
+// structValues 将模型结构体转换为 列名 -> 值
+func structValues(db *gorm.DB, value reflect.Value) map[string]interface{} {
+	row := make(map[string]interface{})
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		row[field.DBName], _ = field.ValueOf(db.Statement.Context, value)
+	}
+	return row
+}
+
+func eachStruct(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			eachStruct(value.Index(i), fn)
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+// recordID 主键值，复合主键以逗号连接
+func recordID(s *schema.Schema, row map[string]interface{}) string {
+	parts := make([]string, 0, len(s.PrimaryFieldDBNames))
+	for _, name := range s.PrimaryFieldDBNames {
+		parts = append(parts, fmt.Sprint(row[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// primaryKeyCondition 按主键匹配给定的行
+func primaryKeyCondition(s *schema.Schema, rows []map[string]interface{}) clause.Expression {
+	columns := make([]clause.Column, len(s.PrimaryFieldDBNames))
+	for i, name := range s.PrimaryFieldDBNames {
+		columns[i] = clause.Column{Name: name}
+	}
+
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(s.PrimaryFieldDBNames))
+		for j, name := range s.PrimaryFieldDBNames {
+			values[i][j] = row[name]
+		}
+	}
+
+	if len(columns) == 1 {
+		flat := make([]interface{}, len(values))
+		for i, v := range values {
+			flat[i] = v[0]
+		}
+		return clause.IN{Column: columns[0], Values: flat}
+	}
+	return clause.IN{Column: columns, Values: toInterfaces(values)}
+}
+
+func toInterfaces(values [][]interface{}) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+func writeLogs(db *gorm.DB, logs []models.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: write logs: %w", err))
+	}
+}
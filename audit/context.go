@@ -0,0 +1,38 @@
+package audit
+
+import "context"
+
+// 操作人类型
+const (
+	ActorSystem    = "system"    // 后台任务等非请求触发的操作
+	ActorAnonymous = "anonymous" // 未认证的请求
+	ActorUser      = "user"
+	ActorService   = "service_account" // 使用服务账号的 API Key
+)
+
+// Actor 审计日志中的操作人信息
+type Actor struct {
+	UserID    uint
+	APIKeyID  uint // 使用 API Key 认证时的密钥ID
+	Type      string
+	RequestID string
+	IP        string
+}
+
+type actorKey struct{}
+
+// WithActorFunc 在上下文中记录操作人的获取方式
+// 使用函数而不是直接存值：认证中间件可能在之后才写入用户信息，写库时再解析
+func WithActorFunc(ctx context.Context, fn func() Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, fn)
+}
+
+// ActorFromContext 获取上下文中的操作人，没有请求上下文时视为系统操作
+func ActorFromContext(ctx context.Context) Actor {
+	if ctx != nil {
+		if fn, ok := ctx.Value(actorKey{}).(func() Actor); ok {
+			return fn()
+		}
+	}
+	return Actor{Type: ActorSystem}
+}
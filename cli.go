@@ -0,0 +1,180 @@
+package main
+
+import (
+	"echo-template/app/models"
+	"echo-template/audit"
+	"echo-template/config"
+	"echo-template/database"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v2"
+)
+
+// 版本信息，构建时通过 -ldflags "-X main.version=v1.2.3 -X main.commit=... -X main.buildDate=..." 注入
+var (
+	version   = "dev"
+	commit    = "none"
+	buildDate = "unknown"
+)
+
+// configFlags 全局参数与配置环境变量的对应关系，命令行参数优先于环境变量和 .env 文件
+var configFlags = []struct {
+	name  string
+	env   string
+	usage string
+}{
+	{"env", "APP_ENV", "运行环境：development | production"},
+	{"host", "SERVER_HOST", "监听地址"},
+	{"port", "SERVER_PORT", "监听端口"},
+	{"db-type", "DB_TYPE", "数据库类型：postgres | mysql | sqlite"},
+	{"db-host", "DB_HOST", "数据库地址"},
+	{"db-port", "DB_PORT", "数据库端口"},
+	{"db-user", "DB_USER", "数据库用户"},
+	{"db-password", "DB_PASSWORD", "数据库密码"},
+	{"db-name", "DB_NAME", "数据库名（SQLite 为不含 .db 的文件路径）"},
+}
+
+func newApp() *cli.App {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "env-file", Usage: "额外加载的环境变量文件，优先于 .env"},
+	}
+	for _, f := range configFlags {
+		flags = append(flags, &cli.StringFlag{Name: f.name, Usage: f.usage + "（" + f.env + "）"})
+	}
+
+	return &cli.App{
+		Name:           "echo-template",
+		Usage:          "基于 Echo 框架的 MVC 脚手架",
+		Version:        version,
+		HideVersion:    true,
+		Flags:          flags,
+		Before:         loadConfig,
+		DefaultCommand: "serve",
+		Commands: []*cli.Command{
+			serveCommand,
+			migrateCommand,
+			seedCommand,
+			routesCommand,
+			createAdminCommand,
+			apiKeyCommand,
+			configCommand,
+			generateCommand,
+			openAPICommand,
+			versionCommand,
+		},
+	}
+}
+
+// loadConfig 将全局参数写入对应的环境变量后加载配置，派生的默认值（例如生产环境的安全配置）随之生效
+func loadConfig(c *cli.Context) error {
+	if file := c.String("env-file"); file != "" {
+		if err := godotenv.Load(file); err != nil {
+			return fmt.Errorf("failed to load env file %s: %w", file, err)
+		}
+	}
+	for _, f := range configFlags {
+		if c.IsSet(f.name) {
+			if err := os.Setenv(f.env, c.String(f.name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := config.LoadConfig(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return nil
+}
+
+// setupDatabase 连接数据库并注册审计回调，migrate 为 true 时自动迁移表结构
+func setupDatabase(migrate bool) error {
+	if err := database.InitDB(); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	db := database.GetDB()
+	if err := audit.Register(db); err != nil {
+		return fmt.Errorf("failed to register audit callbacks: %w", err)
+	}
+
+	if migrate {
+		if err := database.Migrate(db, models.All()...); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+	return nil
+}
+
+var migrateCommand = &cli.Command{
+	Name:  "migrate",
+	Usage: "迁移数据库表结构",
+	Action: func(c *cli.Context) error {
+		if err := setupDatabase(true); err != nil {
+			return err
+		}
+		fmt.Println("database migrated")
+		return nil
+	},
+}
+
+var routesCommand = &cli.Command{
+	Name:  "routes",
+	Usage: "列出注册的路由及其处理函数和中间件",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "format", Value: "text", Usage: "输出格式：text | json"},
+	},
+	Action: func(c *cli.Context) error {
+		_, table := newServer()
+
+		switch c.String("format") {
+		case "json":
+			encoder := json.NewEncoder(c.App.Writer)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(map[string]interface{}{
+				"global": table.Global,
+				"routes": table.Routes(),
+			})
+		case "text":
+		default:
+			return fmt.Errorf("unsupported format %q", c.String("format"))
+		}
+
+		fmt.Fprintf(c.App.Writer, "Global middleware: %s\n\n", strings.Join(table.Global, ", "))
+		w := tabwriter.NewWriter(c.App.Writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARE")
+		for _, route := range table.Routes() {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler, strings.Join(route.Middleware, ", "))
+		}
+		return w.Flush()
+	},
+}
+
+var configCommand = &cli.Command{
+	Name:  "config",
+	Usage: "配置相关命令",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "print",
+			Usage: "输出生效的配置（密码已隐藏）",
+			Action: func(c *cli.Context) error {
+				return config.AppConfig.Print(c.App.Writer)
+			},
+		},
+	},
+}
+
+var versionCommand = &cli.Command{
+	Name:  "version",
+	Usage: "输出版本信息",
+	Action: func(c *cli.Context) error {
+		fmt.Fprintf(c.App.Writer, "%s %s (commit %s, built %s, %s)\n",
+			c.App.Name, version, commit, buildDate, runtime.Version())
+		return nil
+	},
+}
@@ -0,0 +1,409 @@
+// Package client 是本服务的 Go 客户端
+//
+// client_gen.go 中的类型和接口方法由 `go run . generate client` 根据 OpenAPI 文档生成，不要手工修改；
+// 本文件提供请求、认证、重试和响应解析
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderIdempotencyKey 幂等键请求头，携带幂等键的 POST 请求可以安全重试
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// Client API 客户端，可被多个 goroutine 同时使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+	userAgent  string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// New 创建客户端，baseURL 包含 API 前缀，例如 http://localhost:1323/api
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		userAgent:  "echo-template-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient 使用自定义的 http.Client，例如设置超时或传输层
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAuth 为每个请求添加认证信息
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetry 设置重试策略，NoRetry 关闭重试
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent 设置 User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// Authenticator 为请求添加认证信息，每次尝试（包括重试）都会调用
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc 函数形式的 Authenticator，可用于需要刷新的令牌
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken Authorization: Bearer 认证
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKeyAuth 通过 X-API-Key 传递 API Key，也可以使用 BearerToken(key)
+func APIKeyAuth(key string) Authenticator {
+	return HeaderAuth("X-API-Key", key)
+}
+
+// BasicAuth HTTP Basic 认证
+func BasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// HeaderAuth 通过自定义请求头认证，例如 HeaderAuth("X-API-Key", key)
+func HeaderAuth(name, value string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// RetryPolicy 重试策略
+// 只重试幂等请求（GET、HEAD、PUT、DELETE、OPTIONS 以及携带幂等键的请求），
+// 重试的情况为网络错误和 429、502、503、504 响应；等待时间按指数退避加随机抖动，响应带 Retry-After 时以其为准
+type RetryPolicy struct {
+	MaxAttempts int           // 最多尝试次数（包括第一次），小于等于 1 时不重试
+	MinBackoff  time.Duration // 第一次重试前的等待时间
+	MaxBackoff  time.Duration // 单次等待时间上限
+}
+
+var (
+	// DefaultRetryPolicy 默认重试策略
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+	// NoRetry 不重试
+	NoRetry = RetryPolicy{MaxAttempts: 1}
+)
+
+// backoff 第 attempt 次重试前的等待时间
+func (p RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, p.MaxBackoff)
+		}
+	}
+	wait := p.MinBackoff << (attempt - 1)
+	if wait <= 0 || wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	// 在 [wait/2, wait] 之间随机，避免多个客户端同时重试
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// RequestOption 单个请求的选项
+type RequestOption func(*request)
+
+// WithHeader 设置请求头
+func WithHeader(name, value string) RequestOption {
+	return func(r *request) {
+		r.header.Set(name, value)
+	}
+}
+
+// WithIdempotencyKey 设置幂等键，服务端对相同幂等键的重复请求回放第一次的响应，POST 请求因此可以重试
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader(HeaderIdempotencyKey, key)
+}
+
+// WithResponseInfo 请求完成后将状态码和响应头写入 info，例如读取 ETag 用于之后的 If-Match
+func WithResponseInfo(info *ResponseInfo) RequestOption {
+	return func(r *request) {
+		r.info = info
+	}
+}
+
+// ResponseInfo 响应的状态码和响应头
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items    []T   `json:"items"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// Upload 上传的文件
+type Upload struct {
+	Name    string // 文件名，服务端可能根据扩展名判断格式
+	Content io.Reader
+}
+
+// File 下载的文件，调用方负责关闭 Body
+type File struct {
+	Body        io.ReadCloser
+	ContentType string
+	Filename    string
+}
+
+// request 待发送的请求，请求体保存为字节以便重试
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+	info        *ResponseInfo
+}
+
+func newRequest(method, path string) *request {
+	return &request{method: method, path: path, query: url.Values{}, header: http.Header{}}
+}
+
+// setJSON 设置 JSON 请求体
+func (r *request) setJSON(body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request body: %w", err)
+	}
+	r.body = data
+	r.contentType = "application/json"
+	return nil
+}
+
+// formPart multipart 表单的一项，file 不为 nil 时为文件
+type formPart struct {
+	name  string
+	value string
+	file  *Upload
+}
+
+// setMultipart 设置 multipart/form-data 请求体，空值的普通字段不发送
+func (r *request) setMultipart(parts []formPart) error {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, part := range parts {
+		if part.file == nil {
+			if part.value == "" {
+				continue
+			}
+			if err := writer.WriteField(part.name, part.value); err != nil {
+				return err
+			}
+			continue
+		}
+		if part.file.Content == nil {
+			continue
+		}
+		w, err := writer.CreateFormFile(part.name, part.file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, part.file.Content); err != nil {
+			return fmt.Errorf("read upload %s: %w", part.file.Name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	r.body = buf.Bytes()
+	r.contentType = writer.FormDataContentType()
+	return nil
+}
+
+// retryable 请求是否可以安全重试
+func (r *request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return r.header.Get(HeaderIdempotencyKey) != ""
+}
+
+// send 发送请求，按重试策略重试；返回的响应状态码可能不是 2xx
+func (c *Client) send(ctx context.Context, r *request, opts []RequestOption) (*http.Response, error) {
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	target := c.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	attempts := 1
+	if r.retryable() && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, r.method, target, bytes.NewReader(r.body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range r.header {
+			req.Header[name] = values
+		}
+		if req.Header.Get("Accept") == "" {
+			req.Header.Set("Accept", "application/json")
+		}
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+		if c.userAgent != "" {
+			req.Header.Set("User-Agent", c.userAgent)
+		}
+		if c.auth != nil {
+			if err := c.auth.Authenticate(req); err != nil {
+				return nil, fmt.Errorf("authenticate: %w", err)
+			}
+		}
+
+		res, err := c.httpClient.Do(req)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= attempts || (err == nil && !retryableStatus(res.StatusCode)) {
+			if err != nil {
+				return nil, err
+			}
+			if r.info != nil {
+				*r.info = ResponseInfo{StatusCode: res.StatusCode, Header: res.Header}
+			}
+			return res, nil
+		}
+
+		wait := c.retry.backoff(attempt, res)
+		if res != nil {
+			// 读完响应体以复用连接
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// envelope 统一响应格式
+type envelope struct {
+	Code int             `json:"code"`
+	Data json.RawMessage `json:"data"`
+	Msg  string          `json:"msg"`
+}
+
+// do 发送请求并将响应的 data 解析到 result，result 为 nil 时忽略 data
+func (c *Client) do(ctx context.Context, r *request, result interface{}, opts []RequestOption) error {
+	res, err := c.send(ctx, r, opts)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return newError(res, body)
+	}
+	if result == nil {
+		return nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, result); err != nil {
+		return fmt.Errorf("decode response data: %w", err)
+	}
+	return nil
+}
+
+// download 发送请求并返回响应体，不读取内容
+func (c *Client) download(ctx context.Context, r *request, opts []RequestOption) (*File, error) {
+	r.header.Set("Accept", "*/*")
+	res, err := c.send(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, newError(res, body)
+	}
+
+	file := &File{Body: res.Body, ContentType: res.Header.Get("Content-Type")}
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		file.Filename = params["filename"]
+	}
+	return file, nil
+}
@@ -0,0 +1,771 @@
+// Code generated by "go run . generate client"; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// APIKey API Key，只保存可见前缀和密钥的哈希，完整密钥只在创建时返回一次
+type APIKey struct {
+	CreatedAt      time.Time  `json:"created_at,omitempty"`      // 创建时间
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`      // 删除时间（未删除时为 null）
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`      // 过期时间，null 为不过期
+	ID             int64      `json:"id,omitempty"`              // ID
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`    // 最近使用时间
+	LastUsedIP     string     `json:"last_used_ip,omitempty"`    // 最近使用的客户端 IP
+	Name           string     `json:"name,omitempty"`            // 名称
+	Prefix         string     `json:"prefix,omitempty"`          // 可见前缀，用于识别密钥
+	Role           string     `json:"role,omitempty"`            // 服务账号的角色：user | admin，用户的密钥使用用户的角色
+	Scopes         []string   `json:"scopes,omitempty"`          // 权限范围
+	ServiceAccount string     `json:"service_account,omitempty"` // 所属服务账号，用户的密钥为空
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`      // 更新时间
+	UserID         *int64     `json:"user_id,omitempty"`         // 所属用户ID，服务账号的密钥为 null
+	Version        int64      `json:"version,omitempty"`         // 版本号（乐观锁，更新时必须携带）
+}
+
+// AuditLog 审计日志，记录每次数据变更的操作人和新旧值
+type AuditLog struct {
+	Action    string                 `json:"action,omitempty"`     // 动作：create | update | delete | restore | force_delete
+	ActorID   *int64                 `json:"actor_id,omitempty"`   // 操作人用户ID，非用户操作时为 null
+	ActorType string                 `json:"actor_type,omitempty"` // 操作人类型：user | service_account | anonymous | system
+	APIKeyID  *int64                 `json:"api_key_id,omitempty"` // 使用的 API Key ID，未使用 API Key 时为 null
+	Changes   map[string]interface{} `json:"changes,omitempty"`    // 变更内容：{"字段": {"old": 旧值, "new": 新值}}
+	CreatedAt time.Time              `json:"created_at,omitempty"` // 操作时间
+	ID        int64                  `json:"id,omitempty"`         // 日志ID
+	IP        string                 `json:"ip,omitempty"`         // 客户端 IP
+	RecordID  string                 `json:"record_id,omitempty"`  // 主键
+	RequestID string                 `json:"request_id,omitempty"` // 请求ID
+	Table     string                 `json:"table,omitempty"`      // 表名
+}
+
+// BatchItemResult
+type BatchItemResult struct {
+	Data    *User   `json:"data,omitempty"`    // 操作后的用户信息
+	Error   string  `json:"error,omitempty"`   // 失败原因
+	ID      int64   `json:"id,omitempty"`      // 用户ID
+	Index   int64   `json:"index,omitempty"`   // 在请求中的位置
+	Op      BatchOp `json:"op,omitempty"`      // 操作类型（无法解析的导入行为空）
+	Status  int64   `json:"status,omitempty"`  // 与单个接口一致的 HTTP 状态码
+	Success bool    `json:"success,omitempty"` // 是否成功
+}
+
+// BatchOp
+type BatchOp string
+
+const (
+	BatchOpCreate BatchOp = "create"
+	BatchOpUpdate BatchOp = "update"
+	BatchOpDelete BatchOp = "delete"
+)
+
+// BatchResult
+type BatchResult struct {
+	Atomic    bool              `json:"atomic,omitempty"`    // 是否为原子模式
+	Failed    int64             `json:"failed,omitempty"`    // 失败数
+	Items     []BatchItemResult `json:"items,omitempty"`     // 逐项结果，顺序与请求一致
+	Succeeded int64             `json:"succeeded,omitempty"` // 成功数
+}
+
+// BatchUserOperation
+type BatchUserOperation struct {
+	ID   int64   `json:"id,omitempty"`   // 用户ID（update/delete）
+	Op   BatchOp `json:"op,omitempty"`   // 操作类型
+	User *User   `json:"user,omitempty"` // 用户信息（create/update）
+}
+
+// BatchUsersRequest
+type BatchUsersRequest struct {
+	Mode       string               `json:"mode,omitempty"`       // atomic：任一项失败全部回滚（默认）；partial：允许部分成功
+	Operations []BatchUserOperation `json:"operations,omitempty"` // 按顺序执行的操作
+}
+
+// CreateAPIKeyRequest 创建 API Key 请求；未指定所有者时属于调用方自己，只有管理员可以为其他用户或服务账号创建
+type CreateAPIKeyRequest struct {
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`      // 过期时间，未指定时按 API_KEY_DEFAULT_TTL 设置
+	Name           string     `json:"name"`                      // 名称
+	Role           string     `json:"role,omitempty"`            // 服务账号的角色，默认 user
+	Scopes         []string   `json:"scopes"`                    // 权限范围：<资源>:read、<资源>:write 或 *
+	ServiceAccount string     `json:"service_account,omitempty"` // 所属服务账号，与 user_id 二选一
+	UserID         *int64     `json:"user_id,omitempty"`         // 所属用户ID
+}
+
+// CreateUserRequest v2 创建用户请求
+type CreateUserRequest struct {
+	DisplayName string `json:"display_name,omitempty"` // 显示名称
+	Email       string `json:"email"`                  // 邮箱（未删除用户中唯一）
+	Locale      string `json:"locale,omitempty"`       // 偏好的语言
+	Username    string `json:"username"`               // 用户名（未删除用户中唯一）
+}
+
+// CreatedAPIKey 新创建的 API Key，key 为完整密钥，只在创建时返回这一次
+type CreatedAPIKey struct {
+	CreatedAt      time.Time  `json:"created_at,omitempty"`      // 创建时间
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`      // 删除时间（未删除时为 null）
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`      // 过期时间，null 为不过期
+	ID             int64      `json:"id,omitempty"`              // ID
+	Key            string     `json:"key,omitempty"`             // 完整密钥
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`    // 最近使用时间
+	LastUsedIP     string     `json:"last_used_ip,omitempty"`    // 最近使用的客户端 IP
+	Name           string     `json:"name,omitempty"`            // 名称
+	Prefix         string     `json:"prefix,omitempty"`          // 可见前缀，用于识别密钥
+	Role           string     `json:"role,omitempty"`            // 服务账号的角色：user | admin，用户的密钥使用用户的角色
+	Scopes         []string   `json:"scopes,omitempty"`          // 权限范围
+	ServiceAccount string     `json:"service_account,omitempty"` // 所属服务账号，用户的密钥为空
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`      // 更新时间
+	UserID         *int64     `json:"user_id,omitempty"`         // 所属用户ID，服务账号的密钥为 null
+	Version        int64      `json:"version,omitempty"`         // 版本号（乐观锁，更新时必须携带）
+}
+
+// ImportLineResult
+type ImportLineResult struct {
+	Data    *User   `json:"data,omitempty"`  // 操作后的用户信息
+	Error   string  `json:"error,omitempty"` // 失败原因
+	ID      int64   `json:"id,omitempty"`    // 用户ID
+	Index   int64   `json:"index,omitempty"` // 在请求中的位置
+	Line    int64   `json:"line,omitempty"`
+	Op      BatchOp `json:"op,omitempty"`      // 操作类型（无法解析的导入行为空）
+	Status  int64   `json:"status,omitempty"`  // 与单个接口一致的 HTTP 状态码
+	Success bool    `json:"success,omitempty"` // 是否成功
+}
+
+// ImportResult
+type ImportResult struct {
+	Atomic    bool               `json:"atomic,omitempty"`    // 是否为原子模式
+	Failed    int64              `json:"failed,omitempty"`    // 失败行数
+	Lines     []ImportLineResult `json:"lines,omitempty"`     // 逐行结果，顺序与文件一致
+	Succeeded int64              `json:"succeeded,omitempty"` // 成功行数
+}
+
+// LoginProvider 可用于登录的身份提供方，浏览器打开 login_url 开始登录
+type LoginProvider struct {
+	DisplayName string `json:"display_name,omitempty"` // 展示名称
+	LoginURL    string `json:"login_url,omitempty"`    // 登录地址
+	Name        string `json:"name,omitempty"`         // 名称
+}
+
+// LoginResult
+type LoginResult struct {
+	AccessToken string    `json:"access_token,omitempty"` // 访问令牌，通过 Authorization: Bearer 传递
+	Created     bool      `json:"created,omitempty"`      // 是否为首次登录时自动创建的用户
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`   // 过期时间
+	ExpiresIn   int64     `json:"expires_in,omitempty"`   // 有效期（秒）
+	TokenType   string    `json:"token_type,omitempty"`   // 令牌类型
+	User        *User     `json:"user,omitempty"`         // 登录的用户
+}
+
+// OidcLink 在发起关联的浏览器中打开 auth_url，身份提供方登录后回调完成关联
+type OidcLink struct {
+	AuthURL string `json:"auth_url,omitempty"` // 身份提供方的授权地址
+}
+
+// UpdateAPIKeyRequest 更新 API Key 请求，所有者、角色和密钥本身不能修改
+type UpdateAPIKeyRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 过期时间，null 为不过期
+	Name      string     `json:"name"`                 // 名称
+	Scopes    []string   `json:"scopes"`               // 权限范围
+	Version   int64      `json:"version"`              // 获取密钥时返回的版本号
+}
+
+// UpdateUserRequest v2 更新用户请求，未携带的字段会被清空
+type UpdateUserRequest struct {
+	DisplayName string `json:"display_name,omitempty"` // 显示名称
+	Email       string `json:"email"`                  // 邮箱（未删除用户中唯一）
+	Locale      string `json:"locale,omitempty"`       // 偏好的语言
+	Username    string `json:"username"`               // 用户名（未删除用户中唯一）
+	Version     int64  `json:"version"`                // 获取用户时返回的版本号
+}
+
+// User 用户信息
+type User struct {
+	CreatedAt time.Time  `json:"created_at,omitempty"` // 创建时间
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // 删除时间（未删除时为 null）
+	Email     string     `json:"email"`                // 邮箱（未删除用户中唯一）
+	ID        int64      `json:"id,omitempty"`         // ID
+	Locale    string     `json:"locale,omitempty"`     // 偏好的语言，认证后用于响应消息
+	Name      string     `json:"name,omitempty"`       // 姓名
+	Role      string     `json:"role,omitempty"`       // 角色：user | admin（不可通过接口修改）
+	UpdatedAt time.Time  `json:"updated_at,omitempty"` // 更新时间
+	Username  string     `json:"username"`             // 用户名（未删除用户中唯一）
+	Version   int64      `json:"version,omitempty"`    // 版本号（乐观锁，更新时必须携带）
+}
+
+// UserResponse v2 用户信息
+type UserResponse struct {
+	CreatedAt   time.Time  `json:"created_at,omitempty"`   // 创建时间
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`   // 删除时间（仅已删除用户返回）
+	DisplayName string     `json:"display_name,omitempty"` // 显示名称
+	Email       string     `json:"email,omitempty"`        // 邮箱
+	ID          int64      `json:"id,omitempty"`           // ID
+	Locale      string     `json:"locale,omitempty"`       // 偏好的语言
+	Role        string     `json:"role,omitempty"`         // 角色：user | admin
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`   // 更新时间
+	Username    string     `json:"username,omitempty"`     // 用户名
+	Version     int64      `json:"version,omitempty"`      // 版本号（乐观锁，更新时必须携带）
+}
+
+// UserSearchResult
+type UserSearchResult struct {
+	Highlights map[string]string `json:"highlights,omitempty"` // 命中的字段，匹配部分以 <mark> 标记
+	Rank       float64           `json:"rank,omitempty"`       // 相关度，越大越相关
+	User       *User             `json:"user,omitempty"`
+}
+
+// ListAPIKeysParams 查询参数和请求头，零值不发送
+type ListAPIKeysParams struct {
+	Page     int64 // 页码
+	PageSize int64 // 每页数量
+}
+
+// ListAPIKeys 获取 API Key 列表
+//
+// GET /v1/api-keys
+func (c *Client) ListAPIKeys(ctx context.Context, params *ListAPIKeysParams, opts ...RequestOption) (*Page[APIKey], error) {
+	var result Page[APIKey]
+	req := newRequest(http.MethodGet, "/v1/api-keys")
+	if params != nil {
+		if params.Page != 0 {
+			req.query.Set("page", strconv.FormatInt(params.Page, 10))
+		}
+		if params.PageSize != 0 {
+			req.query.Set("page_size", strconv.FormatInt(params.PageSize, 10))
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAPIKeyParams 查询参数和请求头，零值不发送
+type CreateAPIKeyParams struct {
+	IdempotencyKey string // 幂等键，重试时携带相同的值不会重复创建
+}
+
+// CreateAPIKey 创建 API Key
+//
+// POST /v1/api-keys
+func (c *Client) CreateAPIKey(ctx context.Context, body CreateAPIKeyRequest, params *CreateAPIKeyParams, opts ...RequestOption) (*CreatedAPIKey, error) {
+	var result CreatedAPIKey
+	req := newRequest(http.MethodPost, "/v1/api-keys")
+	if params != nil {
+		if params.IdempotencyKey != "" {
+			req.header.Set("Idempotency-Key", params.IdempotencyKey)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAPIKey 获取单个 API Key
+//
+// GET /v1/api-keys/{id}
+func (c *Client) GetAPIKey(ctx context.Context, id int64, opts ...RequestOption) (*APIKey, error) {
+	var result APIKey
+	req := newRequest(http.MethodGet, "/v1/api-keys/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateAPIKey 更新 API Key
+//
+// PUT /v1/api-keys/{id}
+func (c *Client) UpdateAPIKey(ctx context.Context, id int64, body UpdateAPIKeyRequest, opts ...RequestOption) (*APIKey, error) {
+	var result APIKey
+	req := newRequest(http.MethodPut, "/v1/api-keys/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAPIKey 吊销 API Key
+//
+// DELETE /v1/api-keys/{id}
+func (c *Client) DeleteAPIKey(ctx context.Context, id int64, opts ...RequestOption) error {
+	req := newRequest(http.MethodDelete, "/v1/api-keys/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	return c.do(ctx, req, nil, opts)
+}
+
+// ListAuditLogsParams 查询参数和请求头，零值不发送
+type ListAuditLogsParams struct {
+	ActorID   int64  // 操作人用户ID
+	APIKeyID  int64  // 使用的 API Key ID
+	Action    string // 动作
+	Table     string // 表名
+	RecordID  string // 记录主键
+	RequestID string // 请求ID
+	From      string // 起始时间（RFC3339，包含）
+	To        string // 结束时间（RFC3339，不包含）
+	Page      int64  // 页码
+	PageSize  int64  // 每页数量
+}
+
+// ListAuditLogs 查询审计日志
+//
+// GET /v1/audit-logs
+func (c *Client) ListAuditLogs(ctx context.Context, params *ListAuditLogsParams, opts ...RequestOption) (*Page[AuditLog], error) {
+	var result Page[AuditLog]
+	req := newRequest(http.MethodGet, "/v1/audit-logs")
+	if params != nil {
+		if params.ActorID != 0 {
+			req.query.Set("actor_id", strconv.FormatInt(params.ActorID, 10))
+		}
+		if params.APIKeyID != 0 {
+			req.query.Set("api_key_id", strconv.FormatInt(params.APIKeyID, 10))
+		}
+		if params.Action != "" {
+			req.query.Set("action", params.Action)
+		}
+		if params.Table != "" {
+			req.query.Set("table", params.Table)
+		}
+		if params.RecordID != "" {
+			req.query.Set("record_id", params.RecordID)
+		}
+		if params.RequestID != "" {
+			req.query.Set("request_id", params.RequestID)
+		}
+		if params.From != "" {
+			req.query.Set("from", params.From)
+		}
+		if params.To != "" {
+			req.query.Set("to", params.To)
+		}
+		if params.Page != 0 {
+			req.query.Set("page", strconv.FormatInt(params.Page, 10))
+		}
+		if params.PageSize != 0 {
+			req.query.Set("page_size", strconv.FormatInt(params.PageSize, 10))
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetCurrentUser 获取当前登录的用户
+//
+// GET /v1/auth/me
+func (c *Client) GetCurrentUser(ctx context.Context, opts ...RequestOption) (*User, error) {
+	var result User
+	req := newRequest(http.MethodGet, "/v1/auth/me")
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompleteOidcLoginParams 查询参数和请求头，零值不发送
+type CompleteOidcLoginParams struct {
+	Code  string // 授权码
+	State string // 发起登录时生成的 state
+	Error string // 身份提供方返回的错误，例如 access_denied
+}
+
+// CompleteOidcLogin 身份提供方登录回调
+//
+// GET /v1/auth/oidc/{provider}/callback
+func (c *Client) CompleteOidcLogin(ctx context.Context, provider string, params *CompleteOidcLoginParams, opts ...RequestOption) (*LoginResult, error) {
+	var result LoginResult
+	req := newRequest(http.MethodGet, "/v1/auth/oidc/"+url.PathEscape(provider)+"/callback")
+	if params != nil {
+		if params.Code != "" {
+			req.query.Set("code", params.Code)
+		}
+		if params.State != "" {
+			req.query.Set("state", params.State)
+		}
+		if params.Error != "" {
+			req.query.Set("error", params.Error)
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LinkOidcIdentity 关联身份提供方
+//
+// POST /v1/auth/oidc/{provider}/link
+func (c *Client) LinkOidcIdentity(ctx context.Context, provider string, opts ...RequestOption) (*OidcLink, error) {
+	var result OidcLink
+	req := newRequest(http.MethodPost, "/v1/auth/oidc/"+url.PathEscape(provider)+"/link")
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartOidcLogin 跳转到身份提供方登录
+//
+// GET /v1/auth/oidc/{provider}/login
+func (c *Client) StartOidcLogin(ctx context.Context, provider string, opts ...RequestOption) error {
+	req := newRequest(http.MethodGet, "/v1/auth/oidc/"+url.PathEscape(provider)+"/login")
+	return c.do(ctx, req, nil, opts)
+}
+
+// ListLoginProviders 获取身份提供方列表
+//
+// GET /v1/auth/providers
+func (c *Client) ListLoginProviders(ctx context.Context, opts ...RequestOption) ([]LoginProvider, error) {
+	var result []LoginProvider
+	req := newRequest(http.MethodGet, "/v1/auth/providers")
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListUsersParams 查询参数和请求头，零值不发送
+type ListUsersParams struct {
+	Trashed string // 已删除用户的查询范围
+}
+
+// ListUsers 获取用户列表
+//
+// GET /v1/users
+//
+// Deprecated: 该接口已弃用
+func (c *Client) ListUsers(ctx context.Context, params *ListUsersParams, opts ...RequestOption) ([]User, error) {
+	var result []User
+	req := newRequest(http.MethodGet, "/v1/users")
+	if params != nil {
+		if params.Trashed != "" {
+			req.query.Set("trashed", params.Trashed)
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateUserParams 查询参数和请求头，零值不发送
+type CreateUserParams struct {
+	IdempotencyKey string // 幂等键，重试时携带相同的值不会重复创建
+}
+
+// CreateUser 创建用户
+//
+// POST /v1/users
+func (c *Client) CreateUser(ctx context.Context, body User, params *CreateUserParams, opts ...RequestOption) (*User, error) {
+	var result User
+	req := newRequest(http.MethodPost, "/v1/users")
+	if params != nil {
+		if params.IdempotencyKey != "" {
+			req.header.Set("Idempotency-Key", params.IdempotencyKey)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BatchUsersParams 查询参数和请求头，零值不发送
+type BatchUsersParams struct {
+	IdempotencyKey string // 幂等键，重试时携带相同的值不会重复执行
+}
+
+// BatchUsers 批量操作用户
+//
+// POST /v1/users/batch
+func (c *Client) BatchUsers(ctx context.Context, body BatchUsersRequest, params *BatchUsersParams, opts ...RequestOption) (*BatchResult, error) {
+	var result BatchResult
+	req := newRequest(http.MethodPost, "/v1/users/batch")
+	if params != nil {
+		if params.IdempotencyKey != "" {
+			req.header.Set("Idempotency-Key", params.IdempotencyKey)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportUsersParams 查询参数和请求头，零值不发送
+type ExportUsersParams struct {
+	Format  string // 导出格式，默认 csv
+	Trashed string // 已删除用户的导出范围
+}
+
+// ExportUsers 导出用户
+//
+// GET /v1/users/export
+func (c *Client) ExportUsers(ctx context.Context, params *ExportUsersParams, opts ...RequestOption) (*File, error) {
+	req := newRequest(http.MethodGet, "/v1/users/export")
+	if params != nil {
+		if params.Format != "" {
+			req.query.Set("format", params.Format)
+		}
+		if params.Trashed != "" {
+			req.query.Set("trashed", params.Trashed)
+		}
+	}
+	return c.download(ctx, req, opts)
+}
+
+// ImportUsersForm multipart 表单，空字段不发送
+type ImportUsersForm struct {
+	File   Upload // CSV 或 JSONL 文件
+	Format string // 文件格式，默认根据扩展名判断
+	Mode   string // 导入模式，默认 atomic
+}
+
+// ImportUsers 导入用户
+//
+// POST /v1/users/import
+func (c *Client) ImportUsers(ctx context.Context, form ImportUsersForm, opts ...RequestOption) (*ImportResult, error) {
+	var result ImportResult
+	req := newRequest(http.MethodPost, "/v1/users/import")
+	if err := req.setMultipart([]formPart{
+		{name: "file", file: &form.File},
+		{name: "format", value: form.Format},
+		{name: "mode", value: form.Mode},
+	}); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchUsersParams 查询参数和请求头，零值不发送
+type SearchUsersParams struct {
+	Q        string // 检索词，多个词以空格分隔
+	Page     int64  // 页码
+	PageSize int64  // 每页数量
+}
+
+// SearchUsers 检索用户
+//
+// GET /v1/users/search
+func (c *Client) SearchUsers(ctx context.Context, params *SearchUsersParams, opts ...RequestOption) (*Page[UserSearchResult], error) {
+	var result Page[UserSearchResult]
+	req := newRequest(http.MethodGet, "/v1/users/search")
+	if params != nil {
+		if params.Q != "" {
+			req.query.Set("q", params.Q)
+		}
+		if params.Page != 0 {
+			req.query.Set("page", strconv.FormatInt(params.Page, 10))
+		}
+		if params.PageSize != 0 {
+			req.query.Set("page_size", strconv.FormatInt(params.PageSize, 10))
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUser 获取单个用户
+//
+// GET /v1/users/{id}
+func (c *Client) GetUser(ctx context.Context, id int64, opts ...RequestOption) (*User, error) {
+	var result User
+	req := newRequest(http.MethodGet, "/v1/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateUserParams 查询参数和请求头，零值不发送
+type UpdateUserParams struct {
+	IfMatch string // 获取用户时返回的 ETag，不匹配时拒绝更新
+}
+
+// UpdateUser 更新用户
+//
+// PUT /v1/users/{id}
+func (c *Client) UpdateUser(ctx context.Context, id int64, body User, params *UpdateUserParams, opts ...RequestOption) (*User, error) {
+	var result User
+	req := newRequest(http.MethodPut, "/v1/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteUserParams 查询参数和请求头，零值不发送
+type DeleteUserParams struct {
+	Force   bool   // 彻底删除（仅管理员）
+	IfMatch string // 获取用户时返回的 ETag，不匹配时拒绝删除
+}
+
+// DeleteUser 删除用户
+//
+// DELETE /v1/users/{id}
+func (c *Client) DeleteUser(ctx context.Context, id int64, params *DeleteUserParams, opts ...RequestOption) error {
+	req := newRequest(http.MethodDelete, "/v1/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if params != nil {
+		if params.Force {
+			req.query.Set("force", "true")
+		}
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+	}
+	return c.do(ctx, req, nil, opts)
+}
+
+// RestoreUser 恢复用户
+//
+// POST /v1/users/{id}/restore
+func (c *Client) RestoreUser(ctx context.Context, id int64, opts ...RequestOption) (*User, error) {
+	var result User
+	req := newRequest(http.MethodPost, "/v1/users/"+url.PathEscape(strconv.FormatInt(id, 10))+"/restore")
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// V2ListUsersParams 查询参数和请求头，零值不发送
+type V2ListUsersParams struct {
+	Trashed  string // 已删除用户的查询范围
+	Page     int64  // 页码
+	PageSize int64  // 每页数量
+}
+
+// V2ListUsers 获取用户列表
+//
+// GET /v2/users
+func (c *Client) V2ListUsers(ctx context.Context, params *V2ListUsersParams, opts ...RequestOption) (*Page[UserResponse], error) {
+	var result Page[UserResponse]
+	req := newRequest(http.MethodGet, "/v2/users")
+	if params != nil {
+		if params.Trashed != "" {
+			req.query.Set("trashed", params.Trashed)
+		}
+		if params.Page != 0 {
+			req.query.Set("page", strconv.FormatInt(params.Page, 10))
+		}
+		if params.PageSize != 0 {
+			req.query.Set("page_size", strconv.FormatInt(params.PageSize, 10))
+		}
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// V2CreateUserParams 查询参数和请求头，零值不发送
+type V2CreateUserParams struct {
+	IdempotencyKey string // 幂等键，重试时携带相同的值不会重复创建
+}
+
+// V2CreateUser 创建用户
+//
+// POST /v2/users
+func (c *Client) V2CreateUser(ctx context.Context, body CreateUserRequest, params *V2CreateUserParams, opts ...RequestOption) (*UserResponse, error) {
+	var result UserResponse
+	req := newRequest(http.MethodPost, "/v2/users")
+	if params != nil {
+		if params.IdempotencyKey != "" {
+			req.header.Set("Idempotency-Key", params.IdempotencyKey)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// V2GetUser 获取单个用户
+//
+// GET /v2/users/{id}
+func (c *Client) V2GetUser(ctx context.Context, id int64, opts ...RequestOption) (*UserResponse, error) {
+	var result UserResponse
+	req := newRequest(http.MethodGet, "/v2/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// V2UpdateUserParams 查询参数和请求头，零值不发送
+type V2UpdateUserParams struct {
+	IfMatch string // 获取用户时返回的 ETag，不匹配时拒绝更新
+}
+
+// V2UpdateUser 更新用户
+//
+// PUT /v2/users/{id}
+func (c *Client) V2UpdateUser(ctx context.Context, id int64, body UpdateUserRequest, params *V2UpdateUserParams, opts ...RequestOption) (*UserResponse, error) {
+	var result UserResponse
+	req := newRequest(http.MethodPut, "/v2/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if params != nil {
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+	}
+	if err := req.setJSON(body); err != nil {
+		return nil, err
+	}
+	if err := c.do(ctx, req, &result, opts); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// V2DeleteUserParams 查询参数和请求头，零值不发送
+type V2DeleteUserParams struct {
+	Force   bool   // 彻底删除（仅管理员）
+	IfMatch string // 获取用户时返回的 ETag，不匹配时拒绝删除
+}
+
+// V2DeleteUser 删除用户
+//
+// DELETE /v2/users/{id}
+func (c *Client) V2DeleteUser(ctx context.Context, id int64, params *V2DeleteUserParams, opts ...RequestOption) error {
+	req := newRequest(http.MethodDelete, "/v2/users/"+url.PathEscape(strconv.FormatInt(id, 10)))
+	if params != nil {
+		if params.Force {
+			req.query.Set("force", "true")
+		}
+		if params.IfMatch != "" {
+			req.header.Set("If-Match", params.IfMatch)
+		}
+	}
+	return c.do(ctx, req, nil, opts)
+}
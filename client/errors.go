@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error API 返回的错误，对应服务端的 ErrorResponse
+type Error struct {
+	StatusCode int             // HTTP 状态码
+	Code       int             // 响应中的 code
+	Message    string          // 响应中的 msg
+	Data       json.RawMessage // 响应中的 data，例如字段校验错误、冲突时的当前版本
+	RequestID  string          // X-Request-Id，便于在服务端日志中查找
+}
+
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("api error %d: %s (request id %s)", e.StatusCode, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// DecodeData 将错误响应的 data 解析到 v
+func (e *Error) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return errors.New("error response has no data")
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+func newError(res *http.Response, body []byte) *Error {
+	apiErr := &Error{StatusCode: res.StatusCode, RequestID: res.Header.Get("X-Request-Id")}
+	var env envelope
+	if err := json.Unmarshal(body, &env); err == nil && env.Msg != "" {
+		apiErr.Code = env.Code
+		apiErr.Message = env.Msg
+		apiErr.Data = env.Data
+		return apiErr
+	}
+	// 非统一格式的响应，例如网关返回的错误页
+	apiErr.Code = res.StatusCode
+	apiErr.Message = http.StatusText(res.StatusCode)
+	return apiErr
+}
+
+// StatusCode 返回 API 错误的 HTTP 状态码，不是 API 错误时返回 0
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound 是否为 404 错误
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict 是否为 409 错误，例如唯一约束冲突或版本冲突
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
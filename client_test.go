@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"echo-template/client"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// unavailableHandler 在 next 之前按需返回 503，用于验证客户端的重试
+type unavailableHandler struct {
+	next http.Handler
+
+	mu       sync.Mutex
+	failures int // 之后连续返回 503 的请求数
+	requests int
+}
+
+func (h *unavailableHandler) failNext(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures = n
+	h.requests = 0
+}
+
+func (h *unavailableHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests
+}
+
+func (h *unavailableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests++
+	fail := h.failures > 0
+	if fail {
+		h.failures--
+	}
+	h.mu.Unlock()
+
+	if fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// newTestClient 在 httptest 服务上启动完整的服务，返回以管理员 API Key 认证的客户端
+func newTestClient(t *testing.T, opts ...client.Option) (*client.Client, *unavailableHandler) {
+	t.Helper()
+	e, _ := newServer()
+	handler := &unavailableHandler{next: e}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]client.Option{
+		client.WithAuth(client.APIKeyAuth(createAdminAPIKey(t))),
+		client.WithRetry(client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}),
+	}, opts...)
+	return client.New(server.URL+"/api", opts...), handler
+}
+
+// apiError 断言 err 为指定状态码的 *client.Error
+func apiError(t *testing.T, err error, status int) *client.Error {
+	t.Helper()
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *client.Error with status %d, got %v", status, err)
+	}
+	if apiErr.StatusCode != status || apiErr.Message == "" {
+		t.Fatalf("unexpected error: status %d, message %q", apiErr.StatusCode, apiErr.Message)
+	}
+	return apiErr
+}
+
+func TestClient_UserCRUD(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := c.CreateUser(ctx, client.User{Username: "client_crud", Email: "client_crud@example.com", Name: "CRUD"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if created.ID == 0 || created.Username != "client_crud" {
+		t.Fatalf("unexpected created user: %+v", created)
+	}
+
+	// 用户名已被使用
+	_, err = c.CreateUser(ctx, client.User{Username: "client_crud", Email: "client_crud2@example.com"}, nil)
+	apiError(t, err, http.StatusConflict)
+
+	var info client.ResponseInfo
+	fetched, err := c.GetUser(ctx, created.ID, client.WithResponseInfo(&info))
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	etag := info.Header.Get("ETag")
+	if info.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("get user: status %d, ETag %q", info.StatusCode, etag)
+	}
+
+	fetched.Name = "Updated"
+	updated, err := c.UpdateUser(ctx, created.ID, *fetched, &client.UpdateUserParams{IfMatch: etag})
+	if err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	if updated.Name != "Updated" || updated.Version != fetched.Version+1 {
+		t.Fatalf("unexpected updated user: %+v", updated)
+	}
+
+	// 用户已被修改，之前的 ETag 不再匹配
+	_, err = c.UpdateUser(ctx, created.ID, *updated, &client.UpdateUserParams{IfMatch: etag})
+	apiError(t, err, http.StatusPreconditionFailed)
+	err = c.DeleteUser(ctx, created.ID, &client.DeleteUserParams{IfMatch: etag})
+	apiError(t, err, http.StatusPreconditionFailed)
+
+	// 不带 If-Match 时由版本号检测冲突
+	stale := *fetched
+	stale.Name = "Stale"
+	_, err = c.UpdateUser(ctx, created.ID, stale, nil)
+	apiError(t, err, http.StatusConflict)
+
+	if _, err := c.GetUser(ctx, created.ID, client.WithResponseInfo(&info)); err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if err := c.DeleteUser(ctx, created.ID, &client.DeleteUserParams{IfMatch: info.Header.Get("ETag")}); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+
+	_, err = c.GetUser(ctx, created.ID)
+	if apiErr := apiError(t, err, http.StatusNotFound); apiErr.Code == 0 || apiErr.RequestID == "" {
+		t.Fatalf("error envelope not decoded: code %d, request id %q", apiErr.Code, apiErr.RequestID)
+	}
+	if !client.IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false", err)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	c, handler := newTestClient(t)
+	ctx := context.Background()
+
+	// GET 在 503 后重试
+	handler.failNext(2)
+	if _, err := c.ListUsers(ctx, nil); err != nil {
+		t.Fatalf("list users after retries: %v", err)
+	}
+	if got := handler.count(); got != 3 {
+		t.Fatalf("GET attempts = %d, want 3", got)
+	}
+
+	// 超过最多尝试次数时返回最后一次的错误
+	handler.failNext(3)
+	_, err := c.ListUsers(ctx, nil)
+	apiErr := apiError(t, err, http.StatusServiceUnavailable)
+	if apiErr.Message != http.StatusText(http.StatusServiceUnavailable) {
+		t.Fatalf("unexpected message for a non-envelope response: %q", apiErr.Message)
+	}
+
+	// 不带幂等键的 POST 不重试
+	handler.failNext(1)
+	_, err = c.CreateUser(ctx, client.User{Username: "client_retry", Email: "client_retry@example.com"}, nil)
+	apiError(t, err, http.StatusServiceUnavailable)
+	if got := handler.count(); got != 1 {
+		t.Fatalf("POST without idempotency key attempts = %d, want 1", got)
+	}
+
+	// 带幂等键的 POST 重试，只创建一次
+	handler.failNext(1)
+	params := &client.CreateUserParams{IdempotencyKey: "client-retry-1"}
+	created, err := c.CreateUser(ctx, client.User{Username: "client_retry", Email: "client_retry@example.com"}, params)
+	if err != nil {
+		t.Fatalf("create user with idempotency key: %v", err)
+	}
+	if got := handler.count(); got != 2 {
+		t.Fatalf("POST with idempotency key attempts = %d, want 2", got)
+	}
+	var info client.ResponseInfo
+	replayed, err := c.CreateUser(ctx, client.User{Username: "client_retry", Email: "client_retry@example.com"}, params,
+		client.WithResponseInfo(&info))
+	if err != nil {
+		t.Fatalf("replay create user: %v", err)
+	}
+	if replayed.ID != created.ID || info.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed response for user %d, got user %d (replayed %q)",
+			created.ID, replayed.ID, info.Header.Get("Idempotent-Replayed"))
+	}
+}
+
+func TestClient_ContextCancellation(t *testing.T) {
+	c, handler := newTestClient(t,
+		client.WithRetry(client.RetryPolicy{MaxAttempts: 5, MinBackoff: time.Hour, MaxBackoff: time.Hour}))
+
+	// 已取消的请求不会发送
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.failNext(0)
+	if _, err := c.ListUsers(canceled, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := handler.count(); got != 0 {
+		t.Fatalf("requests sent with a canceled context: %d", got)
+	}
+
+	// 等待重试时取消，不等到退避结束
+	handler.failNext(5)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.GetUser(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("cancellation took %v", elapsed)
+	}
+	if got := handler.count(); got != 1 {
+		t.Fatalf("attempts before cancellation = %d, want 1", got)
+	}
+}
@@ -1,20 +1,49 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/labstack/gommon/bytes"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Env         string // 运行环境：development | production
+	Server      ServerConfig
+	Database    DatabaseConfig
+	RateLimit   RateLimitConfig
+	CORS        CORSConfig
+	Security    SecurityConfig
+	Compression CompressionConfig
+	SoftDelete  SoftDeleteConfig
+	Idempotency IdempotencyConfig
+	Batch       BatchConfig
+	Seed        SeedConfig
+	Docs        DocsConfig
+	OpenAPI     OpenAPIConfig
+	API         APIConfig
+	Envelope    EnvelopeConfig
+	I18n        I18nConfig
+	APIKey      APIKeyConfig
+	Auth        AuthConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
+	Port              string
+	Host              string
+	ReadTimeout       time.Duration // 读取整个请求（含请求体）的超时，防御慢速客户端
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration // keep-alive 连接空闲超时
 }
 
 type DatabaseConfig struct {
@@ -24,18 +53,258 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	TxMaxRetries   int           // 事务遇到序列化失败或死锁时的最大重试次数
+	TxRetryBackoff time.Duration // 首次重试前的等待时间，之后每次翻倍
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled  bool
+	Policies map[string]RateLimitPolicy // 策略名 -> 策略，由路由组按名称引用
+}
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Algorithm string        // token_bucket | sliding_window
+	KeyBy     string        // ip | user | api_key
+	Limit     int           // 每个窗口允许的请求数
+	Window    time.Duration // 窗口长度
+	Burst     int           // 令牌桶容量（仅 token_bucket 使用，默认等于 Limit）
+}
+
+// 限流算法
+const (
+	RateLimitTokenBucket   = "token_bucket"
+	RateLimitSlidingWindow = "sliding_window"
+)
+
+// 限流键来源
+const (
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyUser   = "user"
+	RateLimitKeyAPIKey = "api_key"
+)
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	Default CORSPolicy
+	Groups  map[string]CORSPolicy // 路由组路径前缀 -> 策略，按最长前缀匹配
+}
+
+// CORSPolicy 跨域策略
+type CORSPolicy struct {
+	AllowOrigins     []string // 支持 "*" 和通配子域名，例如 https://*.example.com
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int // 预检结果缓存秒数
+}
+
+// SecurityConfig 安全相关配置，默认值随运行环境变化
+type SecurityConfig struct {
+	HSTSMaxAge            int // 为 0 时不发送 Strict-Transport-Security
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ContentSecurityPolicy string
+	ContentTypeNosniff    bool
+	FrameOptions          string
+	ReferrerPolicy        string
+	BodyLimit             int64            // 默认请求体大小上限（字节）
+	BodyLimits            map[string]int64 // 路由路径前缀 -> 请求体大小上限，按最长前缀匹配
+	TrustedProxies        []*net.IPNet     // 可信代理，仅采信来自这些地址的 X-Forwarded-For
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	Enabled   bool
+	MinSize   int      // 小于该字节数的响应不压缩
+	Encodings []string // 服务端支持的编码，按优先级排序：br | zstd | gzip
+}
+
+// SoftDeleteConfig 软删除清理配置
+type SoftDeleteConfig struct {
+	RetentionDays int           // 软删除记录保留天数，超过后彻底删除；为 0 时不清理
+	PurgeInterval time.Duration // 清理任务执行间隔
+}
+
+// IdempotencyConfig 幂等键配置
+type IdempotencyConfig struct {
+	Enabled     bool
+	TTL         time.Duration // 响应保存时长，期间相同幂等键的重试直接回放
+	WaitTimeout time.Duration // 相同幂等键的请求仍在处理时的最长等待时间，为 0 时立即返回 409
+}
+
+// BatchConfig 批量操作配置
+type BatchConfig struct {
+	MaxItems      int // 单次请求允许的最大操作数
+	Size          int // 批量写入和导出时每批的记录数
+	MaxImportRows int // 单个导入文件允许的最大行数
+}
+
+// DocsConfig API 文档配置，默认值随运行环境变化
+type DocsConfig struct {
+	Enabled  bool     // 是否提供 /swagger，默认开发环境开启、生产环境关闭
+	Host     string   // 文档中的 host，为空时取请求的 Host（来自可信代理时取 X-Forwarded-Host）
+	BasePath string   // API 路径前缀，来自可信代理的 X-Forwarded-Prefix 会加在前面
+	Schemes  []string // 为空时取请求的协议（来自可信代理时取 X-Forwarded-Proto）
+	Auth     string   // 访问控制：none | basic | app，默认开发环境 none、生产环境 basic
+	Username string   // basic 认证的用户名和密码
+	Password string
+	Role     string // app 认证要求的角色，为空时只要求已认证
+}
+
+// OpenAPIConfig 按 OpenAPI 文档校验请求和响应，用于开发和测试环境
+type OpenAPIConfig struct {
+	Validate string // off | report | strict，生产环境只能为 off
+}
+
+// OpenAPI 校验模式
+const (
+	OpenAPIValidateOff    = "off"
+	OpenAPIValidateReport = "report" // 只报告不一致之处
+	OpenAPIValidateStrict = "strict" // 不符合文档的请求返回 400，响应仍只报告
+)
+
+// APIConfig API 版本协商配置
+type APIConfig struct {
+	DefaultVersion string // URL 和请求头都未指定版本时使用的版本，例如 v1
+	Vendor         string // 厂商媒体类型中的名称：application/vnd.<vendor>.v2+json
+}
+
+// EnvelopeConfig 响应包装配置：{code, data, msg[, meta]}
+type EnvelopeConfig struct {
+	Enabled     bool   // 成功响应是否包装，路由组可以单独开启或关闭
+	AllowPrefer bool   // 是否允许客户端以 Prefer: envelope=true|false 覆盖
+	CodeField   string // 各字段的名称，CodeField 为 "-" 时不输出 code
+	DataField   string
+	MsgField    string
+	MetaField   string
+	Meta        bool // 是否输出 meta：请求ID和分页信息
+}
+
+// I18nConfig 国际化配置，请求的语言依次取自查询参数、用户偏好和 Accept-Language
+type I18nConfig struct {
+	DefaultLocale string // 都未指定或都不受支持时使用的语言，须有对应的消息目录
+	QueryParam    string // 指定语言的查询参数，为 "-" 时不从查询参数读取
 }
 
+// APIKeyConfig API Key 认证配置，密钥格式为 <Prefix>_<标识>_<密钥>
+type APIKeyConfig struct {
+	Enabled          bool
+	Prefix           string        // 密钥的可见前缀，用于识别密钥类型和区分 Bearer 令牌，例如 etk
+	DefaultTTL       time.Duration // 创建时未指定过期时间的有效期，为 0 时不过期
+	LastUsedInterval time.Duration // 最近使用时间的最小更新间隔，避免每个请求都写库
+}
+
+// apiKeyPrefixPattern 密钥前缀中不能包含分隔符 _
+var apiKeyPrefixPattern = regexp.MustCompile(`^[a-z][a-z0-9]{1,15}$`)
+
+// AuthConfig 用户登录配置：通过 OIDC 身份提供方登录后签发访问令牌
+type AuthConfig struct {
+	Secret        string                // 签发访问令牌和加密登录状态的密钥，至少 32 字节
+	TokenTTL      time.Duration         // 访问令牌的有效期
+	StateTTL      time.Duration         // 从跳转到身份提供方到回调的最长时间
+	OIDCProviders map[string]OIDCConfig // 按名称登记的身份提供方
+}
+
+// OIDCConfig 单个 OIDC 身份提供方，从 OIDC_<NAME>_* 读取
+type OIDCConfig struct {
+	Name          string // 名称，出现在登录和回调路径中
+	DisplayName   string // 展示给用户的名称
+	Issuer        string // 签发者，须与发现文档中的 issuer 完全一致
+	ClientID      string
+	ClientSecret  string   // 公共客户端（仅使用 PKCE）可为空
+	RedirectURL   string   // 回调地址，须与在身份提供方登记的一致
+	Scopes        []string // 请求的权限范围，始终包含 openid
+	AllowSignup   bool     // 首次登录且无法按邮箱关联时自动创建本地用户
+	TrustEmail    bool     // 身份提供方不返回 email_verified 时视为邮箱已验证，仅用于确认会验证邮箱的提供方
+	UsernameClaim string   // 新用户用户名取自的声明
+	NameClaim     string   // 新用户姓名取自的声明
+	EmailClaim    string   // 邮箱取自的声明
+}
+
+// 访问令牌和登录状态的密钥长度下限
+const authSecretMinLength = 32
+
+// oidcProviderPattern 身份提供方名称同时用于路径和环境变量名
+var oidcProviderPattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,31}$`)
+
+// 文档访问控制方式
+const (
+	DocsAuthNone  = "none"
+	DocsAuthBasic = "basic"
+	DocsAuthApp   = "app" // 使用应用的认证，要求请求已认证
+)
+
+// SeedConfig 数据填充配置
+type SeedConfig struct {
+	FixturesDir   string // 夹具根目录，按数据集分子目录：<dir>/<set>/*.yaml
+	AdminUsername string // 初始管理员（生产环境数据集）
+	AdminEmail    string
+	AdminPassword string // 为空时不创建初始管理员
+}
+
+// 运行环境
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
 var AppConfig *Config
 
 func LoadConfig() error {
 	// 加载 .env 文件（如果存在）
 	_ = godotenv.Load()
 
-	AppConfig = &Config{
+	policies, err := parseRateLimitPolicies(getEnv("RATE_LIMIT_POLICIES",
+		"api=sliding_window:ip:300/1m;users_create=token_bucket:ip:10/1m:5"))
+	if err != nil {
+		return err
+	}
+
+	env := getEnv("APP_ENV", EnvDevelopment)
+
+	// 开发环境默认允许任意来源，生产环境必须显式配置 CORS_ALLOW_ORIGINS
+	var defaultOrigins []string
+	if env != EnvProduction {
+		defaultOrigins = []string{"*"}
+	}
+
+	defaultCORS := loadCORSPolicy("CORS_", CORSPolicy{
+		AllowOrigins: defaultOrigins,
+		AllowMethods: []string{"GET", "HEAD", "PUT", "PATCH", "POST", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key",
+			"If-Match", "If-None-Match", "Idempotency-Key", "Accept-Version", "Prefer"},
+		ExposeHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+			"RateLimit-Policy", "Retry-After", "ETag", "X-Request-Id", "Idempotent-Replayed",
+			"API-Version", "Deprecation", "Sunset", "Link", "Preference-Applied"},
+	})
+	corsGroups, err := loadCORSGroups(getEnv("CORS_GROUPS", ""), defaultCORS)
+	if err != nil {
+		return err
+	}
+
+	security, err := loadSecurityConfig(env == EnvProduction)
+	if err != nil {
+		return err
+	}
+
+	docsAuth := DocsAuthNone
+	if env == EnvProduction {
+		docsAuth = DocsAuthBasic
+	}
+
+	cfg := &Config{
+		Env: env,
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "1323"),
-			Host: getEnv("SERVER_HOST", "localhost"),
+			Port:              getEnv("SERVER_PORT", "1323"),
+			Host:              getEnv("SERVER_HOST", "localhost"),
+			ReadTimeout:       getEnvDuration("SERVER_READ_TIMEOUT", 30*time.Second),
+			ReadHeaderTimeout: getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+			WriteTimeout:      getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       getEnvDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -44,20 +313,462 @@ func LoadConfig() error {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "test"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			TxMaxRetries:   getEnvInt("DB_TX_MAX_RETRIES", 3),
+			TxRetryBackoff: getEnvDuration("DB_TX_RETRY_BACKOFF", 20*time.Millisecond),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:  getEnvBool("RATE_LIMIT_ENABLED", true),
+			Policies: policies,
+		},
+		CORS: CORSConfig{
+			Default: defaultCORS,
+			Groups:  corsGroups,
+		},
+		Security: security,
+		Compression: CompressionConfig{
+			Enabled:   getEnvBool("COMPRESSION_ENABLED", true),
+			MinSize:   getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+			Encodings: getEnvList("COMPRESSION_ENCODINGS", []string{"br", "zstd", "gzip"}),
 		},
+		SoftDelete: SoftDeleteConfig{
+			RetentionDays: getEnvInt("SOFT_DELETE_RETENTION_DAYS", 30),
+			PurgeInterval: getEnvDuration("SOFT_DELETE_PURGE_INTERVAL", 24*time.Hour),
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:     getEnvBool("IDEMPOTENCY_ENABLED", true),
+			TTL:         getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+			WaitTimeout: getEnvDuration("IDEMPOTENCY_WAIT_TIMEOUT", 10*time.Second),
+		},
+		Batch: BatchConfig{
+			MaxItems:      getEnvInt("BATCH_MAX_ITEMS", 1000),
+			Size:          getEnvInt("BATCH_SIZE", 100),
+			MaxImportRows: getEnvInt("IMPORT_MAX_ROWS", 10000),
+		},
+		Seed: SeedConfig{
+			FixturesDir:   getEnv("SEED_FIXTURES_DIR", "fixtures"),
+			AdminUsername: getEnv("SEED_ADMIN_USERNAME", "admin"),
+			AdminEmail:    getEnv("SEED_ADMIN_EMAIL", "admin@example.com"),
+			AdminPassword: getEnv("SEED_ADMIN_PASSWORD", ""),
+		},
+		Docs: DocsConfig{
+			Enabled:  getEnvBool("DOCS_ENABLED", env != EnvProduction),
+			Host:     getEnv("DOCS_HOST", ""),
+			BasePath: getEnv("DOCS_BASE_PATH", "/api"),
+			Schemes:  getEnvList("DOCS_SCHEMES", nil),
+			Auth:     getEnv("DOCS_AUTH", docsAuth),
+			Username: getEnv("DOCS_USERNAME", ""),
+			Password: getEnv("DOCS_PASSWORD", ""),
+			Role:     getEnv("DOCS_ROLE", ""),
+		},
+		OpenAPI: OpenAPIConfig{
+			Validate: getEnv("OPENAPI_VALIDATE", OpenAPIValidateOff),
+		},
+		API: APIConfig{
+			DefaultVersion: getEnv("API_DEFAULT_VERSION", "v1"),
+			Vendor:         getEnv("API_VENDOR", "echo-template"),
+		},
+		Envelope: EnvelopeConfig{
+			Enabled:     getEnvBool("ENVELOPE_ENABLED", true),
+			AllowPrefer: getEnvBool("ENVELOPE_PREFER", true),
+			CodeField:   getEnv("ENVELOPE_CODE_FIELD", "code"),
+			DataField:   getEnv("ENVELOPE_DATA_FIELD", "data"),
+			MsgField:    getEnv("ENVELOPE_MSG_FIELD", "msg"),
+			MetaField:   getEnv("ENVELOPE_META_FIELD", "meta"),
+			Meta:        getEnvBool("ENVELOPE_META", false),
+		},
+		I18n: I18nConfig{
+			DefaultLocale: getEnv("I18N_DEFAULT_LOCALE", "zh-CN"),
+			QueryParam:    getEnv("I18N_QUERY_PARAM", "lang"),
+		},
+		APIKey: APIKeyConfig{
+			Enabled:          getEnvBool("API_KEY_ENABLED", true),
+			Prefix:           getEnv("API_KEY_PREFIX", "etk"),
+			DefaultTTL:       getEnvDuration("API_KEY_DEFAULT_TTL", 90*24*time.Hour),
+			LastUsedInterval: getEnvDuration("API_KEY_LAST_USED_INTERVAL", time.Minute),
+		},
+		Auth: AuthConfig{
+			Secret:        getEnv("AUTH_SECRET", ""),
+			TokenTTL:      getEnvDuration("AUTH_TOKEN_TTL", 12*time.Hour),
+			StateTTL:      getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+			OIDCProviders: loadOIDCProviders(getEnvList("OIDC_PROVIDERS", nil)),
+		},
+	}
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	AppConfig = cfg
+	return nil
+}
+
+// IsProduction 是否为生产环境
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// validate 校验配置，拒绝生产环境中不安全的组合
+func (c *Config) validate() error {
+	for _, encoding := range c.Compression.Encodings {
+		switch encoding {
+		case "br", "zstd", "gzip":
+		default:
+			return fmt.Errorf("unsupported compression encoding %q", encoding)
+		}
+	}
+
+	if c.Batch.MaxItems <= 0 || c.Batch.Size <= 0 || c.Batch.MaxImportRows <= 0 {
+		return fmt.Errorf("BATCH_MAX_ITEMS, BATCH_SIZE and IMPORT_MAX_ROWS must be positive")
+	}
+
+	switch c.Docs.Auth {
+	case DocsAuthNone, DocsAuthApp:
+	case DocsAuthBasic:
+		if c.Docs.Enabled && (c.Docs.Username == "" || c.Docs.Password == "") {
+			return fmt.Errorf("DOCS_USERNAME and DOCS_PASSWORD are required when DOCS_AUTH=basic")
+		}
+	default:
+		return fmt.Errorf("invalid DOCS_AUTH %q", c.Docs.Auth)
+	}
+	for _, scheme := range c.Docs.Schemes {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("invalid DOCS_SCHEMES entry %q", scheme)
+		}
+	}
+
+	switch c.OpenAPI.Validate {
+	case OpenAPIValidateOff:
+	case OpenAPIValidateReport, OpenAPIValidateStrict:
+		if c.IsProduction() {
+			return fmt.Errorf("OPENAPI_VALIDATE must be off in production")
+		}
+	default:
+		return fmt.Errorf("invalid OPENAPI_VALIDATE %q", c.OpenAPI.Validate)
+	}
+
+	if c.API.Vendor == "" || strings.ContainsAny(c.API.Vendor, "/+; ") {
+		return fmt.Errorf("invalid API_VENDOR %q", c.API.Vendor)
+	}
+
+	if !apiKeyPrefixPattern.MatchString(c.APIKey.Prefix) {
+		return fmt.Errorf("invalid API_KEY_PREFIX %q: 2-16 lowercase letters or digits, starting with a letter", c.APIKey.Prefix)
+	}
+	if c.APIKey.DefaultTTL < 0 || c.APIKey.LastUsedInterval < 0 {
+		return fmt.Errorf("API_KEY_DEFAULT_TTL and API_KEY_LAST_USED_INTERVAL must not be negative")
+	}
+
+	if err := c.Auth.validate(c.IsProduction()); err != nil {
+		return err
+	}
+
+	if c.I18n.QueryParam == "-" {
+		c.I18n.QueryParam = ""
+	}
+
+	if c.Envelope.CodeField == "-" {
+		c.Envelope.CodeField = ""
+	}
+	fields := []string{c.Envelope.DataField, c.Envelope.MsgField, c.Envelope.MetaField}
+	if c.Envelope.CodeField != "" {
+		fields = append(fields, c.Envelope.CodeField)
+	}
+	for i, field := range fields {
+		if field == "-" || slices.Contains(fields[i+1:], field) {
+			return fmt.Errorf("ENVELOPE_*_FIELD names must be distinct and only ENVELOPE_CODE_FIELD may be \"-\"")
+		}
+	}
+
+	if !c.IsProduction() {
+		return nil
+	}
+
+	policies := map[string]CORSPolicy{"default": c.CORS.Default}
+	for prefix, policy := range c.CORS.Groups {
+		policies[prefix] = policy
+	}
+	for name, policy := range policies {
+		if policy.AllowCredentials && slices.Contains(policy.AllowOrigins, "*") {
+			return fmt.Errorf("cors policy %q: wildcard origin with credentials is not allowed in production", name)
+		}
 	}
+	return nil
+}
 
+// validate 校验登录配置；生产环境登记了身份提供方时必须设置 AUTH_SECRET，
+// 其他情况下未设置时随机生成，重启后已签发的令牌失效
+func (a *AuthConfig) validate(production bool) error {
+	if a.Secret == "" && (!production || len(a.OIDCProviders) == 0) {
+		secret := make([]byte, authSecretMinLength)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		a.Secret = hex.EncodeToString(secret)
+	}
+	if len(a.Secret) < authSecretMinLength {
+		return fmt.Errorf("AUTH_SECRET must be at least %d bytes", authSecretMinLength)
+	}
+	if a.TokenTTL <= 0 || a.StateTTL <= 0 {
+		return fmt.Errorf("AUTH_TOKEN_TTL and OIDC_STATE_TTL must be positive")
+	}
+
+	for name, provider := range a.OIDCProviders {
+		if !oidcProviderPattern.MatchString(name) {
+			return fmt.Errorf("invalid OIDC_PROVIDERS entry %q: lowercase letters or digits, starting with a letter", name)
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return fmt.Errorf("%sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", prefix, prefix, prefix)
+		}
+		if production && !strings.HasPrefix(provider.Issuer, "https://") {
+			return fmt.Errorf("%sISSUER must use https in production", prefix)
+		}
+		if !slices.Contains(provider.Scopes, "openid") {
+			provider.Scopes = append([]string{"openid"}, provider.Scopes...)
+			a.OIDCProviders[name] = provider
+		}
+	}
 	return nil
 }
 
+// IsTrustedProxy 请求的直接来源是否为可信代理，只有可信代理发送的 X-Forwarded-* 头才会被采信
+func (s *SecurityConfig) IsTrustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range s.TrustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		d.Host, d.Port, d.User, d.Password, d.DBName, d.SSLMode)
 }
 
+// parseRateLimitPolicies 解析限流策略
+// 格式：name=algorithm:key:limit/window[:burst]，多个策略以分号分隔
+// 例如：api=sliding_window:ip:300/1m;users_create=token_bucket:ip:10/1m:5
+func parseRateLimitPolicies(value string) (map[string]RateLimitPolicy, error) {
+	policies := make(map[string]RateLimitPolicy)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit policy %q", item)
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) < 3 || len(parts) > 4 {
+			return nil, fmt.Errorf("invalid rate limit policy %q", item)
+		}
+
+		var err error
+		policy := RateLimitPolicy{Algorithm: parts[0], KeyBy: parts[1]}
+		switch policy.Algorithm {
+		case RateLimitTokenBucket, RateLimitSlidingWindow:
+		default:
+			return nil, fmt.Errorf("unknown rate limit algorithm %q in policy %q", policy.Algorithm, name)
+		}
+		switch policy.KeyBy {
+		case RateLimitKeyIP, RateLimitKeyUser, RateLimitKeyAPIKey:
+		default:
+			return nil, fmt.Errorf("unknown rate limit key %q in policy %q", policy.KeyBy, name)
+		}
+
+		limit, window, ok := strings.Cut(parts[2], "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q in policy %q", parts[2], name)
+		}
+		if policy.Limit, err = strconv.Atoi(limit); err != nil || policy.Limit <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q in policy %q", parts[2], name)
+		}
+		if policy.Window, err = time.ParseDuration(window); err != nil || policy.Window <= 0 {
+			return nil, fmt.Errorf("invalid rate limit window %q in policy %q", parts[2], name)
+		}
+
+		policy.Burst = policy.Limit
+		if len(parts) == 4 {
+			if policy.Burst, err = strconv.Atoi(parts[3]); err != nil || policy.Burst <= 0 {
+				return nil, fmt.Errorf("invalid rate limit burst %q in policy %q", parts[3], name)
+			}
+		}
+
+		policies[strings.TrimSpace(name)] = policy
+	}
+	return policies, nil
+}
+
+// loadSecurityConfig 读取安全配置，生产环境默认开启 HSTS 和更严格的策略
+func loadSecurityConfig(production bool) (SecurityConfig, error) {
+	hstsMaxAge := 0
+	if production {
+		hstsMaxAge = 31536000
+	}
+
+	bodyLimit, err := bytes.Parse(getEnv("BODY_LIMIT", "2M"))
+	if err != nil {
+		return SecurityConfig{}, fmt.Errorf("invalid BODY_LIMIT: %w", err)
+	}
+
+	bodyLimits := make(map[string]int64)
+	for _, item := range strings.Split(getEnv("BODY_LIMIT_ROUTES", ""), ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, size, ok := strings.Cut(item, "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return SecurityConfig{}, fmt.Errorf("invalid body limit route %q", item)
+		}
+		if bodyLimits[prefix], err = bytes.Parse(size); err != nil {
+			return SecurityConfig{}, fmt.Errorf("invalid body limit route %q: %w", item, err)
+		}
+	}
+
+	var trustedProxies []*net.IPNet
+	for _, cidr := range getEnvList("TRUSTED_PROXIES", nil) {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return SecurityConfig{}, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", cidr, err)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+
+	return SecurityConfig{
+		HSTSMaxAge:            getEnvInt("SECURITY_HSTS_MAX_AGE", hstsMaxAge),
+		HSTSIncludeSubdomains: getEnvBool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", production),
+		HSTSPreload:           getEnvBool("SECURITY_HSTS_PRELOAD", false),
+		ContentSecurityPolicy: getEnv("SECURITY_CSP", "default-src 'none'; frame-ancestors 'none'"),
+		ContentTypeNosniff:    getEnvBool("SECURITY_CONTENT_TYPE_NOSNIFF", true),
+		FrameOptions:          getEnv("SECURITY_FRAME_OPTIONS", "DENY"),
+		ReferrerPolicy:        getEnv("SECURITY_REFERRER_POLICY", "no-referrer"),
+		BodyLimit:             bodyLimit,
+		BodyLimits:            bodyLimits,
+		TrustedProxies:        trustedProxies,
+	}, nil
+}
+
+// loadCORSPolicy 从带前缀的环境变量读取跨域策略，未设置的字段沿用 base
+func loadCORSPolicy(prefix string, base CORSPolicy) CORSPolicy {
+	return CORSPolicy{
+		AllowOrigins:     getEnvList(prefix+"ALLOW_ORIGINS", base.AllowOrigins),
+		AllowMethods:     getEnvList(prefix+"ALLOW_METHODS", base.AllowMethods),
+		AllowHeaders:     getEnvList(prefix+"ALLOW_HEADERS", base.AllowHeaders),
+		ExposeHeaders:    getEnvList(prefix+"EXPOSE_HEADERS", base.ExposeHeaders),
+		AllowCredentials: getEnvBool(prefix+"ALLOW_CREDENTIALS", base.AllowCredentials),
+		MaxAge:           getEnvInt(prefix+"MAX_AGE", base.MaxAge),
+	}
+}
+
+// loadCORSGroups 解析路由组跨域策略
+// 格式：name=/path/prefix，多个分组以分号分隔，例如 partner=/api/v1/partner
+// 每个分组从 CORS_<NAME>_* 读取覆盖项，例如 CORS_PARTNER_ALLOW_ORIGINS
+func loadCORSGroups(value string, base CORSPolicy) (map[string]CORSPolicy, error) {
+	groups := make(map[string]CORSPolicy)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, prefix, ok := strings.Cut(item, "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid cors group %q", item)
+		}
+
+		envPrefix := "CORS_" + strings.ToUpper(strings.TrimSpace(name)) + "_"
+		groups[strings.TrimSpace(prefix)] = loadCORSPolicy(envPrefix, base)
+	}
+	return groups, nil
+}
+
+// loadOIDCProviders 读取 OIDC_PROVIDERS 中列出的身份提供方，
+// 每个提供方从 OIDC_<NAME>_* 读取，例如 OIDC_CORP_ISSUER、OIDC_CORP_CLIENT_ID
+func loadOIDCProviders(names []string) map[string]OIDCConfig {
+	providers := make(map[string]OIDCConfig, len(names))
+	for _, name := range names {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = OIDCConfig{
+			Name:          name,
+			DisplayName:   getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:        getEnv(prefix+"ISSUER", ""),
+			ClientID:      getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:  getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:   getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:        getEnvList(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+			AllowSignup:   getEnvBool(prefix+"ALLOW_SIGNUP", true),
+			TrustEmail:    getEnvBool(prefix+"TRUST_EMAIL", false),
+			UsernameClaim: getEnv(prefix+"USERNAME_CLAIM", "preferred_username"),
+			NameClaim:     getEnv(prefix+"NAME_CLAIM", "name"),
+			EmailClaim:    getEnv(prefix+"EMAIL_CLAIM", "email"),
+		}
+	}
+	return providers
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvList 读取逗号分隔的列表，值为 "-" 时表示空列表
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if value == "-" {
+		return nil
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
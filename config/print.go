@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Print 以缩进的层级结构输出配置，名称包含 Password 的字段以 ****** 代替
+func (c *Config) Print(w io.Writer) error {
+	return printFields(w, "", reflect.ValueOf(c).Elem())
+}
+
+func printFields(w io.Writer, indent string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if err := printValue(w, indent, field.Name, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printValue(w io.Writer, indent, name string, v reflect.Value) error {
+	switch {
+	case strings.Contains(name, "Password"):
+		value := ""
+		if !v.IsZero() {
+			value = "******"
+		}
+		_, err := fmt.Fprintf(w, "%s%s: %s\n", indent, name, value)
+		return err
+	case v.Kind() == reflect.Struct:
+		if _, err := fmt.Fprintf(w, "%s%s:\n", indent, name); err != nil {
+			return err
+		}
+		return printFields(w, indent+"  ", v)
+	case v.Kind() == reflect.Map:
+		if _, err := fmt.Fprintf(w, "%s%s:\n", indent, name); err != nil {
+			return err
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			if err := printValue(w, indent+"  ", key.String(), v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		_, err := fmt.Fprintf(w, "%s%s: %v\n", indent, name, v.Interface())
+		return err
+	}
+}
@@ -39,6 +39,8 @@ func InitDB() error {
 
 	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// 将唯一约束冲突等驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误
+		TranslateError: true,
 	})
 
 	if err != nil {
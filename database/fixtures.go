@@ -0,0 +1,282 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// 记录已存在时的处理方式
+const (
+	FixtureUpdate = "update" // 更新为夹具中的值（默认）
+	FixtureSkip   = "skip"   // 保持不变，适合只需初始化一次的数据，例如生产环境的管理员
+)
+
+// Fixture 夹具，一个文件描述一张表的数据，支持 YAML 和 JSON
+//
+//	model: users
+//	key: [username]
+//	on_conflict: update
+//	rows:
+//	  - username: alice
+//	    email: alice@example.com
+type Fixture struct {
+	Model      string                   `json:"model"`       // 表名
+	Key        []string                 `json:"key"`         // 判断记录是否已存在的字段（JSON 字段名），默认为 id
+	OnConflict string                   `json:"on_conflict"` // update | skip
+	Rows       []map[string]interface{} `json:"rows"`        // 按模型的 JSON 字段名填写
+}
+
+// FixtureHook 模型实现该接口后，加载夹具时会在写入前调用
+// 用于 JSON 标签无法赋值的字段，例如对明文密码做哈希；row 为夹具中的原始数据
+type FixtureHook interface {
+	BeforeFixture(row map[string]interface{}) error
+}
+
+// FixtureStats 夹具加载结果
+type FixtureStats struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+func (s *FixtureStats) add(other FixtureStats) {
+	s.Created += other.Created
+	s.Updated += other.Updated
+	s.Unchanged += other.Unchanged
+}
+
+// ReadFixtureFile 读取 YAML 或 JSON 夹具文件
+func ReadFixtureFile(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+
+	var fixture Fixture
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// FixtureFiles 返回目录下的夹具文件（.yaml、.yml、.json），按文件名排序，可用数字前缀控制加载顺序
+func FixtureFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadFixtures 按顺序加载夹具文件，全部文件在同一事务中写入
+// models 为夹具可以引用的模型，通常为 models.All()
+func LoadFixtures(ctx context.Context, db *gorm.DB, models []interface{}, paths ...string) (FixtureStats, error) {
+	fixtures := make([]*Fixture, len(paths))
+	for i, path := range paths {
+		fixture, err := ReadFixtureFile(path)
+		if err != nil {
+			return FixtureStats{}, err
+		}
+		fixtures[i] = fixture
+	}
+
+	var stats FixtureStats
+	err := Conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		ctx := ContextWithTx(ctx, tx)
+		for i, fixture := range fixtures {
+			result, err := ApplyFixture(ctx, db, models, fixture)
+			if err != nil {
+				return fmt.Errorf("fixture %s: %w", paths[i], err)
+			}
+			stats.add(result)
+		}
+		return nil
+	})
+	if err != nil {
+		return FixtureStats{}, err
+	}
+	return stats, nil
+}
+
+// ApplyFixture 写入夹具数据（upsert）
+// 按 Key 查找未删除的记录：不存在时创建；存在时按 OnConflict 更新或跳过，
+// 更新时只覆盖夹具中出现的字段，值没有变化的记录不会写入，重复加载是幂等的
+func ApplyFixture(ctx context.Context, db *gorm.DB, models []interface{}, fixture *Fixture) (FixtureStats, error) {
+	var stats FixtureStats
+
+	modelType, s, err := fixtureModel(db, models, fixture.Model)
+	if err != nil {
+		return stats, err
+	}
+
+	keys := fixture.Key
+	if len(keys) == 0 {
+		keys = []string{"id"}
+	}
+	keyFields := make([]*schema.Field, len(keys))
+	for i, key := range keys {
+		if keyFields[i] = fixtureField(s, key); keyFields[i] == nil {
+			return stats, fmt.Errorf("unknown key field %q in model %q", key, fixture.Model)
+		}
+	}
+
+	onConflict := fixture.OnConflict
+	switch onConflict {
+	case "":
+		onConflict = FixtureUpdate
+	case FixtureUpdate, FixtureSkip:
+	default:
+		return stats, fmt.Errorf("invalid on_conflict %q", fixture.OnConflict)
+	}
+
+	err = Conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		for i, row := range fixture.Rows {
+			for _, key := range keys {
+				if _, ok := row[key]; !ok {
+					return fmt.Errorf("row %d: missing key field %q", i+1, key)
+				}
+			}
+			if err := applyFixtureRow(tx, modelType, s, keyFields, onConflict, row, &stats); err != nil {
+				return fmt.Errorf("row %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func applyFixtureRow(tx *gorm.DB, modelType reflect.Type, s *schema.Schema, keyFields []*schema.Field,
+	onConflict string, row map[string]interface{}, stats *FixtureStats) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	candidate := reflect.New(modelType)
+	if err := json.Unmarshal(data, candidate.Interface()); err != nil {
+		return err
+	}
+
+	ctx := tx.Statement.Context
+	query := tx
+	for _, field := range keyFields {
+		value, _ := field.ValueOf(ctx, candidate.Elem())
+		query = query.Where(tx.Statement.Quote(field.DBName)+" = ?", value)
+	}
+
+	existing := reflect.New(modelType)
+	err = query.First(existing.Interface()).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := beforeFixture(candidate, row); err != nil {
+			return err
+		}
+		if err := tx.Create(candidate.Interface()).Error; err != nil {
+			return err
+		}
+		stats.Created++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if onConflict == FixtureSkip {
+		stats.Unchanged++
+		return nil
+	}
+
+	before := reflect.New(modelType).Elem()
+	before.Set(existing.Elem())
+	if err := json.Unmarshal(data, existing.Interface()); err != nil {
+		return err
+	}
+	if err := beforeFixture(existing, row); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(before.Interface(), existing.Elem().Interface()) {
+		stats.Unchanged++
+		return nil
+	}
+
+	// 带乐观锁版本号的模型，更新后版本号加一
+	if field := s.LookUpField("version"); field != nil {
+		value, _ := field.ValueOf(ctx, before)
+		if version, ok := value.(uint); ok {
+			if err := field.Set(ctx, existing.Elem(), version+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Model(existing.Interface()).Select("*").Omit(s.PrimaryFieldDBNames...).Updates(existing.Interface()).Error; err != nil {
+		return err
+	}
+	stats.Updated++
+	return nil
+}
+
+func beforeFixture(value reflect.Value, row map[string]interface{}) error {
+	if hook, ok := value.Interface().(FixtureHook); ok {
+		return hook.BeforeFixture(row)
+	}
+	return nil
+}
+
+// fixtureModel 按表名查找模型
+func fixtureModel(db *gorm.DB, models []interface{}, table string) (reflect.Type, *schema.Schema, error) {
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, nil, err
+		}
+		if stmt.Schema.Table == table {
+			return stmt.Schema.ModelType, stmt.Schema, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unknown model %q", table)
+}
+
+// fixtureField 按 JSON 字段名查找模型字段
+func fixtureField(s *schema.Schema, name string) *schema.Field {
+	for _, field := range s.Fields {
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" || field.DBName == "" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		if jsonName == name {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PostMigrator 模型实现该接口后，会在 AutoMigrate 之后执行附加迁移
+// 用于 GORM 标签无法表达的结构，例如部分唯一索引、全文索引
+type PostMigrator interface {
+	PostMigrate(db *gorm.DB) error
+}
+
+// Migrate 自动迁移模型表结构，并执行模型声明的附加迁移
+func Migrate(db *gorm.DB, models ...interface{}) error {
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+
+	for _, model := range models {
+		if migrator, ok := model.(PostMigrator); ok {
+			if err := migrator.PostMigrate(db); err != nil {
+				return fmt.Errorf("post migrate %T: %w", model, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureActiveUniqueIndex 创建只约束未删除记录的唯一索引，使软删除的记录不再占用唯一值
+// PostgreSQL 和 SQLite 使用部分索引（WHERE deleted_at IS NULL）；
+// MySQL 不支持部分索引，退化为普通唯一索引
+// legacy 为需要替换掉的旧索引名（例如 uniqueIndex 标签生成的索引）
+func EnsureActiveUniqueIndex(db *gorm.DB, table, name string, columns []string, legacy ...string) error {
+	migrator := db.Migrator()
+	for _, old := range legacy {
+		if migrator.HasIndex(table, old) {
+			if err := migrator.DropIndex(table, old); err != nil {
+				return err
+			}
+		}
+	}
+
+	if migrator.HasIndex(table, name) {
+		return nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = db.Statement.Quote(column)
+	}
+	sql := fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)",
+		db.Statement.Quote(name), db.Statement.Quote(table), strings.Join(quoted, ", "))
+
+	switch db.Dialector.Name() {
+	case "postgres", "sqlite":
+		sql += " WHERE " + db.Statement.Quote("deleted_at") + " IS NULL"
+	}
+	return db.Exec(sql).Error
+}
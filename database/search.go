@@ -0,0 +1,295 @@
+package database
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// SearchIndex 全文检索定义，模型在 PostMigrate 中调用 Migrate 创建索引，服务层调用 Search 检索
+// PostgreSQL 使用 tsvector + pg_trgm，MySQL 使用 FULLTEXT（ngram 分词），
+// SQLite 使用 FTS5 trigram 外部内容表并通过触发器同步；SQLite 驱动未启用 FTS5 时退化为 LIKE
+type SearchIndex struct {
+	Table      string   // 被检索的表
+	Columns    []string // 参与检索的文本列
+	SoftDelete bool     // 为 true 时排除 deleted_at 不为空的记录
+}
+
+// SearchHit 检索命中的记录，Rank 越大越相关
+type SearchHit struct {
+	ID   uint
+	Rank float64
+}
+
+// Migrate 创建当前数据库对应的全文索引，可重复执行
+func (idx SearchIndex) Migrate(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return idx.migratePostgres(db)
+	case "mysql":
+		return idx.migrateMySQL(db)
+	case "sqlite":
+		return idx.migrateSQLite(db)
+	default:
+		return nil
+	}
+}
+
+// Search 按相关度降序检索，返回当前页的命中记录和总数
+func (idx SearchIndex) Search(db *gorm.DB, query string, offset, limit int) ([]SearchHit, int64, error) {
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	var from, where, rank string
+	var whereArgs, rankArgs []interface{}
+	switch db.Dialector.Name() {
+	case "postgres":
+		from, where, rank, whereArgs, rankArgs = idx.postgresQuery(db, strings.Join(terms, " "))
+	case "mysql":
+		from, where, rank, whereArgs, rankArgs = idx.mysqlQuery(db, strings.Join(terms, " "))
+	case "sqlite":
+		from, where, rank, whereArgs, rankArgs = idx.sqliteQuery(db, terms)
+	default:
+		from, where, rank, whereArgs = idx.likeQuery(db, terms)
+	}
+	if idx.SoftDelete {
+		where = "(" + where + ") AND t." + db.Statement.Quote("deleted_at") + " IS NULL"
+	}
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM "+from+" WHERE "+where, whereArgs...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	id := "t." + db.Statement.Quote("id")
+	sql := fmt.Sprintf("SELECT %s AS id, %s AS %s FROM %s WHERE %s ORDER BY %s DESC, %s LIMIT ? OFFSET ?",
+		id, rank, db.Statement.Quote("rank"), from, where, db.Statement.Quote("rank"), id)
+	args := append(append(rankArgs, whereArgs...), limit, offset)
+
+	var hits []SearchHit
+	if err := db.Raw(sql, args...).Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// SearchTerms 将检索词按空白拆分，去掉空项
+func SearchTerms(query string) []string {
+	return strings.Fields(query)
+}
+
+// Highlight 将 text 中与任一检索词匹配的部分（不区分大小写）用 <mark></mark> 包裹，
+// 其余部分做 HTML 转义；没有匹配时返回空字符串
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	matched := false
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+				matched = true
+			}
+		}
+	}
+	if !matched {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			segment = "<mark>" + segment + "</mark>"
+		}
+		b.WriteString(segment)
+		i = j
+	}
+	return b.String()
+}
+
+func (idx SearchIndex) indexName(suffix string) string {
+	return "idx_" + idx.Table + "_search" + suffix
+}
+
+// postgresDocument 拼接所有检索列，索引表达式必须与查询中的表达式完全一致才能命中索引
+func (idx SearchIndex) postgresDocument(db *gorm.DB, alias string) string {
+	parts := make([]string, len(idx.Columns))
+	for i, column := range idx.Columns {
+		parts[i] = "coalesce(" + alias + db.Statement.Quote(column) + ", '')"
+	}
+	return "(" + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+func (idx SearchIndex) migratePostgres(db *gorm.DB) error {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return fmt.Errorf("create extension pg_trgm: %w", err)
+	}
+	table := db.Statement.Quote(idx.Table)
+	document := idx.postgresDocument(db, "")
+	statements := []string{
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (to_tsvector('simple', %s))",
+			db.Statement.Quote(idx.indexName("_tsv")), table, document),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s gin_trgm_ops)",
+			db.Statement.Quote(idx.indexName("_trgm")), table, document),
+	}
+	for _, sql := range statements {
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postgresQuery 全文匹配或子串匹配（由 trigram 索引加速），相关度为 ts_rank 与 trigram 相似度之和
+func (idx SearchIndex) postgresQuery(db *gorm.DB, query string) (from, where, rank string, whereArgs, rankArgs []interface{}) {
+	document := idx.postgresDocument(db, "t.")
+	from = db.Statement.Quote(idx.Table) + " t"
+	where = fmt.Sprintf("to_tsvector('simple', %s) @@ plainto_tsquery('simple', ?) OR %s ILIKE ? ESCAPE '!'", document, document)
+	rank = fmt.Sprintf("ts_rank(to_tsvector('simple', %s), plainto_tsquery('simple', ?)) + similarity(%s, ?)", document, document)
+	return from, where, rank, []interface{}{query, "%" + escapeLike(query) + "%"}, []interface{}{query, query}
+}
+
+func (idx SearchIndex) migrateMySQL(db *gorm.DB) error {
+	name := idx.indexName("")
+	if db.Migrator().HasIndex(idx.Table, name) {
+		return nil
+	}
+	return db.Exec(fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s) WITH PARSER ngram",
+		db.Statement.Quote(idx.Table), db.Statement.Quote(name), idx.quotedColumns(db, ""))).Error
+}
+
+func (idx SearchIndex) mysqlQuery(db *gorm.DB, query string) (from, where, rank string, whereArgs, rankArgs []interface{}) {
+	match := fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", idx.quotedColumns(db, "t."))
+	from = db.Statement.Quote(idx.Table) + " t"
+	return from, match, match, []interface{}{query}, []interface{}{query}
+}
+
+func (idx SearchIndex) ftsTable() string {
+	return idx.Table + "_fts"
+}
+
+// migrateSQLite 创建 FTS5 外部内容表和同步触发器，首次创建时从原表重建索引
+func (idx SearchIndex) migrateSQLite(db *gorm.DB) error {
+	fts := idx.ftsTable()
+	if db.Migrator().HasTable(fts) {
+		return nil
+	}
+
+	// go-sqlite3 只有在 sqlite_fts5 构建标签下才编译 FTS5
+	var fts5 bool
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5).Error; err != nil {
+		return err
+	}
+	if !fts5 {
+		log.Printf("SQLite FTS5 is not available, %s search falls back to LIKE (build with -tags sqlite_fts5)", idx.Table)
+		return nil
+	}
+
+	columns := idx.quotedColumns(db, "")
+	table := db.Statement.Quote(idx.Table)
+	quotedFTS := db.Statement.Quote(fts)
+	newValues := idx.quotedColumns(db, "new.")
+	oldValues := idx.quotedColumns(db, "old.")
+	statements := []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content=%s, content_rowid='id', tokenize='trigram')",
+			quotedFTS, columns, quoteString(idx.Table)),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s BEGIN INSERT INTO %s(rowid, %s) VALUES (new.id, %s); END",
+			db.Statement.Quote(fts+"_ai"), table, quotedFTS, columns, newValues),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s BEGIN INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s); END",
+			db.Statement.Quote(fts+"_ad"), table, quotedFTS, quotedFTS, columns, oldValues),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s BEGIN "+
+			"INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s); "+
+			"INSERT INTO %s(rowid, %s) VALUES (new.id, %s); END",
+			db.Statement.Quote(fts+"_au"), table, quotedFTS, quotedFTS, columns, oldValues, quotedFTS, columns, newValues),
+		fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", quotedFTS, quotedFTS),
+	}
+	for _, sql := range statements {
+		if err := db.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteQuery trigram 分词只能匹配至少 3 个字符的检索词，含更短的检索词或未启用 FTS5 时使用 LIKE
+func (idx SearchIndex) sqliteQuery(db *gorm.DB, terms []string) (from, where, rank string, whereArgs, rankArgs []interface{}) {
+	fts := idx.ftsTable()
+	if !db.Migrator().HasTable(fts) {
+		from, where, rank, whereArgs = idx.likeQuery(db, terms)
+		return from, where, rank, whereArgs, nil
+	}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < 3 {
+			from, where, rank, whereArgs = idx.likeQuery(db, terms)
+			return from, where, rank, whereArgs, nil
+		}
+	}
+
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	quotedFTS := db.Statement.Quote(fts)
+	from = quotedFTS + " JOIN " + db.Statement.Quote(idx.Table) + " t ON t." + db.Statement.Quote("id") + " = " + quotedFTS + ".rowid"
+	where = quotedFTS + " MATCH ?"
+	// bm25 越小越相关
+	rank = "-bm25(" + quotedFTS + ")"
+	return from, where, rank, []interface{}{strings.Join(phrases, " AND ")}, nil
+}
+
+// likeQuery 每个检索词都需要出现在任一检索列中，不计算相关度
+func (idx SearchIndex) likeQuery(db *gorm.DB, terms []string) (from, where, rank string, whereArgs []interface{}) {
+	conditions := make([]string, len(terms))
+	for i, term := range terms {
+		columns := make([]string, len(idx.Columns))
+		for j, column := range idx.Columns {
+			columns[j] = "t." + db.Statement.Quote(column) + " LIKE ? ESCAPE '!'"
+			whereArgs = append(whereArgs, "%"+escapeLike(term)+"%")
+		}
+		conditions[i] = "(" + strings.Join(columns, " OR ") + ")"
+	}
+	return db.Statement.Quote(idx.Table) + " t", strings.Join(conditions, " AND "), "0", whereArgs
+}
+
+func (idx SearchIndex) quotedColumns(db *gorm.DB, prefix string) string {
+	quoted := make([]string, len(idx.Columns))
+	for i, column := range idx.Columns {
+		quoted[i] = prefix + db.Statement.Quote(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
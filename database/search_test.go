@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type searchItem struct {
+	ID        uint
+	Title     string
+	Body      string
+	DeletedAt *time.Time
+}
+
+var searchItemIndex = SearchIndex{Table: "search_items", Columns: []string{"title", "body"}, SoftDelete: true}
+
+// newSearchTestDB 创建带检索索引的临时 SQLite 数据库
+// 以 -tags sqlite_fts5 构建时使用 FTS5，否则退化为 LIKE
+func newSearchTestDB(t *testing.T) (*gorm.DB, bool) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "search.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&searchItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	deleted := time.Now()
+	items := []searchItem{
+		{Title: "golang", Body: "other"},
+		{Title: "golang tips", Body: "golang golang golang"},
+		{Title: "python", Body: "nothing"},
+		{Title: "golang deleted", Body: "golang", DeletedAt: &deleted},
+		{Title: "100% off", Body: "discount"},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("create items: %v", err)
+	}
+	// 在已有数据上创建索引，FTS5 表从原表重建
+	if err := searchItemIndex.Migrate(db); err != nil {
+		t.Fatalf("migrate search index: %v", err)
+	}
+	return db, db.Migrator().HasTable(searchItemIndex.ftsTable())
+}
+
+// search 返回命中的 ID 和总数
+func search(t *testing.T, db *gorm.DB, query string, offset, limit int) ([]SearchHit, string, int64) {
+	t.Helper()
+	hits, total, err := searchItemIndex.Search(db, query, offset, limit)
+	if err != nil {
+		t.Fatalf("search %q: %v", query, err)
+	}
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return hits, fmt.Sprint(ids), total
+}
+
+// TestSearchIndex_SQLite FTS5 按 bm25 排序；短于 3 个字符的检索词和未启用 FTS5 时使用 LIKE，按主键排序
+func TestSearchIndex_SQLite(t *testing.T) {
+	db, fts := newSearchTestDB(t)
+	t.Logf("SQLite FTS5 enabled: %v", fts)
+
+	hits, ids, total := search(t, db, "golang", 0, 10)
+	if fts {
+		// 第 2 条出现次数更多，排在前面
+		if ids != "[2 1]" || total != 2 || hits[0].Rank <= hits[1].Rank {
+			t.Fatalf("fts search: ids %s, total %d, hits %+v", ids, total, hits)
+		}
+	} else if ids != "[1 2]" || total != 2 || hits[0].Rank != 0 {
+		t.Fatalf("like search: ids %s, total %d, hits %+v", ids, total, hits)
+	}
+
+	// 所有检索词都需要匹配；已删除的记录被排除
+	if _, ids, _ := search(t, db, "golang tips", 0, 10); ids != "[2]" {
+		t.Fatalf("multiple terms: ids %s", ids)
+	}
+	if _, ids, _ := search(t, db, "deleted", 0, 10); ids != "[]" {
+		t.Fatalf("deleted record found: ids %s", ids)
+	}
+
+	// 短检索词退化为 LIKE 子串匹配，不计算相关度
+	hits, ids, total = search(t, db, "go", 0, 10)
+	if ids != "[1 2]" || total != 2 || hits[0].Rank != 0 || hits[1].Rank != 0 {
+		t.Fatalf("short term: ids %s, total %d, hits %+v", ids, total, hits)
+	}
+	// LIKE 通配符按字面匹配
+	if _, ids, _ := search(t, db, "%", 0, 10); ids != "[5]" {
+		t.Fatalf("wildcard term: ids %s", ids)
+	}
+	if _, ids, _ := search(t, db, "_", 0, 10); ids != "[]" {
+		t.Fatalf("underscore term: ids %s", ids)
+	}
+
+	// 分页不影响总数
+	if _, ids, total := search(t, db, "go", 1, 1); ids != "[2]" || total != 2 {
+		t.Fatalf("second page: ids %s, total %d", ids, total)
+	}
+	if _, _, total := search(t, db, "   ", 0, 10); total != 0 {
+		t.Fatalf("blank query: total %d", total)
+	}
+
+	// 修改后的数据可以被检索到（FTS5 通过触发器同步）
+	if err := db.Model(&searchItem{}).Where("id = ?", 3).Update("title", "golang now").Error; err != nil {
+		t.Fatalf("update item: %v", err)
+	}
+	if _, ids, _ := search(t, db, "python", 0, 10); ids != "[]" {
+		t.Fatalf("stale index: ids %s", ids)
+	}
+	if _, _, total := search(t, db, "golang", 0, 10); total != 3 {
+		t.Fatalf("updated item not found: total %d", total)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Golang tips", []string{"go"}, "<mark>Go</mark>lang tips"},
+		{"golang go", []string{"go", "lang"}, "<mark>golang</mark> <mark>go</mark>"},
+		{"<b>go</b> & more", []string{"go"}, "&lt;b&gt;<mark>go</mark>&lt;/b&gt; &amp; more"},
+		{"aaa", []string{"aa"}, "<mark>aaa</mark>"},
+		{"张三丰", []string{"三"}, "张<mark>三</mark>丰"},
+		{"python", []string{"go"}, ""},
+		{"python", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.text, tt.terms); got != tt.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"echo-template/config"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// ContextWithTx 返回携带事务的 context，之后通过 Conn 获取连接的查询都在该事务中执行
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext 返回 context 中的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// Conn 返回 context 中的事务；不在事务中时返回 db.WithContext(ctx)
+// 服务层统一通过 Conn 获取连接，即可自动加入调用方开启的事务
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// TxManager 事务管理器
+// 最外层事务遇到序列化失败或死锁时自动重试；嵌套调用使用保存点，失败只回滚到保存点，由最外层负责重试
+type TxManager struct {
+	db           *gorm.DB
+	MaxRetries   int           // 最外层事务的最大重试次数
+	RetryBackoff time.Duration // 首次重试前的等待时间，之后每次翻倍并加入随机抖动
+}
+
+// NewTxManager 创建事务管理器，重试策略来自配置
+func NewTxManager(db *gorm.DB) *TxManager {
+	cfg := config.AppConfig.Database
+	return &TxManager{
+		db:           db,
+		MaxRetries:   cfg.TxMaxRetries,
+		RetryBackoff: cfg.TxRetryBackoff,
+	}
+}
+
+// Transaction 在事务中执行 fn，fn 收到的 context 携带事务
+// fn 返回错误时回滚；ctx 已在事务中时创建保存点，opts 仅对最外层事务生效
+// fn 可能因重试被执行多次，不应包含事务之外不可重复的副作用
+func (m *TxManager) Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	run := func(tx *gorm.DB) error {
+		return fn(ContextWithTx(ctx, tx))
+	}
+
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx).Transaction(run)
+	}
+
+	backoff := m.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := m.db.WithContext(ctx).Transaction(run, opts...)
+		if err == nil || attempt >= m.MaxRetries || !IsRetryableError(err) {
+			return err
+		}
+
+		wait := backoff + rand.N(backoff+1)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// Transaction 使用全局连接在事务中执行 fn，见 TxManager.Transaction
+func Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	return NewTxManager(DB).Transaction(ctx, fn, opts...)
+}
+
+// IsRetryableError 是否为重试后可能成功的事务错误：序列化失败、死锁、锁等待超时、数据库忙
+func IsRetryableError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// serialization_failure、deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK、ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type txItem struct {
+	ID   uint
+	Name string
+}
+
+// newTestTxManager 使用临时 SQLite 数据库创建事务管理器
+func newTestTxManager(t *testing.T) *TxManager {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&txItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &TxManager{db: db, MaxRetries: 3, RetryBackoff: time.Millisecond}
+}
+
+// itemNames 返回已提交的记录名称
+func itemNames(t *testing.T, m *TxManager) []string {
+	t.Helper()
+	var names []string
+	if err := m.db.Model(&txItem{}).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("query items: %v", err)
+	}
+	return names
+}
+
+func createItem(ctx context.Context, m *TxManager, name string) error {
+	return Conn(ctx, m.db).Create(&txItem{Name: name}).Error
+}
+
+// TestTransaction_NestedRollback 内层事务失败只回滚到保存点，外层的其余修改照常提交
+func TestTransaction_NestedRollback(t *testing.T) {
+	m := newTestTxManager(t)
+	errInner := errors.New("inner failed")
+
+	err := m.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := createItem(ctx, m, "outer"); err != nil {
+			return err
+		}
+		err := m.Transaction(ctx, func(ctx context.Context) error {
+			if err := createItem(ctx, m, "inner"); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("nested transaction returned %v, want %v", err, errInner)
+		}
+		return m.Transaction(ctx, func(ctx context.Context) error {
+			return createItem(ctx, m, "after")
+		})
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+	if got := fmt.Sprint(itemNames(t, m)); got != "[outer after]" {
+		t.Fatalf("committed items = %s, want [outer after]", got)
+	}
+
+	// 外层失败时内层已成功的保存点一并回滚
+	err = m.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := m.Transaction(ctx, func(ctx context.Context) error {
+			return createItem(ctx, m, "discarded")
+		}); err != nil {
+			return err
+		}
+		return errInner
+	})
+	if !errors.Is(err, errInner) {
+		t.Fatalf("transaction returned %v, want %v", err, errInner)
+	}
+	if got := fmt.Sprint(itemNames(t, m)); got != "[outer after]" {
+		t.Fatalf("committed items = %s, want [outer after]", got)
+	}
+}
+
+// TestTransaction_Retry 可重试的错误使最外层事务回滚后重新执行，直到成功或达到最大重试次数
+func TestTransaction_Retry(t *testing.T) {
+	m := newTestTxManager(t)
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	attempts := 0
+	err := m.Transaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if err := createItem(ctx, m, fmt.Sprintf("attempt %d", attempts)); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return fmt.Errorf("write: %w", busy)
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("transaction: err %v after %d attempts", err, attempts)
+	}
+	// 失败的尝试已回滚
+	if got := fmt.Sprint(itemNames(t, m)); got != "[attempt 3]" {
+		t.Fatalf("committed items = %s, want [attempt 3]", got)
+	}
+
+	// 超过最大重试次数时返回最后一次的错误
+	attempts = 0
+	err = m.Transaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return busy
+	})
+	if !errors.Is(err, busy) || attempts != m.MaxRetries+1 {
+		t.Fatalf("transaction: err %v after %d attempts, want %d", err, attempts, m.MaxRetries+1)
+	}
+
+	// 不可重试的错误和嵌套事务中的错误不重试，由最外层决定
+	for name, fn := range map[string]func(ctx context.Context) error{
+		"not retryable": func(ctx context.Context) error {
+			attempts++
+			return errors.New("failed")
+		},
+		"nested": func(ctx context.Context) error {
+			attempts++
+			return m.Transaction(ctx, func(ctx context.Context) error { return errors.New("failed") })
+		},
+	} {
+		attempts = 0
+		if err := m.Transaction(context.Background(), fn); err == nil || attempts != 1 {
+			t.Fatalf("%s: err %v after %d attempts, want 1", name, err, attempts)
+		}
+	}
+}
+
+// TestTransaction_RetryCanceled context 取消后不再等待重试
+func TestTransaction_RetryCanceled(t *testing.T) {
+	m := newTestTxManager(t)
+	m.RetryBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	err := m.Transaction(ctx, func(ctx context.Context) error {
+		attempts++
+		return sqlite3.Error{Code: sqlite3.ErrLocked}
+	})
+	if !IsRetryableError(err) || attempts != 1 {
+		t.Fatalf("transaction: err %v after %d attempts, want 1", err, attempts)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"postgres serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"postgres deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"mysql lock wait timeout", &mysql.MySQLError{Number: 1205}, true},
+		{"mysql duplicate entry", &mysql.MySQLError{Number: 1062}, false},
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"sqlite locked", sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{"sqlite constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"wrapped", fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"other", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableError(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryableError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
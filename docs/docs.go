@@ -24,9 +24,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/users": {
+        "/v1/api-keys": {
             "get": {
-                "description": "获取所有用户信息",
+                "description": "分页获取未吊销的 API Key，管理员可以看到全部密钥，其他调用方只能看到自己的密钥",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,12 +34,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
+                ],
+                "summary": "获取 API Key 列表",
+                "operationId": "listAPIKeys",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取用户列表",
                 "responses": {
                     "200": {
-                        "description": "成功返回用户列表",
+                        "description": "成功返回 API Key 列表",
                         "schema": {
                             "allOf": [
                                 {
@@ -49,26 +66,64 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.User"
-                                            }
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/utils.PageResult"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "items": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/models.APIKey"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             },
             "post": {
-                "description": "创建新用户",
+                "description": "为用户或服务账号生成 API Key，响应中的 key 为完整密钥，只返回这一次；通过 API Key 调用时新密钥的权限范围不能超出调用方密钥",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,23 +131,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "创建用户",
+                "summary": "创建 API Key",
+                "operationId": "createAPIKey",
                 "parameters": [
                     {
-                        "description": "用户信息",
-                        "name": "user",
+                        "type": "string",
+                        "description": "幂等键，重试时携带相同的值不会重复创建",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "API Key 信息",
+                        "name": "api_key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/controllers.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "成功创建用户",
+                        "description": "成功创建 API Key",
                         "schema": {
                             "allOf": [
                                 {
@@ -102,7 +164,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.User"
+                                            "$ref": "#/definitions/controllers.CreatedAPIKey"
                                         }
                                     }
                                 }
@@ -115,18 +177,38 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "无权为其他用户或服务账号创建，或权限范围超出调用方密钥",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/v1/users/{id}": {
+        "/v1/api-keys/{id}": {
             "get": {
-                "description": "根据ID获取用户详细信息",
+                "description": "根据ID获取 API Key，不包含密钥本身",
                 "consumes": [
                     "application/json"
                 ],
@@ -134,14 +216,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "获取单个用户",
+                "summary": "获取单个 API Key",
+                "operationId": "getAPIKey",
                 "parameters": [
                     {
                         "type": "integer",
                         "example": 1,
-                        "description": "用户ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -149,7 +232,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功返回用户信息",
+                        "description": "成功返回 API Key",
                         "schema": {
                             "allOf": [
                                 {
@@ -159,7 +242,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/models.User"
+                                            "$ref": "#/definitions/models.APIKey"
                                         }
                                     }
                                 }
@@ -172,16 +255,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "API Key 不存在",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             },
             "put": {
-                "description": "更新用户信息",
+                "description": "更新名称、权限范围和过期时间，请求体需携带获取密钥时返回的 version，版本不一致时返回 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,31 +292,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "更新用户",
+                "summary": "更新 API Key",
+                "operationId": "updateAPIKey",
                 "parameters": [
                     {
                         "type": "integer",
                         "example": 1,
-                        "description": "用户ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "用户信息",
-                        "name": "user",
+                        "description": "API Key 信息",
+                        "name": "api_key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/controllers.UpdateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功更新用户",
+                        "description": "成功更新 API Key",
                         "schema": {
                             "allOf": [
                                 {
@@ -223,10 +327,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/models.User"
-                                            }
+                                            "$ref": "#/definitions/models.APIKey"
                                         }
                                     }
                                 }
@@ -239,16 +340,63 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限，或权限范围超出调用方密钥",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API Key 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "版本冲突，data.current_version 为当前版本号",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             },
             "delete": {
-                "description": "根据ID删除用户",
+                "description": "吊销 API Key，立即失效",
                 "consumes": [
                     "application/json"
                 ],
@@ -256,14 +404,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "删除用户",
+                "summary": "吊销 API Key",
+                "operationId": "deleteAPIKey",
                 "parameters": [
                     {
                         "type": "integer",
                         "example": 1,
-                        "description": "用户ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -271,110 +420,2856 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功删除用户",
+                        "description": "成功吊销 API Key",
                         "schema": {
                             "$ref": "#/definitions/utils.SuccessResponse"
                         }
                     },
+                    "204": {
+                        "description": "成功吊销 API Key（Prefer: envelope=false 或路由组关闭了响应包装）"
+                    },
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API Key 不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "models.User": {
-            "description": "用户信息",
-            "type": "object",
-            "required": [
-                "email",
-                "username"
-            ],
-            "properties": {
-                "created_at": {
-                    "description": "创建时间",
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
-                },
-                "email": {
-                    "description": "邮箱",
-                    "type": "string",
-                    "example": "john@example.com"
-                },
-                "id": {
-                    "description": "用户ID",
-                    "type": "integer",
-                    "example": 1
-                },
-                "name": {
-                    "description": "姓名",
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "updated_at": {
-                    "description": "更新时间",
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
-                },
-                "username": {
-                    "description": "用户名",
-                    "type": "string",
-                    "example": "john_doe"
-                }
-            }
-        },
-        "utils.ErrorResponse": {
-            "description": "错误响应",
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 400
-                },
-                "msg": {
-                    "type": "string",
-                    "example": "操作失败"
-                }
-            }
-        },
-        "utils.Response": {
-            "description": "通用API响应",
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 200
                 },
-                "data": {},
-                "msg": {
-                    "type": "string",
-                    "example": "success"
-                }
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "utils.SuccessResponse": {
-            "description": "成功响应",
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 200
-                },
-                "msg": {
-                    "type": "string",
-                    "example": "操作成功"
-                }
-            }
+        "/v1/audit-logs": {
+            "get": {
+                "description": "按操作人、API Key、动作、表、记录、请求ID和时间范围分页查询审计日志（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit-logs"
+                ],
+                "summary": "查询审计日志",
+                "operationId": "listAuditLogs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "操作人用户ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "使用的 API Key ID",
+                        "name": "api_key_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "force_delete"
+                        ],
+                        "type": "string",
+                        "description": "动作",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "users",
+                        "description": "表名",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "记录主键",
+                        "name": "record_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "请求ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始时间（RFC3339，包含）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339，不包含）",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回审计日志",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/utils.PageResult"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "items": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/models.AuditLog"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "无权访问",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/auth/me": {
+            "get": {
+                "description": "获取访问令牌或用户的 API Key 所属的用户，服务账号的 API Key 没有对应的用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "获取当前登录的用户",
+                "operationId": "getCurrentUser",
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或令牌无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "服务账号没有对应的用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "身份提供方登录后跳转到此接口（OIDC_\u003cNAME\u003e_REDIRECT_URL）。校验 state 与 Cookie 中的登录状态一致，用授权码和 PKCE 校验码换取 ID Token，校验签名、签发者、受众、有效期和 nonce 后映射为本地用户：已关联的身份直接登录，已验证的邮箱关联已有的普通用户（管理员须显式关联），否则按配置自动创建用户；通过关联接口发起时关联到发起关联的用户。成功后签发访问令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "身份提供方登录回调",
+                "operationId": "completeOIDCLogin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "corp",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "授权码",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发起登录时生成的 state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "身份提供方返回的错误，例如 access_denied",
+                        "name": "error",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功，返回访问令牌",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.LoginResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "state 无效或登录已过期",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "身份提供方拒绝登录或 ID Token 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "用户已停用或不允许自动注册",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "身份提供方不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "邮箱已被未关联的用户或管理员使用，或身份已关联其他用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "无法访问身份提供方",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/oidc/{provider}/link": {
+            "post": {
+                "description": "已登录的用户发起关联：与登录相同，生成 state、nonce 和 PKCE 校验码并加密保存在 Cookie 中，返回身份提供方的授权地址。在同一浏览器中打开 auth_url，身份提供方登录后回调接口将该身份关联到当前用户（不比较邮箱）并签发访问令牌。管理员不会按邮箱自动关联，须通过此接口关联",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "关联身份提供方",
+                "operationId": "linkOIDCIdentity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "corp",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "返回身份提供方的授权地址",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controllers.OIDCLink"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或令牌无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "服务账号没有对应的用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "身份提供方不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "无法访问身份提供方",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/auth/oidc/{provider}/login": {
+            "get": {
+                "description": "使用授权码 + PKCE 流程登录：生成 state、nonce 和 PKCE 校验码，加密后保存在 Cookie 中，并跳转到身份提供方的授权地址。需要在浏览器中打开",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "跳转到身份提供方登录",
+                "operationId": "startOIDCLogin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "corp",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "跳转到身份提供方的授权地址",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "身份提供方的授权地址"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "身份提供方不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "无法访问身份提供方",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/providers": {
+            "get": {
+                "description": "获取已配置的 OIDC 身份提供方，未配置时为空列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "获取身份提供方列表",
+                "operationId": "listLoginProviders",
+                "responses": {
+                    "200": {
+                        "description": "成功返回身份提供方列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/controllers.LoginProvider"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users": {
+            "get": {
+                "description": "获取所有用户信息，trashed=with 包含已删除用户，trashed=only 仅返回已删除用户（仅管理员）。已弃用，请使用分页的 v2 接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "获取用户列表",
+                "operationId": "listUsers",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "enum": [
+                            "with",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "已删除用户的查询范围",
+                        "name": "trashed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.User"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限，或非管理员查询已删除用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建新用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "创建用户",
+                "operationId": "createUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时携带相同的值不会重复创建",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "成功创建用户",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "用户名或邮箱已存在，或相同幂等键的请求正在处理",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "幂等键已被用于不同的请求",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/batch": {
+            "post": {
+                "description": "在一个事务中按顺序批量创建、更新、删除用户，返回逐项结果。\nmode=atomic（默认）时任一项失败即全部回滚并返回 422，data 中未失败的项状态为 424；\nmode=partial 时失败项单独回滚，其余操作照常提交。单次操作数受 BATCH_MAX_ITEMS 限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "批量操作用户",
+                "operationId": "batchUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时携带相同的值不会重复执行",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "批量操作",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BatchUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "执行完成，逐项结果见 data.items",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "仅管理员可以批量操作用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "请求体过大或操作数量超过上限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "原子模式下有操作失败，所有操作已回滚",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/export": {
+            "get": {
+                "description": "流式导出用户表，不包含密码等敏感字段；导出文件可修改后通过导入接口重新上传（仅管理员）",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "导出用户",
+                "operationId": "exportUsers",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "导出格式，默认 csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "with",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "已删除用户的导出范围",
+                        "name": "trashed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "仅管理员可以导出用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/import": {
+            "post": {
+                "description": "上传 CSV（需包含表头）或 JSONL 文件批量导入用户，逐行校验并返回每行的结果（仅管理员）。\n不带 id 的行创建用户；带 id 的行更新对应用户，必须同时提供 version。导出文件中的其他列会被忽略。\nmode=atomic（默认）时任一行失败即全部回滚并返回 422；mode=partial 时仅跳过失败的行",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "导入用户",
+                "operationId": "importUsers",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV 或 JSONL 文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "description": "文件格式，默认根据扩展名判断",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "导入模式，默认 atomic",
+                        "name": "mode",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导入完成，逐行结果见 data.lines",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.ImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或文件格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "仅管理员可以导入用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "文件过大或行数超过上限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "原子模式下有行失败，所有数据已回滚",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.ImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/search": {
+            "get": {
+                "description": "按用户名、邮箱、姓名全文检索未删除的用户，结果按相关度排序；highlights 中匹配部分以 \u003cmark\u003e 标记，其余部分已做 HTML 转义",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "检索用户",
+                "operationId": "searchUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "检索词，多个词以空格分隔",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回检索结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/utils.PageResult"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "items": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/services.UserSearchResult"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/{id}": {
+            "get": {
+                "description": "根据ID获取用户详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "获取单个用户",
+                "operationId": "getUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新用户信息，请求体需携带获取用户时返回的 version，版本不一致时返回 409（非管理员只能修改自己）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "更新用户",
+                "operationId": "updateUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取用户时返回的 ETag，不匹配时拒绝更新",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功更新用户",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "只能修改自己，或 API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "版本冲突，data.current_version 为当前版本号",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "用户已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "根据ID删除用户（软删除），管理员可通过 force=true 彻底删除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "删除用户",
+                "operationId": "deleteUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "彻底删除（仅管理员）",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取用户时返回的 ETag，不匹配时拒绝删除",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功删除用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.SuccessResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "成功删除用户（Prefer: envelope=false 或路由组关闭了响应包装）"
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "只能删除自己、无权彻底删除用户，或 API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "用户已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/{id}/restore": {
+            "post": {
+                "description": "恢复已删除的用户（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "恢复用户",
+                "operationId": "restoreUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功恢复用户",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "仅管理员可以恢复用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "用户未被删除，或用户名/邮箱已被占用",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v2/users": {
+            "get": {
+                "description": "分页获取用户，trashed=with 包含已删除用户，trashed=only 仅返回已删除用户（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users-v2"
+                ],
+                "summary": "获取用户列表",
+                "operationId": "v2ListUsers",
+                "parameters": [
+                    {
+                        "enum": [
+                            "with",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "已删除用户的查询范围",
+                        "name": "trashed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/utils.PageResult"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "items": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/v2.UserResponse"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限，或非管理员查询已删除用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建新用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users-v2"
+                ],
+                "summary": "创建用户",
+                "operationId": "v2CreateUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时携带相同的值不会重复创建",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v2.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "成功创建用户",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/v2.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "用户名或邮箱已存在，或相同幂等键的请求正在处理",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "幂等键已被用于不同的请求",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v2/users/{id}": {
+            "get": {
+                "description": "根据ID获取用户详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users-v2"
+                ],
+                "summary": "获取单个用户",
+                "operationId": "v2GetUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功返回用户信息",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/v2.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新用户信息，请求体需携带获取用户时返回的 version，版本不一致时返回 409（非管理员只能修改自己）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users-v2"
+                ],
+                "summary": "更新用户",
+                "operationId": "v2UpdateUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取用户时返回的 ETag，不匹配时拒绝更新",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v2.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功更新用户",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/v2.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "只能修改自己，或 API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "版本冲突，data.current_version 为当前版本号",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.ErrorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "用户已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "根据ID删除用户（软删除），管理员可通过 force=true 彻底删除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users-v2"
+                ],
+                "summary": "删除用户",
+                "operationId": "v2DeleteUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "彻底删除（仅管理员）",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "获取用户时返回的 ETag，不匹配时拒绝删除",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功删除用户",
+                        "schema": {
+                            "$ref": "#/definitions/utils.SuccessResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "成功删除用户（Prefer: envelope=false 或路由组关闭了响应包装）"
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证或 API Key 无效",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "只能删除自己、无权彻底删除用户，或 API Key 缺少权限",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "用户已被修改",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "controllers.BatchUsersRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "atomic：任一项失败全部回滚（默认）；partial：允许部分成功",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ],
+                    "example": "atomic"
+                },
+                "operations": {
+                    "description": "按顺序执行的操作",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BatchUserOperation"
+                    }
+                }
+            }
+        },
+        "controllers.CreateAPIKeyRequest": {
+            "description": "创建 API Key 请求；未指定所有者时属于调用方自己，只有管理员可以为其他用户或服务账号创建",
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "过期时间，未指定时按 API_KEY_DEFAULT_TTL 设置",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "nightly-export"
+                },
+                "role": {
+                    "description": "服务账号的角色，默认 user",
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "admin"
+                    ],
+                    "example": "user"
+                },
+                "scopes": {
+                    "description": "权限范围：\u003c资源\u003e:read、\u003c资源\u003e:write 或 *",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "service_account": {
+                    "description": "所属服务账号，与 user_id 二选一",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "billing-sync"
+                },
+                "user_id": {
+                    "description": "所属用户ID",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 1
+                }
+            }
+        },
+        "controllers.CreatedAPIKey": {
+            "description": "新创建的 API Key，key 为完整密钥，只在创建时返回这一次",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "删除时间（未删除时为 null）",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                },
+                "expires_at": {
+                    "description": "过期时间，null 为不过期",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "description": "完整密钥",
+                    "type": "string",
+                    "example": "etk_3f9a1c2b_Jx2bX0sQ4vTq"
+                },
+                "last_used_at": {
+                    "description": "最近使用时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "last_used_ip": {
+                    "description": "最近使用的客户端 IP",
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "nightly-export"
+                },
+                "prefix": {
+                    "description": "可见前缀，用于识别密钥",
+                    "type": "string",
+                    "example": "etk_3f9a1c2b"
+                },
+                "role": {
+                    "description": "服务账号的角色：user | admin，用户的密钥使用用户的角色",
+                    "type": "string",
+                    "example": "user"
+                },
+                "scopes": {
+                    "description": "权限范围",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "service_account": {
+                    "description": "所属服务账号，用户的密钥为空",
+                    "type": "string",
+                    "example": "billing-sync"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "所属用户ID，服务账号的密钥为 null",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 1
+                },
+                "version": {
+                    "description": "版本号（乐观锁，更新时必须携带）",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.LoginProvider": {
+            "description": "可用于登录的身份提供方，浏览器打开 login_url 开始登录",
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "description": "展示名称",
+                    "type": "string",
+                    "example": "公司 SSO"
+                },
+                "login_url": {
+                    "description": "登录地址",
+                    "type": "string",
+                    "example": "/api/v1/auth/oidc/corp/login"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "corp"
+                }
+            }
+        },
+        "controllers.OIDCLink": {
+            "description": "在发起关联的浏览器中打开 auth_url，身份提供方登录后回调完成关联",
+            "type": "object",
+            "properties": {
+                "auth_url": {
+                    "description": "身份提供方的授权地址",
+                    "type": "string",
+                    "example": "https://sso.example.com/authorize?client_id=echo-template\u0026state=..."
+                }
+            }
+        },
+        "controllers.UpdateAPIKeyRequest": {
+            "description": "更新 API Key 请求，所有者、角色和密钥本身不能修改",
+            "type": "object",
+            "required": [
+                "name",
+                "scopes",
+                "version"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "过期时间，null 为不过期",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "nightly-export"
+                },
+                "scopes": {
+                    "description": "权限范围",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "version": {
+                    "description": "获取密钥时返回的版本号",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.APIKey": {
+            "description": "API Key，只保存可见前缀和密钥的哈希，完整密钥只在创建时返回一次",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "删除时间（未删除时为 null）",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                },
+                "expires_at": {
+                    "description": "过期时间，null 为不过期",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "description": "最近使用时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true,
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "last_used_ip": {
+                    "description": "最近使用的客户端 IP",
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "nightly-export"
+                },
+                "prefix": {
+                    "description": "可见前缀，用于识别密钥",
+                    "type": "string",
+                    "example": "etk_3f9a1c2b"
+                },
+                "role": {
+                    "description": "服务账号的角色：user | admin，用户的密钥使用用户的角色",
+                    "type": "string",
+                    "example": "user"
+                },
+                "scopes": {
+                    "description": "权限范围",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "service_account": {
+                    "description": "所属服务账号，用户的密钥为空",
+                    "type": "string",
+                    "example": "billing-sync"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "所属用户ID，服务账号的密钥为 null",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 1
+                },
+                "version": {
+                    "description": "版本号（乐观锁，更新时必须携带）",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.AuditLog": {
+            "description": "审计日志，记录每次数据变更的操作人和新旧值",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "动作：create | update | delete | restore | force_delete",
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor_id": {
+                    "description": "操作人用户ID，非用户操作时为 null",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 1
+                },
+                "actor_type": {
+                    "description": "操作人类型：user | service_account | anonymous | system",
+                    "type": "string",
+                    "example": "user"
+                },
+                "api_key_id": {
+                    "description": "使用的 API Key ID，未使用 API Key 时为 null",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 1
+                },
+                "changes": {
+                    "description": "变更内容：{\"字段\": {\"old\": 旧值, \"new\": 新值}}",
+                    "type": "object"
+                },
+                "created_at": {
+                    "description": "操作时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "日志ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "description": "客户端 IP",
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "record_id": {
+                    "description": "主键",
+                    "type": "string",
+                    "example": "1"
+                },
+                "request_id": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "3b1f0c9e"
+                },
+                "table": {
+                    "description": "表名",
+                    "type": "string",
+                    "example": "users"
+                }
+            }
+        },
+        "models.User": {
+            "description": "用户信息",
+            "type": "object",
+            "required": [
+                "email",
+                "username"
+            ],
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "删除时间（未删除时为 null）",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                },
+                "email": {
+                    "description": "邮箱（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "description": "ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "locale": {
+                    "description": "偏好的语言，认证后用于响应消息",
+                    "type": "string",
+                    "example": "zh-CN"
+                },
+                "name": {
+                    "description": "姓名",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "description": "角色：user | admin（不可通过接口修改）",
+                    "type": "string",
+                    "example": "user"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "username": {
+                    "description": "用户名（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john_doe"
+                },
+                "version": {
+                    "description": "版本号（乐观锁，更新时必须携带）",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "services.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "操作后的用户信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "用户ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "description": "在请求中的位置",
+                    "type": "integer",
+                    "example": 0
+                },
+                "op": {
+                    "description": "操作类型（无法解析的导入行为空）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "status": {
+                    "description": "与单个接口一致的 HTTP 状态码",
+                    "type": "integer",
+                    "example": 201
+                },
+                "success": {
+                    "description": "是否成功",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "services.BatchOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BatchOpCreate",
+                "BatchOpUpdate",
+                "BatchOpDelete"
+            ]
+        },
+        "services.BatchResult": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "是否为原子模式",
+                    "type": "boolean",
+                    "example": true
+                },
+                "failed": {
+                    "description": "失败数",
+                    "type": "integer",
+                    "example": 0
+                },
+                "items": {
+                    "description": "逐项结果，顺序与请求一致",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.BatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "成功数",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "services.BatchUserOperation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "用户ID（update/delete）",
+                    "type": "integer",
+                    "example": 1
+                },
+                "op": {
+                    "description": "操作类型",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "user": {
+                    "description": "用户信息（create/update）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "services.ImportLineResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "操作后的用户信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "用户ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "description": "在请求中的位置",
+                    "type": "integer",
+                    "example": 0
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "op": {
+                    "description": "操作类型（无法解析的导入行为空）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "status": {
+                    "description": "与单个接口一致的 HTTP 状态码",
+                    "type": "integer",
+                    "example": 201
+                },
+                "success": {
+                    "description": "是否成功",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "services.ImportResult": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "是否为原子模式",
+                    "type": "boolean",
+                    "example": true
+                },
+                "failed": {
+                    "description": "失败行数",
+                    "type": "integer",
+                    "example": 0
+                },
+                "lines": {
+                    "description": "逐行结果，顺序与文件一致",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ImportLineResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "成功行数",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "services.LoginResult": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "访问令牌，通过 Authorization: Bearer 传递",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "created": {
+                    "description": "是否为首次登录时自动创建的用户",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expires_at": {
+                    "description": "过期时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "expires_in": {
+                    "description": "有效期（秒）",
+                    "type": "integer",
+                    "example": 43200
+                },
+                "token_type": {
+                    "description": "令牌类型",
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "description": "登录的用户",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "services.UserSearchResult": {
+            "type": "object",
+            "properties": {
+                "highlights": {
+                    "description": "命中的字段，匹配部分以 \u003cmark\u003e 标记",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "username": "\u003cmark\u003ejoh\u003c/mark\u003en_doe"
+                    }
+                },
+                "rank": {
+                    "description": "相关度，越大越相关",
+                    "type": "number",
+                    "example": 0.42
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "utils.ErrorResponse": {
+            "description": "错误响应",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "data": {},
+                "msg": {
+                    "type": "string",
+                    "example": "操作失败"
+                }
+            }
+        },
+        "utils.PageResult": {
+            "description": "分页数据",
+            "type": "object",
+            "properties": {
+                "items": {},
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "utils.Response": {
+            "description": "通用API响应",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {},
+                "msg": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "utils.SuccessResponse": {
+            "description": "成功响应",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "msg": {
+                    "type": "string",
+                    "example": "操作成功"
+                }
+            }
+        },
+        "v2.CreateUserRequest": {
+            "description": "v2 创建用户请求",
+            "type": "object",
+            "required": [
+                "email",
+                "username"
+            ],
+            "properties": {
+                "display_name": {
+                    "description": "显示名称",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "email": {
+                    "description": "邮箱（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "locale": {
+                    "description": "偏好的语言",
+                    "type": "string",
+                    "example": "zh-CN"
+                },
+                "username": {
+                    "description": "用户名（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john_doe"
+                }
+            }
+        },
+        "v2.UpdateUserRequest": {
+            "description": "v2 更新用户请求，未携带的字段会被清空",
+            "type": "object",
+            "required": [
+                "email",
+                "username",
+                "version"
+            ],
+            "properties": {
+                "display_name": {
+                    "description": "显示名称",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "email": {
+                    "description": "邮箱（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "locale": {
+                    "description": "偏好的语言",
+                    "type": "string",
+                    "example": "zh-CN"
+                },
+                "username": {
+                    "description": "用户名（未删除用户中唯一）",
+                    "type": "string",
+                    "example": "john_doe"
+                },
+                "version": {
+                    "description": "获取用户时返回的版本号",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "v2.UserResponse": {
+            "description": "v2 用户信息",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "deleted_at": {
+                    "description": "删除时间（仅已删除用户返回）",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                },
+                "display_name": {
+                    "description": "显示名称",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "email": {
+                    "description": "邮箱",
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "description": "ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "locale": {
+                    "description": "偏好的语言",
+                    "type": "string",
+                    "example": "zh-CN"
+                },
+                "role": {
+                    "description": "角色：user | admin",
+                    "type": "string",
+                    "example": "user"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string",
+                    "example": "john_doe"
+                },
+                "version": {
+                    "description": "版本号（乐观锁，更新时必须携带）",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API Key，格式为 \u003c前缀\u003e_\u003c标识\u003e_\u003c密钥\u003e，例如 etk_3f9a1c2b_...",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Bearer 令牌：Authorization: Bearer \u003cAPI Key 或 OIDC 登录获得的访问令牌\u003e",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -382,9 +3277,9 @@ const docTemplate = `{
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
-	Host:             "localhost:1323",
+	Host:             "",
 	BasePath:         "/api",
-	Schemes:          []string{"http", "https"},
+	Schemes:          []string{},
 	Title:            "Echo Template API",
 	Description:      "基于 Echo 框架的 MVC 架构 API 文档",
 	InfoInstanceName: "swagger",
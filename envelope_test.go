@@ -0,0 +1,95 @@
+package main
+
+import (
+	"echo-template/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// TestEnvelopePreference Prefer: envelope=false 返回不包装的资源，错误响应始终包装
+func TestEnvelopePreference(t *testing.T) {
+	e, _ := newServer()
+	apiKey := createAdminAPIKey(t)
+
+	rec := serveRequest(e, http.MethodPost, "/api/v1/users",
+		[]byte(`{"username":"envelope_bare","email":"envelope_bare@example.com","name":"Bare"}`),
+		middleware.HeaderAPIKey, apiKey, echo.HeaderContentType, echo.MIMEApplicationJSON,
+		"Prefer", "respond-async, envelope=false")
+	var bare map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &bare); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if bare["username"] != "envelope_bare" || bare["data"] != nil || bare["msg"] != nil {
+		t.Fatalf("expected a bare user, got %s", rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "envelope=false" {
+		t.Fatalf("Preference-Applied = %q", got)
+	}
+	if vary := strings.Join(rec.Header().Values(echo.HeaderVary), ","); !strings.Contains(vary, "Prefer") {
+		t.Fatalf("Vary = %q", vary)
+	}
+	target := "/api/v1/users/" + strconv.FormatFloat(bare["id"].(float64), 'f', -1, 64)
+
+	// 显式请求包装，以及不带 Prefer 时按配置包装且不返回 Preference-Applied
+	for _, prefer := range []string{"envelope=true", ""} {
+		rec = serveRequest(e, http.MethodGet, target, nil, "Prefer", prefer)
+		var wrapped struct {
+			Code int                    `json:"code"`
+			Data map[string]interface{} `json:"data"`
+			Msg  string                 `json:"msg"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &wrapped); err != nil || rec.Code != http.StatusOK ||
+			wrapped.Code != http.StatusOK || wrapped.Msg == "" || wrapped.Data["username"] != "envelope_bare" {
+			t.Fatalf("Prefer %q: status %d, body %q", prefer, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Preference-Applied"); got != prefer {
+			t.Fatalf("Prefer %q: Preference-Applied = %q", prefer, got)
+		}
+	}
+
+	// 其他格式同样返回不包装的资源
+	rec = serveRequest(e, http.MethodGet, target, nil, echo.HeaderAccept, "application/msgpack", "Prefer", "envelope=false")
+	var packed map[string]interface{}
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &packed); err != nil || rec.Code != http.StatusOK ||
+		packed["username"] != "envelope_bare" || packed["data"] != nil {
+		t.Fatalf("msgpack: status %d, %v, %v", rec.Code, packed, err)
+	}
+
+	// 分页列表返回分页结果本身
+	rec = serveRequest(e, http.MethodGet, "/api/v2/users?page_size=1", nil, "Prefer", "envelope=false")
+	var page struct {
+		Items    []map[string]interface{} `json:"items"`
+		Total    int64                    `json:"total"`
+		PageSize int                      `json:"page_size"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || rec.Code != http.StatusOK ||
+		len(page.Items) != 1 || page.Total == 0 || page.PageSize != 1 {
+		t.Fatalf("list: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	// 错误响应不受 Prefer 影响
+	rec = serveRequest(e, http.MethodGet, "/api/v1/users/999999", nil, "Prefer", "envelope=false")
+	var notFound struct {
+		Code *int   `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &notFound); err != nil || rec.Code != http.StatusNotFound ||
+		notFound.Code == nil || *notFound.Code != http.StatusNotFound || notFound.Msg == "" {
+		t.Fatalf("not found: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	// 没有数据的成功响应不包装时返回 204
+	rec = serveRequest(e, http.MethodDelete, target, nil, middleware.HeaderAPIKey, apiKey, "Prefer", "envelope=false")
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatalf("delete: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "envelope=false" {
+		t.Fatalf("delete: Preference-Applied = %q", got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"echo-template/generator"
+	"echo-template/openapi"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+)
+
+var generateCommand = &cli.Command{
+	Name:  "generate",
+	Usage: "代码生成",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "resource",
+			Usage:     "生成资源的模型、服务、控制器、路由和测试",
+			ArgsUsage: "[--label 名称] <Name> field:type[:required,unique,index]...",
+			Description: "字段类型：string、text、int、int64、uint、float64、bool、time\n" +
+				"参数需放在资源名之前，例如：generate resource --label 产品 Product name:string:required sku:string:required,unique price:float64",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "label", Usage: "用于注释和消息的名称，默认为类型名"},
+				&cli.BoolFlag{Name: "force", Usage: "覆盖已存在的文件"},
+				&cli.BoolFlag{Name: "test", Value: true, Usage: "生成控制器测试"},
+				&cli.StringFlag{Name: "dir", Value: ".", Usage: "项目根目录"},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() < 2 {
+					return errors.New("usage: generate resource <Name> field:type[:options]...")
+				}
+
+				resource, err := generator.NewResource(c.Args().First(), c.String("label"), c.Args().Tail())
+				if err != nil {
+					return err
+				}
+				files, err := generator.Generate(resource, generator.Options{
+					Root:  c.String("dir"),
+					Force: c.Bool("force"),
+					Test:  c.Bool("test"),
+				})
+				if err != nil {
+					return err
+				}
+
+				for _, file := range files {
+					fmt.Fprintln(c.App.Writer, "  write", file)
+				}
+				fmt.Fprintln(c.App.Writer, "\nnext: regenerate the Swagger docs with swag init -g server.go -o docs --parseDependency --parseInternal")
+				return nil
+			},
+		},
+		{
+			Name:  "client",
+			Usage: "根据 OpenAPI 文档生成 Go 客户端的类型和接口方法",
+			Description: "文档来自已生成的 Swagger 文档，修改注释后先重新运行 swag init；\n" +
+				"每个接口都需要 @ID 注释，用作客户端的方法名",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: filepath.Join("client", "client_gen.go"), Usage: "输出文件"},
+				&cli.StringFlag{Name: "package", Value: "client", Usage: "包名"},
+			},
+			Action: func(c *cli.Context) error {
+				doc, err := openapi.Document()
+				if err != nil {
+					return err
+				}
+				src, err := generator.GenerateClient(doc, c.String("package"))
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(c.String("output"), src, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintln(c.App.Writer, "  write", c.String("output"))
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,631 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 统一响应格式的 schema，由客户端运行时处理，不生成类型
+const (
+	envelopeSchema = "utils.Response"
+	successSchema  = "utils.SuccessResponse"
+	errorSchema    = "utils.ErrorResponse"
+	pageSchema     = "utils.PageResult" // 生成为 Page[T]
+)
+
+// 客户端方法中已使用的变量名，参数重名时加上 Param 后缀
+var clientReservedNames = []string{"ctx", "req", "opts", "params", "body", "form", "result", "err", "c"}
+
+// 请求方法在生成代码中的顺序
+var clientMethodOrder = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// clientFile 生成的客户端代码
+type clientFile struct {
+	Package    string
+	Title      string
+	Version    string
+	Types      []*clientType
+	Operations []*clientOperation
+}
+
+// clientType 由 components.schemas 生成的类型
+type clientType struct {
+	Name   string
+	Doc    []string
+	Enum   []clientEnumValue // 不为空时为字符串枚举
+	Fields []clientField
+}
+
+type clientEnumValue struct {
+	Name  string
+	Value string
+}
+
+type clientField struct {
+	Name string
+	Type string
+	Tag  string
+	Doc  string
+}
+
+// clientOperation 接口方法
+type clientOperation struct {
+	Name       string
+	Summary    string
+	Deprecated bool
+	Method     string // GET
+	HTTPMethod string // http.MethodGet
+	Path       string // 文档中的路径
+	PathExpr   string // 拼接路径的 Go 表达式
+	Args       []string
+	Params     *clientParams
+	Form       *clientParams
+	Body       string // JSON 请求体类型
+	Result     string // 返回值类型，为空时只返回 error
+	ResultKind string // none | json | file
+	Pointer    bool   // 返回 *Result
+	Zero       string // 出错时返回的值
+}
+
+// clientParams 查询参数和请求头（或 multipart 表单）组成的结构体
+type clientParams struct {
+	Name   string
+	Fields []clientField
+	Setter []string // 设置请求参数的语句
+}
+
+type clientGen struct {
+	doc     *openapi3.T
+	names   map[string]string // schema 名称 -> Go 类型名
+	structs map[string]bool   // Go 类型名是否为结构体
+}
+
+// GenerateClient 根据 OpenAPI 文档生成客户端的类型和接口方法，返回格式化后的源码
+// 每个操作都必须有 operationId（swag 注释中的 @ID），用作方法名
+func GenerateClient(doc *openapi3.T, pkg string) ([]byte, error) {
+	g := &clientGen{doc: doc, names: map[string]string{}, structs: map[string]bool{"Page": true}}
+	file := &clientFile{Package: pkg}
+	if doc.Info != nil {
+		file.Title = doc.Info.Title
+		file.Version = doc.Info.Version
+	}
+
+	if err := g.nameSchemas(); err != nil {
+		return nil, err
+	}
+	for _, schemaName := range g.schemaNames() {
+		t, err := g.schemaType(schemaName, doc.Components.Schemas[schemaName].Value)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", schemaName, err)
+		}
+		file.Types = append(file.Types, t)
+	}
+	sort.Slice(file.Types, func(i, j int) bool { return file.Types[i].Name < file.Types[j].Name })
+
+	if doc.Paths != nil {
+		paths := doc.Paths.InMatchingOrder()
+		sort.Strings(paths)
+		for _, path := range paths {
+			item := doc.Paths.Value(path)
+			for _, method := range clientMethodOrder {
+				op := item.GetOperation(method)
+				if op == nil {
+					continue
+				}
+				operation, err := g.operation(method, path, op)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+				file.Operations = append(file.Operations, operation)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, op := range file.Operations {
+		if seen[op.Name] {
+			return nil, fmt.Errorf("duplicate operation id %q", op.Name)
+		}
+		seen[op.Name] = true
+	}
+
+	var body bytes.Buffer
+	if err := templates.ExecuteTemplate(&body, "client.go.tmpl", file); err != nil {
+		return nil, err
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by \"go run . generate client\"; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"context", "encoding/json", "net/http", "net/url", "strconv", "time"} {
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if strings.Contains(body.String(), name+".") {
+			fmt.Fprintf(&src, "\t%q\n", imp)
+		}
+	}
+	src.WriteString(")\n\n")
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format client: %w", err)
+	}
+	return formatted, nil
+}
+
+// schemaNames 需要生成类型的 schema，按名称排序
+func (g *clientGen) schemaNames() []string {
+	var names []string
+	if g.doc.Components == nil {
+		return nil
+	}
+	for name := range g.doc.Components.Schemas {
+		switch name {
+		case envelopeSchema, successSchema, errorSchema, pageSchema:
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nameSchemas 为 schema 分配 Go 类型名：去掉包名前缀，例如 models.User 为 User；重名时保留包名，例如 ModelsUser
+func (g *clientGen) nameSchemas() error {
+	count := map[string]int{}
+	short := map[string]string{}
+	for _, schemaName := range g.schemaNames() {
+		name := schemaName[strings.LastIndex(schemaName, ".")+1:]
+		short[schemaName] = pascal(words(name))
+		count[short[schemaName]]++
+	}
+	for schemaName, name := range short {
+		if count[name] > 1 || name == "Page" || name == "Client" {
+			name = pascal(words(strings.ReplaceAll(schemaName, ".", "_")))
+		}
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("schema %q cannot be converted to a Go type name", schemaName)
+		}
+		g.names[schemaName] = name
+		schema := g.doc.Components.Schemas[schemaName].Value
+		g.structs[name] = schema != nil && len(schema.Enum) == 0 && schema.Type.Is(openapi3.TypeObject)
+	}
+	return nil
+}
+
+func (g *clientGen) schemaType(schemaName string, s *openapi3.Schema) (*clientType, error) {
+	t := &clientType{Name: g.names[schemaName], Doc: docLines(s.Description)}
+	if len(s.Enum) > 0 {
+		if !s.Type.Is(openapi3.TypeString) {
+			return nil, fmt.Errorf("only string enums are supported")
+		}
+		for _, value := range s.Enum {
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid enum value %v", value)
+			}
+			t.Enum = append(t.Enum, clientEnumValue{Name: t.Name + pascal(words(str)), Value: str})
+		}
+		return t, nil
+	}
+	if !s.Type.Is(openapi3.TypeObject) {
+		return nil, fmt.Errorf("unsupported schema type %v", s.Type.Slice())
+	}
+
+	properties := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+	for _, name := range properties {
+		required := slices.Contains(s.Required, name)
+		fieldType, err := g.goType(s.Properties[name], required)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", name, err)
+		}
+		tag := name
+		if !required {
+			tag += ",omitempty"
+		}
+		t.Fields = append(t.Fields, clientField{
+			Name: fieldName(name),
+			Type: fieldType,
+			Tag:  fmt.Sprintf("`json:%q`", tag),
+			Doc:  oneLine(propertyDescription(s.Properties[name])),
+		})
+	}
+	return t, nil
+}
+
+// goType schema 对应的 Go 类型；非必填的结构体和可为 null 的标量使用指针
+func (g *clientGen) goType(ref *openapi3.SchemaRef, required bool) (string, error) {
+	if ref == nil {
+		return "json.RawMessage", nil
+	}
+	if ref.Ref != "" {
+		schemaName := strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+		if schemaName == pageSchema {
+			return g.pointer("Page[json.RawMessage]", required), nil
+		}
+		name, ok := g.names[schemaName]
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", ref.Ref)
+		}
+		return g.pointer(name, required), nil
+	}
+
+	s := ref.Value
+	if len(s.AllOf) > 0 {
+		return g.allOfType(s, required)
+	}
+
+	var types []string
+	nullable := false
+	if s.Type != nil {
+		for _, typ := range s.Type.Slice() {
+			if typ == openapi3.TypeNull {
+				nullable = true
+				continue
+			}
+			types = append(types, typ)
+		}
+	}
+	if len(types) != 1 {
+		return "json.RawMessage", nil
+	}
+
+	var goType string
+	switch types[0] {
+	case openapi3.TypeString:
+		switch s.Format {
+		case "date-time":
+			goType = "time.Time"
+		case "binary":
+			return "[]byte", nil
+		default:
+			goType = "string"
+		}
+	case openapi3.TypeInteger:
+		goType = "int64"
+		if s.Format == "int32" {
+			goType = "int32"
+		}
+	case openapi3.TypeNumber:
+		goType = "float64"
+	case openapi3.TypeBoolean:
+		goType = "bool"
+	case openapi3.TypeArray:
+		elem, err := g.goType(s.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case openapi3.TypeObject:
+		if s.AdditionalProperties.Schema != nil {
+			elem, err := g.goType(s.AdditionalProperties.Schema, true)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		return "map[string]interface{}", nil
+	default:
+		return "json.RawMessage", nil
+	}
+	if nullable {
+		goType = "*" + goType
+	}
+	return goType, nil
+}
+
+// allOfType swag 为带描述的引用生成只有一项的 allOf，为 Response{data=...} 这类组合生成引用加覆盖属性的 allOf
+// 分页结果覆盖 items 时生成 Page[T]，其他组合使用第一项的类型
+func (g *clientGen) allOfType(s *openapi3.Schema, required bool) (string, error) {
+	base := s.AllOf[0]
+	if base.Ref == "#/components/schemas/"+pageSchema && len(s.AllOf) > 1 && s.AllOf[1].Value != nil {
+		if items := s.AllOf[1].Value.Properties["items"]; items != nil && items.Value != nil && items.Value.Items != nil {
+			elem, err := g.goType(items.Value.Items, true)
+			if err != nil {
+				return "", err
+			}
+			return g.pointer("Page["+elem+"]", required), nil
+		}
+	}
+	return g.goType(base, required)
+}
+
+func (g *clientGen) pointer(goType string, required bool) string {
+	name, _, _ := strings.Cut(goType, "[")
+	if !required && g.structs[name] {
+		return "*" + goType
+	}
+	return goType
+}
+
+func (g *clientGen) operation(method, path string, op *openapi3.Operation) (*clientOperation, error) {
+	if op.OperationID == "" {
+		return nil, fmt.Errorf("missing operation id, add @ID to the swag annotations")
+	}
+	name := pascal(words(op.OperationID))
+	o := &clientOperation{
+		Name:       name,
+		Summary:    oneLine(op.Summary),
+		Deprecated: op.Deprecated,
+		Method:     method,
+		HTTPMethod: "http.Method" + strings.ToUpper(method[:1]) + strings.ToLower(method[1:]),
+		Path:       path,
+	}
+
+	pathParams := map[string]*openapi3.Parameter{}
+	params := &clientParams{Name: name + "Params"}
+	for _, ref := range op.Parameters {
+		p := ref.Value
+		switch p.In {
+		case openapi3.ParameterInPath:
+			pathParams[p.Name] = p
+		case openapi3.ParameterInQuery, openapi3.ParameterInHeader:
+			if err := g.addParam(params, p.Name, p.In, p.Schema, p.Description); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pathExpr, args, err := g.pathExpr(path, pathParams)
+	if err != nil {
+		return nil, err
+	}
+	o.PathExpr = pathExpr
+	o.Args = args
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		content := op.RequestBody.Value.Content
+		switch {
+		case content.Get("application/json") != nil:
+			bodyType, err := g.goType(content.Get("application/json").Schema, true)
+			if err != nil {
+				return nil, err
+			}
+			o.Body = bodyType
+			o.Args = append(o.Args, "body "+bodyType)
+		case content.Get("multipart/form-data") != nil:
+			form, err := g.form(name+"Form", content.Get("multipart/form-data").Schema)
+			if err != nil {
+				return nil, err
+			}
+			o.Form = form
+			o.Args = append(o.Args, "form "+form.Name)
+		default:
+			return nil, fmt.Errorf("unsupported request content type")
+		}
+	}
+	if len(params.Fields) > 0 {
+		o.Params = params
+		o.Args = append(o.Args, "params *"+params.Name)
+	}
+
+	if err := g.result(o, op); err != nil {
+		return nil, err
+	}
+	o.Zero = "nil"
+	if o.ResultKind == "json" && !o.Pointer && !nilable(o.Result) {
+		o.Zero = "result"
+	}
+	return o, nil
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// pathExpr 拼接路径的表达式，例如 "/v1/users/" + url.PathEscape(strconv.FormatInt(id, 10))
+func (g *clientGen) pathExpr(path string, params map[string]*openapi3.Parameter) (string, []string, error) {
+	var parts, args []string
+	last := 0
+	for _, match := range pathParamPattern.FindAllStringSubmatchIndex(path, -1) {
+		name := path[match[2]:match[3]]
+		p, ok := params[name]
+		if !ok {
+			return "", nil, fmt.Errorf("path parameter %q is not documented", name)
+		}
+		goType, err := g.goType(p.Schema, true)
+		if err != nil {
+			return "", nil, err
+		}
+		value, err := formatExpr(goType, argName(name))
+		if err != nil {
+			return "", nil, fmt.Errorf("path parameter %q: %w", name, err)
+		}
+		parts = append(parts, strconv.Quote(path[last:match[0]]), "url.PathEscape("+value+")")
+		args = append(args, argName(name)+" "+goType)
+		last = match[1]
+	}
+	if last < len(path) {
+		parts = append(parts, strconv.Quote(path[last:]))
+	}
+	return strings.Join(parts, " + "), args, nil
+}
+
+// addParam 添加查询参数或请求头，零值不发送
+func (g *clientGen) addParam(params *clientParams, name, in string, schema *openapi3.SchemaRef, description string) error {
+	goType, err := g.goType(schema, true)
+	if err != nil {
+		return err
+	}
+	field := fieldName(name)
+	target := "req.query"
+	if in == openapi3.ParameterInHeader {
+		target = "req.header"
+	}
+
+	var setter string
+	switch goType {
+	case "bool":
+		setter = fmt.Sprintf("if params.%s {\n%s.Set(%q, \"true\")\n}", field, target, name)
+	case "[]string":
+		setter = fmt.Sprintf("for _, v := range params.%s {\n%s.Add(%q, v)\n}", field, target, name)
+	default:
+		value, err := formatExpr(goType, "params."+field)
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", name, err)
+		}
+		zero := `""`
+		if goType != "string" {
+			zero = "0"
+		}
+		setter = fmt.Sprintf("if params.%s != %s {\n%s.Set(%q, %s)\n}", field, zero, target, name, value)
+	}
+
+	params.Fields = append(params.Fields, clientField{Name: field, Type: goType, Doc: oneLine(description)})
+	params.Setter = append(params.Setter, setter)
+	return nil
+}
+
+// form multipart 表单：文件字段为 Upload，其他字段为字符串
+func (g *clientGen) form(name string, ref *openapi3.SchemaRef) (*clientParams, error) {
+	form := &clientParams{Name: name}
+	s := ref.Value
+	properties := make([]string, 0, len(s.Properties))
+	for property := range s.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	var parts []string
+	for _, property := range properties {
+		p := s.Properties[property].Value
+		field := fieldName(property)
+		doc := oneLine(p.Description)
+		switch {
+		case p.Type.Is(openapi3.TypeString) && p.Format == "binary":
+			form.Fields = append(form.Fields, clientField{Name: field, Type: "Upload", Doc: doc})
+			parts = append(parts, fmt.Sprintf("{name: %q, file: &form.%s},", property, field))
+		case p.Type.Is(openapi3.TypeString):
+			form.Fields = append(form.Fields, clientField{Name: field, Type: "string", Doc: doc})
+			parts = append(parts, fmt.Sprintf("{name: %q, value: form.%s},", property, field))
+		default:
+			return nil, fmt.Errorf("unsupported form field %q", property)
+		}
+	}
+	form.Setter = parts
+	return form, nil
+}
+
+// result 由最小的 2xx 响应确定返回值：统一响应格式中 data 的类型、文件或无返回值
+func (g *clientGen) result(o *clientOperation, op *openapi3.Operation) error {
+	o.ResultKind = "none"
+	if op.Responses == nil {
+		return nil
+	}
+	var success *openapi3.Response
+	for code := 200; code < 300 && success == nil; code++ {
+		if ref := op.Responses.Status(code); ref != nil {
+			success = ref.Value
+		}
+	}
+	if success == nil || len(success.Content) == 0 {
+		return nil
+	}
+
+	for mediaType := range success.Content {
+		if !strings.Contains(mediaType, "json") {
+			o.ResultKind = "file"
+			o.Result = "File"
+			o.Pointer = true
+			return nil
+		}
+	}
+
+	media := success.Content.Get("application/json")
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+	schema := media.Schema
+	switch {
+	case schema.Ref == "#/components/schemas/"+successSchema:
+		return nil
+	case schema.Ref == "#/components/schemas/"+envelopeSchema:
+		o.ResultKind = "json"
+		o.Result = "json.RawMessage"
+		return nil
+	case len(schema.Value.AllOf) > 1 && schema.Value.AllOf[0].Ref == "#/components/schemas/"+envelopeSchema:
+		data := schema.Value.AllOf[1].Value.Properties["data"]
+		resultType, err := g.goType(data, true)
+		if err != nil {
+			return err
+		}
+		o.ResultKind = "json"
+		o.Result = resultType
+		name, _, _ := strings.Cut(resultType, "[")
+		o.Pointer = g.structs[name]
+		return nil
+	default:
+		return fmt.Errorf("success response is not wrapped in %s", envelopeSchema)
+	}
+}
+
+// nilable 零值为 nil 的类型
+func nilable(goType string) bool {
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") ||
+		strings.HasPrefix(goType, "*") || goType == "json.RawMessage"
+}
+
+// formatExpr 将参数值格式化为字符串的表达式
+func formatExpr(goType, expr string) (string, error) {
+	switch goType {
+	case "string":
+		return expr, nil
+	case "int64":
+		return "strconv.FormatInt(" + expr + ", 10)", nil
+	case "int32":
+		return "strconv.FormatInt(int64(" + expr + "), 10)", nil
+	case "float64":
+		return "strconv.FormatFloat(" + expr + ", 'f', -1, 64)", nil
+	case "bool":
+		return "strconv.FormatBool(" + expr + ")", nil
+	case "time.Time":
+		return expr + ".Format(time.RFC3339)", nil
+	}
+	return "", fmt.Errorf("unsupported parameter type %s", goType)
+}
+
+// fieldName JSON 字段名或参数名对应的 Go 字段名，例如 page_size 为 PageSize、If-Match 为 IfMatch
+func fieldName(name string) string {
+	return pascal(words(name))
+}
+
+// argName 路径参数对应的参数名
+func argName(name string) string {
+	arg := camel(words(name))
+	if slices.Contains(clientReservedNames, arg) || token.IsKeyword(arg) {
+		arg += "Param"
+	}
+	return arg
+}
+
+// propertyDescription 属性说明，引用的 schema 带描述时 swag 生成 allOf
+func propertyDescription(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return ""
+	}
+	return ref.Value.Description
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func docLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
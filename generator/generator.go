@@ -0,0 +1,405 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// fieldType 字段类型定义
+type fieldType struct {
+	goType  string
+	gorm    string // 额外的 gorm 标签
+	example string // Swagger 示例值，为空时不生成
+	format  string // Swagger 格式，为空时不生成
+	sample  string // 生成测试时使用的 JSON 值
+}
+
+// 支持的字段类型
+var fieldTypes = map[string]fieldType{
+	"string":  {goType: "string", example: "example", sample: `"example"`},
+	"text":    {goType: "string", gorm: "type:text", example: "example", sample: `"example"`},
+	"int":     {goType: "int", example: "1", sample: "1"},
+	"int64":   {goType: "int64", example: "1", sample: "1"},
+	"uint":    {goType: "uint", example: "1", sample: "1"},
+	"float64": {goType: "float64", example: "9.99", sample: "9.99"},
+	"bool":    {goType: "bool", example: "true", sample: "true"},
+	"time":    {goType: "time.Time", example: "2024-01-01T00:00:00Z", format: "date-time", sample: `"2024-01-01T00:00:00Z"`},
+}
+
+// 类型别名
+var fieldTypeAliases = map[string]string{
+	"float":    "float64",
+	"datetime": "time",
+}
+
+// models.Model 中已有的字段
+var reservedFields = []string{"id", "created_at", "updated_at", "deleted_at", "version"}
+
+// Field 资源字段
+type Field struct {
+	Name     string // Go 字段名，例如 UnitPrice
+	Column   string // JSON 字段名和列名，例如 unit_price
+	Type     string // Go 类型
+	Required bool
+	Unique   bool // 在未删除的记录中唯一
+	Index    bool
+
+	fieldType fieldType
+}
+
+// Tag 结构体标签
+func (f Field) Tag() string {
+	tags := []string{fmt.Sprintf(`json:"%s"`, f.Column)}
+	if f.fieldType.example != "" {
+		tags = append(tags, fmt.Sprintf(`example:"%s"`, f.fieldType.example))
+	}
+	if f.fieldType.format != "" {
+		tags = append(tags, fmt.Sprintf(`format:"%s"`, f.fieldType.format))
+	}
+	// 指针字段未设置时为 null，在文档中标记为可空
+	if strings.HasPrefix(f.Type, "*") {
+		tags = append(tags, `extensions:"x-nullable"`)
+	}
+
+	var gorm []string
+	if f.Required {
+		gorm = append(gorm, "not null")
+	}
+	if f.fieldType.gorm != "" {
+		gorm = append(gorm, f.fieldType.gorm)
+	}
+	if f.Index && !f.Unique {
+		gorm = append(gorm, "index")
+	}
+	if len(gorm) > 0 {
+		tags = append(tags, fmt.Sprintf(`gorm:"%s"`, strings.Join(gorm, ";")))
+	}
+
+	if f.Required {
+		tags = append(tags, `binding:"required"`)
+	}
+	return strings.Join(tags, " ")
+}
+
+// Sample 生成测试时使用的 JSON 值
+func (f Field) Sample() string {
+	return f.fieldType.sample
+}
+
+// ParseField 解析字段定义：name:type[:option,...]，选项为 required、unique、index
+// 例如 unit_price:float64:required、sku:string:required,unique
+func ParseField(spec string) (Field, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return Field{}, fmt.Errorf("invalid field %q, expected name:type[:options]", spec)
+	}
+
+	nameWords := words(parts[0])
+	if len(nameWords) == 0 {
+		return Field{}, fmt.Errorf("invalid field name %q", parts[0])
+	}
+	field := Field{
+		Name:   pascal(nameWords),
+		Column: strings.Join(nameWords, "_"),
+	}
+	if slices.Contains(reservedFields, field.Column) {
+		return Field{}, fmt.Errorf("field %q is already defined by models.Model", field.Column)
+	}
+
+	typeName := strings.ToLower(parts[1])
+	if alias, ok := fieldTypeAliases[typeName]; ok {
+		typeName = alias
+	}
+	ft, ok := fieldTypes[typeName]
+	if !ok {
+		return Field{}, fmt.Errorf("unsupported type %q for field %q", parts[1], parts[0])
+	}
+	field.fieldType = ft
+
+	if len(parts) == 3 {
+		for _, option := range strings.Split(parts[2], ",") {
+			switch strings.TrimSpace(option) {
+			case "required":
+				field.Required = true
+			case "unique":
+				field.Unique = true
+			case "index":
+				field.Index = true
+			case "":
+			default:
+				return Field{}, fmt.Errorf("unknown option %q for field %q", option, parts[0])
+			}
+		}
+	}
+
+	field.Type = ft.goType
+	// 非必填的时间字段使用指针，未设置时为 null 而不是零值时间
+	if field.Type == "time.Time" && !field.Required {
+		field.Type = "*time.Time"
+	}
+	return field, nil
+}
+
+// Resource 要生成的资源
+type Resource struct {
+	Name       string // 类型名，例如 OrderItem
+	Var        string // 变量名，例如 orderItem
+	Plural     string // 复数类型名，例如 OrderItems
+	PluralVar  string // 复数变量名，例如 orderItems
+	File       string // 文件名前缀，例如 order_item
+	Table      string // 表名，例如 order_items
+	Path       string // 路由路径和 Swagger 标签，例如 order-items
+	Label      string // 用于注释和中文消息的名称，例如 订单项
+	English    string // 英文消息中的名称，例如 order item
+	MessageKey string // 名称的消息键，例如 resources.order_item
+	Receiver   string // 服务和控制器接收者名称的首字母，例如 o
+	Fields     []Field
+}
+
+// NewResource 根据名称和字段定义创建资源，label 为空时使用类型名
+func NewResource(name, label string, fieldSpecs []string) (*Resource, error) {
+	nameWords := words(name)
+	if len(nameWords) == 0 {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	if !isLetter(nameWords[0][0]) {
+		return nil, fmt.Errorf("resource name %q must start with a letter", name)
+	}
+	pluralWords := pluralWords(nameWords)
+
+	r := &Resource{
+		Name:       pascal(nameWords),
+		Var:        camel(nameWords),
+		Plural:     pascal(pluralWords),
+		PluralVar:  camel(pluralWords),
+		File:       strings.Join(nameWords, "_"),
+		Table:      strings.Join(pluralWords, "_"),
+		Path:       strings.Join(pluralWords, "-"),
+		Label:      label,
+		English:    strings.Join(nameWords, " "),
+		MessageKey: "resources." + strings.Join(nameWords, "_"),
+		Receiver:   nameWords[0][:1],
+	}
+	if r.Label == "" {
+		r.Label = r.Name
+	}
+
+	seen := map[string]bool{}
+	for _, spec := range fieldSpecs {
+		field, err := ParseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("duplicate field %q", field.Column)
+		}
+		seen[field.Column] = true
+		r.Fields = append(r.Fields, field)
+	}
+	if len(r.Fields) == 0 {
+		return nil, errors.New("at least one field is required")
+	}
+	return r, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// HasTime 是否有 time.Time 字段
+func (r *Resource) HasTime() bool {
+	return slices.ContainsFunc(r.Fields, func(f Field) bool { return strings.HasSuffix(f.Type, "time.Time") })
+}
+
+// UniqueFields 需要唯一索引的字段
+func (r *Resource) UniqueFields() []Field {
+	var fields []Field
+	for _, f := range r.Fields {
+		if f.Unique {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SampleJSON 生成测试时使用的请求体
+func (r *Resource) SampleJSON() string {
+	pairs := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		pairs[i] = fmt.Sprintf(`"%s":%s`, f.Column, f.Sample())
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Options 生成选项
+type Options struct {
+	Root  string // 项目根目录
+	Force bool   // 覆盖已存在的文件
+	Test  bool   // 生成控制器测试
+}
+
+// Generate 生成资源的模型、服务、控制器和测试，在服务接口、迁移列表和 v1 路由中登记，并在消息目录中添加资源名称
+// 先在内存中完成全部生成和修改，任何一步失败都不会写入文件；返回写入的文件
+func Generate(r *Resource, opts Options) ([]string, error) {
+	files := map[string][]byte{}
+	render := func(name, path string) error {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, name, r); err != nil {
+			return err
+		}
+		full := filepath.Join(opts.Root, path)
+		if _, err := os.Stat(full); err == nil && !opts.Force {
+			return fmt.Errorf("%s already exists, use --force to overwrite", path)
+		}
+		files[path] = buf.Bytes()
+		return nil
+	}
+
+	generated := [][2]string{
+		{"model.go.tmpl", filepath.Join("app", "models", r.File+".go")},
+		{"service.go.tmpl", filepath.Join("app", "services", r.File+"_service.go")},
+		{"controller.go.tmpl", filepath.Join("app", "controllers", r.File+"_controller.go")},
+	}
+	if opts.Test {
+		generated = append(generated, [2]string{"controller_test.go.tmpl", filepath.Join("app", "controllers", r.File+"_controller_test.go")})
+	}
+	for _, g := range generated {
+		if err := render(g[0], g[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	patches := []struct {
+		path  string
+		patch func(src string) (string, error)
+	}{
+		{filepath.Join("app", "services", "interfaces.go"), r.patchInterfaces},
+		{filepath.Join("app", "models", "models.go"), r.patchModels},
+		{filepath.Join("app", "routes", "v1", "routes.go"), r.patchRoutes},
+		{filepath.Join("i18n", "locales", "zh-CN.yaml"), r.patchCatalog(r.Label)},
+		{filepath.Join("i18n", "locales", "en-US.yaml"), r.patchCatalog(r.English)},
+	}
+	for _, p := range patches {
+		src, err := os.ReadFile(filepath.Join(opts.Root, p.path))
+		if err != nil {
+			return nil, err
+		}
+		patched, err := p.patch(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.path, err)
+		}
+		if patched != string(src) {
+			files[p.path] = []byte(patched)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path, src := range files {
+		if filepath.Ext(path) != ".go" {
+			paths = append(paths, path)
+			continue
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			return nil, fmt.Errorf("format %s: %w", path, err)
+		}
+		files[path] = formatted
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if err := os.WriteFile(filepath.Join(opts.Root, path), files[path], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// patchCatalog 在消息目录末尾追加资源名称
+func (r *Resource) patchCatalog(name string) func(src string) (string, error) {
+	return func(src string) (string, error) {
+		if strings.Contains(src, "\n"+r.MessageKey+":") {
+			return src, nil
+		}
+		if !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		return src + r.MessageKey + ": " + strconv.Quote(name) + "\n", nil
+	}
+}
+
+// patchInterfaces 在 interfaces.go 末尾追加服务接口
+func (r *Resource) patchInterfaces(src string) (string, error) {
+	if strings.Contains(src, "type "+r.Name+"ServiceInterface interface") {
+		return src, nil
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "interface.go.tmpl", r); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(src, "\n") + "\n\n" + buf.String(), nil
+}
+
+// patchModels 在 models.All() 的迁移列表末尾登记模型，在 models.ScopeResources() 末尾登记 API Key 可授权的资源
+func (r *Resource) patchModels(src string) (string, error) {
+	src, err := appendToList(src, "All", "&"+r.Name+"{},")
+	if err != nil {
+		return "", err
+	}
+	return appendToList(src, "ScopeResources", strconv.Quote(r.Table)+",")
+}
+
+// appendToList 在函数 name 返回的切片字面量末尾追加一项
+func appendToList(src, name, entry string) (string, error) {
+	start := strings.Index(src, "func "+name+"()")
+	if start < 0 {
+		return "", fmt.Errorf("func %s() not found", name)
+	}
+	end := strings.Index(src[start:], "\n\t}\n")
+	if end < 0 {
+		return "", fmt.Errorf("list in func %s() not found", name)
+	}
+	end += start
+	if strings.Contains(src[start:end], entry) {
+		return src, nil
+	}
+	return src[:end] + "\n\t\t" + entry + src[end:], nil
+}
+
+// patchRoutes 在 RegisterRoutes 末尾注册路由
+func (r *Resource) patchRoutes(src string) (string, error) {
+	if strings.Contains(src, "controllers.New"+r.Name+"Controller()") {
+		return src, nil
+	}
+	start := strings.Index(src, "func RegisterRoutes(")
+	if start < 0 {
+		return "", errors.New("func RegisterRoutes not found")
+	}
+	// 函数体在第一个位于行首的右花括号处结束
+	end := strings.Index(src[start:], "\n}")
+	if end < 0 {
+		return "", errors.New("end of func RegisterRoutes not found")
+	}
+	end += start
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "routes.go.tmpl", r); err != nil {
+		return "", err
+	}
+	return src[:end] + "\n" + strings.TrimRight(buf.String(), "\n") + src[end:], nil
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 生成 Go 标识符时保持全大写的缩写
+var initialisms = map[string]string{
+	"id": "ID", "ip": "IP", "url": "URL", "uri": "URI", "uuid": "UUID",
+	"api": "API", "http": "HTTP", "json": "JSON", "html": "HTML", "sku": "SKU",
+}
+
+// words 将 snake_case、kebab-case、camelCase 和 PascalCase 拆分为小写单词
+func words(s string) []string {
+	var result []string
+	var current []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			// userID -> user id，HTTPServer -> http server
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return result
+}
+
+// pascal OrderItem
+func pascal(parts []string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		if initialism, ok := initialisms[part]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// camel orderItem
+func camel(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[0] + pascal(parts[1:])
+}
+
+// plural 英文复数形式，只处理常见规则
+func plural(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// pluralWords 将最后一个单词变为复数
+func pluralWords(parts []string) []string {
+	result := append([]string(nil), parts...)
+	result[len(result)-1] = plural(result[len(result)-1])
+	return result
+}
@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"sigs.k8s.io/yaml"
+)
+
+// Args 消息的插值参数，消息中以 {{.Name}} 引用；Count 同时决定复数形式，
+// 值为 Key 的参数按同一语言渲染后再插入，例如 {"Resource": Key("resources.user")}
+type Args map[string]interface{}
+
+// Key 作为插值参数的消息键
+type Key string
+
+// Message 消息键和插值参数，用于在写出响应时才按请求的语言渲染的消息
+type Message struct {
+	Key  string
+	Args Args
+}
+
+// CountArg 决定复数形式的插值参数名
+const CountArg = "Count"
+
+// 消息目录，每种语言一个文件：locales/<语言标签>.yaml
+//
+//go:embed locales/*.yaml
+var locales embed.FS
+
+var (
+	defaultLanguage = language.MustParse("zh-CN")
+	bundle          = newBundle(defaultLanguage)
+)
+
+func newBundle(defaultLanguage language.Tag) *goi18n.Bundle {
+	b := goi18n.NewBundle(defaultLanguage)
+	b.RegisterUnmarshalFunc("yaml", func(data []byte, v interface{}) error {
+		return yaml.Unmarshal(data, v)
+	})
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		if _, err := b.LoadMessageFileFS(locales, "locales/"+entry.Name()); err != nil {
+			panic(err)
+		}
+	}
+	return b
+}
+
+// Languages 消息目录支持的语言
+func Languages() []language.Tag {
+	return bundle.LanguageTags()
+}
+
+// DefaultLanguage 请求未指定语言或指定的语言都不受支持时使用的语言
+func DefaultLanguage() language.Tag {
+	return defaultLanguage
+}
+
+// SetDefaultLanguage 设置默认语言，须为消息目录支持的语言，在启动服务之前调用
+func SetDefaultLanguage(lang string) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return err
+	}
+	for _, supported := range Languages() {
+		if supported == tag {
+			defaultLanguage = tag
+			bundle = newBundle(tag)
+			return nil
+		}
+	}
+	return fmt.Errorf("no message catalog for language %q", lang)
+}
+
+// Localizer 按一种语言渲染消息
+type Localizer struct {
+	tag       language.Tag
+	localizer *goi18n.Localizer
+}
+
+// NewLocalizer 从按优先级排列的语言偏好中选择支持的语言，每项可以是语言标签或 Accept-Language 的值；
+// 空字符串和无法解析的值被忽略，都不受支持时使用默认语言
+func NewLocalizer(preferences ...string) *Localizer {
+	var tags []language.Tag
+	for _, preference := range preferences {
+		if strings.TrimSpace(preference) == "" {
+			continue
+		}
+		parsed, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, parsed...)
+	}
+
+	tag := defaultLanguage
+	if len(tags) > 0 {
+		supported := Languages()
+		if _, index, confidence := language.NewMatcher(supported).Match(tags...); confidence != language.No {
+			tag = supported[index]
+		}
+	}
+	return &Localizer{tag: tag, localizer: goi18n.NewLocalizer(bundle, tag.String())}
+}
+
+// Language 渲染消息使用的语言，用作 Content-Language
+func (l *Localizer) Language() language.Tag {
+	return l.tag
+}
+
+// T 渲染消息键对应的消息，key 不在消息目录中时原样返回
+func (l *Localizer) T(key string, args ...Args) string {
+	var data Args
+	if len(args) > 0 {
+		data = make(Args)
+		for _, a := range args {
+			for name, value := range a {
+				if k, ok := value.(Key); ok {
+					value = l.T(string(k))
+				}
+				data[name] = value
+			}
+		}
+	}
+	message, err := l.localizer.Localize(&goi18n.LocalizeConfig{
+		MessageID:    key,
+		TemplateData: data,
+		PluralCount:  data[CountArg],
+	})
+	if err != nil {
+		return key
+	}
+	return message
+}
+
+// Message 渲染 Message
+func (l *Localizer) Message(m Message) string {
+	return l.T(m.Key, m.Args)
+}
+
+// T 按默认语言渲染消息，用于命令行等没有请求的场景
+func T(key string, args ...Args) string {
+	return NewLocalizer().T(key, args...)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"echo-template/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// localizedResponse 响应消息和 Content-Language
+func localizedResponse(t *testing.T, handler http.Handler, method, target string, body []byte, headers ...string) (string, string) {
+	t.Helper()
+	rec := serveRequest(handler, method, target, body, headers...)
+	var response struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s %s: status %d, body %q", method, target, rec.Code, rec.Body.String())
+	}
+	return response.Msg, rec.Header().Get("Content-Language")
+}
+
+// TestLocaleNegotiation 响应消息的语言依次取自查询参数、用户偏好、Accept-Language 和默认语言
+func TestLocaleNegotiation(t *testing.T) {
+	e, _ := newServer()
+	apiKey := createAdminAPIKey(t)
+
+	rec := serveRequest(e, http.MethodPost, "/api/v1/users",
+		[]byte(`{"username":"locale_en","email":"locale_en@example.com","locale":"en-US"}`),
+		middleware.HeaderAPIKey, apiKey, echo.HeaderContentType, echo.MIMEApplicationJSON)
+	var created struct {
+		Data struct {
+			ID uint `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	target := "/api/v1/users/" + strconv.FormatUint(uint64(created.Data.ID), 10)
+	userKey := createUserAPIKey(t, created.Data.ID)
+
+	tests := []struct {
+		name     string
+		target   string
+		headers  []string
+		msg      string
+		language string
+	}{
+		{"default", target, nil, "获取用户信息成功", "zh-CN"},
+		{"accept language", target, []string{"Accept-Language", "en-US"}, "Fetched the user", "en-US"},
+		{"closest match", target, []string{"Accept-Language", "en-GB"}, "Fetched the user", "en-US"},
+		{"q values", target, []string{"Accept-Language", "en;q=0.5, fr, zh;q=0.8"}, "获取用户信息成功", "zh-CN"},
+		{"unsupported", target, []string{"Accept-Language", "fr, de"}, "获取用户信息成功", "zh-CN"},
+		{"query parameter", target + "?lang=en", []string{"Accept-Language", "zh-CN"}, "Fetched the user", "en-US"},
+		{"user locale", target, []string{middleware.HeaderAPIKey, userKey, "Accept-Language", "zh-CN"}, "Fetched the user", "en-US"},
+		{"query over user locale", target + "?lang=zh-CN", []string{middleware.HeaderAPIKey, userKey}, "获取用户信息成功", "zh-CN"},
+		{"error", "/api/v1/users/999999", []string{"Accept-Language", "en-US"}, "The user does not exist", "en-US"},
+	}
+	for _, tt := range tests {
+		msg, language := localizedResponse(t, e, http.MethodGet, tt.target, nil, tt.headers...)
+		if msg != tt.msg || language != tt.language {
+			t.Errorf("%s: msg %q (%s), want %q (%s)", tt.name, msg, language, tt.msg, tt.language)
+		}
+	}
+}
+
+// TestLocalePlurals Count 参数决定复数形式，没有复数形式的语言使用同一条消息
+func TestLocalePlurals(t *testing.T) {
+	e, _ := newServer()
+	apiKey := createAdminAPIKey(t)
+
+	batch := func(language string, n int) []byte {
+		ops := make([]map[string]interface{}, n)
+		for i := range ops {
+			username := "locale_plural_" + language + "_" + strconv.Itoa(n) + "_" + strconv.Itoa(i)
+			ops[i] = map[string]interface{}{
+				"op":   "create",
+				"user": map[string]string{"username": username, "email": username + "@example.com"},
+			}
+		}
+		body, _ := json.Marshal(map[string]interface{}{"mode": "partial", "operations": ops})
+		return body
+	}
+
+	tests := []struct {
+		count    int
+		language string
+		msg      string
+	}{
+		{1, "en-US", "Batch completed: 1 operation, 0 failed"},
+		{2, "en-US", "Batch completed: 2 operations, 0 failed"},
+		{1, "zh-CN", "批量操作完成，共 1 项，失败 0 项"},
+		{2, "zh-CN", "批量操作完成，共 2 项，失败 0 项"},
+	}
+	for _, tt := range tests {
+		msg, language := localizedResponse(t, e, http.MethodPost, "/api/v1/users/batch", batch(tt.language, tt.count),
+			middleware.HeaderAPIKey, apiKey, echo.HeaderContentType, echo.MIMEApplicationJSON,
+			"Accept-Language", tt.language)
+		if msg != tt.msg || language != tt.language {
+			t.Errorf("%d in %s: msg %q (%s), want %q", tt.count, tt.language, msg, language, tt.msg)
+		}
+	}
+}
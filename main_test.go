@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/config"
+	"echo-template/database"
+	"echo-template/utils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain 使用临时目录中的 SQLite 数据库运行测试，关闭限流以免测试请求被拒绝
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "echo-template-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	for name, value := range map[string]string{
+		"APP_ENV":            config.EnvDevelopment,
+		"DB_TYPE":            "sqlite",
+		"DB_NAME":            filepath.Join(dir, "test"),
+		"RATE_LIMIT_ENABLED": "false",
+	} {
+		os.Setenv(name, value)
+	}
+	if err := config.LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := setupDatabase(true); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.CloseDB()
+
+	return m.Run()
+}
+
+// createAdminAPIKey 创建拥有全部权限的管理员服务账号 API Key，返回完整密钥
+func createAdminAPIKey(t *testing.T) string {
+	t.Helper()
+	key := &models.APIKey{
+		Name:           "test " + t.Name(),
+		ServiceAccount: "test",
+		Role:           models.RoleAdmin,
+		Scopes:         models.StringList{utils.ScopeAll},
+	}
+	plaintext, err := services.NewAPIKeyService().CreateAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	return plaintext
+}
+
+// createUserAPIKey 为用户创建拥有全部权限的 API Key，请求以该用户的身份和角色认证
+func createUserAPIKey(t *testing.T, userID uint) string {
+	t.Helper()
+	key := &models.APIKey{
+		Name:   "test " + t.Name(),
+		UserID: &userID,
+		Scopes: models.StringList{utils.ScopeAll},
+	}
+	plaintext, err := services.NewAPIKeyService().CreateAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	return plaintext
+}
+
+// serveRequest 向服务发送请求，headers 为请求头的名称和值，交替排列
+func serveRequest(handler http.Handler, method, target string, body []byte, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
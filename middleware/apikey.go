@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"echo-template/config"
+	"echo-template/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyAuthenticator 校验 API Key，由 API Key 服务实现
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key, ip string) (*utils.APIKeyIdentity, error)
+}
+
+// APIKeyAuth API Key 认证，密钥通过 X-API-Key 或 Authorization: Bearer 传递
+// 与其他认证方式共存：请求已认证，或 Bearer 令牌不带 API_KEY_PREFIX 前缀（例如用户的访问令牌）时不处理；
+// X-API-Key 或带前缀的 Bearer 令牌无效时返回 401。
+// 认证成功后记录密钥身份，用户的密钥同时记录所属用户，后续的角色、权限范围检查和审计日志以此为准
+func APIKeyAuth(authenticator APIKeyAuthenticator) echo.MiddlewareFunc {
+	cfg := config.AppConfig.APIKey
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	prefix := cfg.Prefix + "_"
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := extractAPIKey(c)
+			explicit := c.Request().Header.Get(HeaderAPIKey) != ""
+			if key == "" || utils.Authenticated(c) || (!explicit && !strings.HasPrefix(key, prefix)) {
+				return next(c)
+			}
+
+			identity, err := authenticator.AuthenticateAPIKey(c.Request().Context(), key, c.RealIP())
+			if err != nil {
+				var appErr *utils.AppError
+				if errors.As(err, &appErr) && appErr.Code == http.StatusUnauthorized {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				}
+				return utils.HandleError(c, err)
+			}
+
+			utils.SetCurrentAPIKey(c, identity)
+			utils.SetCurrentUserRole(c, identity.Role)
+			if identity.UserID != 0 {
+				utils.SetCurrentUserID(c, identity.UserID)
+				utils.SetUserLocale(c, identity.Locale)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"echo-template/audit"
+	"echo-template/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditContext 将操作人、请求ID和客户端 IP 写入请求上下文，供审计回调使用
+// 需要注册在 RequestID 之后；认证信息在写库时才解析，因此可以早于认证中间件注册
+func AuditContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := audit.WithActorFunc(c.Request().Context(), func() audit.Actor {
+				actor := audit.Actor{
+					Type:      audit.ActorAnonymous,
+					RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+					IP:        c.RealIP(),
+				}
+				if key, ok := utils.CurrentAPIKey(c); ok {
+					actor.APIKeyID = key.ID
+					actor.Type = audit.ActorService
+				}
+				if id, ok := utils.CurrentUserID(c); ok {
+					actor.UserID = id
+					actor.Type = audit.ActorUser
+				}
+				return actor
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"echo-template/i18n"
+	"echo-template/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireAuth 要求请求已认证（用户或服务账号），否则返回 401
+func RequireAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !utils.Authenticated(c) {
+				return utils.HandleError(c, utils.ErrUnauthorized("auth.required"))
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireRole 要求当前认证用户具有指定角色，否则返回 403
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !utils.HasRole(c, role) {
+				return utils.HandleError(c, utils.ErrForbidden("auth.forbidden"))
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSelfOrRole 要求路径参数 param 为当前认证用户的ID，或当前认证用户具有指定角色，否则返回 403；
+// 服务账号没有对应的用户，只能通过角色访问
+func RequireSelfOrRole(param, role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if utils.HasRole(c, role) {
+				return next(c)
+			}
+			id, err := utils.ParseUintParam(c, param)
+			if err != nil {
+				return utils.HandleError(c, err)
+			}
+			if current, ok := utils.CurrentUserID(c); !ok || current != id {
+				return utils.HandleError(c, utils.ErrForbidden("auth.forbidden"))
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireScope 通过 API Key 认证的请求要求密钥具有资源的权限：GET、HEAD、OPTIONS 需要 <资源>:read，
+// 其他方法需要 <资源>:write，缺少时返回 403；其他认证方式和未认证的请求不受权限范围限制
+func RequireScope(resource string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := utils.CurrentAPIKey(c)
+			if !ok {
+				return next(c)
+			}
+			write := true
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				write = false
+			}
+			if !key.Allows(resource, write) {
+				scope := resource + ":read"
+				if write {
+					scope = resource + ":write"
+				}
+				return utils.HandleError(c, utils.ErrForbidden("auth.insufficient_scope", i18n.Args{"Scope": scope}))
+			}
+			return next(c)
+		}
+	}
+}
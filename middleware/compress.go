@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"echo-template/config"
+	"echo-template/utils"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+)
+
+// compressEncoder 压缩编码器
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// zstdEncoder 适配 zstd.Encoder 的 Reset 签名
+type zstdEncoder struct {
+	*zstd.Encoder
+}
+
+func (e zstdEncoder) Reset(w io.Writer) {
+	e.Encoder.Reset(w)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+	}},
+	"zstd": {New: func() any {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return zstdEncoder{w}
+	}},
+}
+
+// incompressibleTypes 本身已压缩的内容类型
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip",
+	"application/x-gzip", "application/zstd", "application/octet-stream",
+	"application/vnd.openxmlformats-officedocument."}
+
+// Compress 响应压缩中间件，根据 Accept-Encoding 协商 br/zstd/gzip
+// 响应体小于 COMPRESSION_MIN_SIZE 时原样返回；压缩后强 ETag 追加编码后缀
+func Compress() echo.MiddlewareFunc {
+	cfg := config.AppConfig.Compression
+	if !cfg.Enabled || len(cfg.Encodings) == 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+			encoding := negotiateEncoding(c.Request().Header.Get(echo.HeaderAcceptEncoding), cfg.Encodings)
+			if encoding == "" || c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+
+			w := &compressWriter{ResponseWriter: res.Writer, encoding: encoding, minSize: cfg.MinSize}
+			res.Writer = w
+			defer func() {
+				if err := w.finish(); err != nil {
+					c.Logger().Errorf("compress response: %v", err)
+				}
+				res.Writer = w.ResponseWriter
+			}()
+			return next(c)
+		}
+	}
+}
+
+// negotiateEncoding 选择客户端接受的编码，q 值相同时按服务端优先级
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, ok := accepted[encoding]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter 在响应体达到最小长度后才开始压缩，之前的数据先缓存
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	minSize     int
+	status      int
+	buf         []byte
+	encoder     compressEncoder
+	passthrough bool // 不压缩，直接写入底层
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.status != 0 || w.passthrough {
+		return
+	}
+	w.status = code
+	// 无响应体的状态码直接下发
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.minSize {
+		return len(b), nil
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush 流式响应：立即决定是否压缩并刷新数据
+func (w *compressWriter) Flush() {
+	if !w.passthrough && w.encoder == nil {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		_ = w.start()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start 下发响应头并写出缓存数据，必要时启用压缩
+func (w *compressWriter) start() error {
+	header := w.Header()
+	if !w.compressible() {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+		return w.flushBuffer(w.ResponseWriter)
+	}
+
+	header.Set(echo.HeaderContentEncoding, w.encoding)
+	header.Del(echo.HeaderContentLength)
+	if etag := header.Get(utils.HeaderETag); strings.HasPrefix(etag, `"`) {
+		header.Set(utils.HeaderETag, strings.TrimSuffix(etag, `"`)+"-"+w.encoding+`"`)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.encoder = encoderPools[w.encoding].Get().(compressEncoder)
+	w.encoder.Reset(w.ResponseWriter)
+	return w.flushBuffer(w.encoder)
+}
+
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+	if header.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	contentType := header.Get(echo.HeaderContentType)
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *compressWriter) flushBuffer(dst io.Writer) error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := dst.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// finish 结束响应：关闭编码器，或原样写出不足最小长度的响应
+func (w *compressWriter) finish() error {
+	if w.encoder != nil {
+		err := w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		encoderPools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+		return err
+	}
+	if w.passthrough || w.status == 0 {
+		return nil
+	}
+
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	return w.flushBuffer(w.ResponseWriter)
+}
@@ -1,15 +1,93 @@
 package middleware
 
 import (
+	"echo-template/config"
+	"net/url"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// CORS 跨域中间件，按请求路径匹配路由组策略（最长前缀优先），未匹配时使用默认策略
+// 需要全局注册：预检请求可能命中没有注册 OPTIONS 处理器的路由，分组中间件不会执行
 func CORS() echo.MiddlewareFunc {
+	cfg := config.AppConfig.CORS
+	fallback := corsWithPolicy(cfg.Default)
+
+	type group struct {
+		prefix     string
+		middleware echo.MiddlewareFunc
+	}
+	groups := make([]group, 0, len(cfg.Groups))
+	for prefix, policy := range cfg.Groups {
+		groups = append(groups, group{prefix: strings.TrimSuffix(prefix, "/"), middleware: corsWithPolicy(policy)})
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handlers := make([]echo.HandlerFunc, len(groups))
+		for i, g := range groups {
+			handlers[i] = g.middleware(next)
+		}
+		fallbackHandler := fallback(next)
+
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			matched, matchedLen := -1, -1
+			for i, g := range groups {
+				if hasPathPrefix(path, g.prefix) && len(g.prefix) > matchedLen {
+					matched, matchedLen = i, len(g.prefix)
+				}
+			}
+			if matched >= 0 {
+				return handlers[matched](c)
+			}
+			return fallbackHandler(c)
+		}
+	}
+}
+
+func corsWithPolicy(policy config.CORSPolicy) echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"}, // 生产环境应该指定具体的前端域名
-		AllowMethods: []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOriginFunc: func(origin string) (bool, error) {
+			return originAllowed(origin, policy.AllowOrigins), nil
+		},
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           policy.MaxAge,
 	})
 }
 
+// originAllowed 判断来源是否被允许
+// 支持精确匹配、"*" 以及通配子域名（https://*.example.com 匹配任意层级子域名，但不匹配 example.com 本身）
+func originAllowed(origin string, allowed []string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok || !strings.EqualFold(scheme, u.Scheme) {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(u.Host), "."+strings.ToLower(host)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix 按路径段匹配前缀，/api 匹配 /api 和 /api/users，但不匹配 /apix
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || path[len(prefix)] == '/' || prefix == ""
+}
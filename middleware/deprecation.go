@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"echo-template/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 弃用相关的响应头
+const (
+	HeaderDeprecation = "Deprecation" // RFC 9745，值为弃用时间的 Unix 时间戳：@1767225600
+	HeaderSunset      = "Sunset"      // RFC 8594，值为下线时间（HTTP-date）
+	HeaderLink        = "Link"
+)
+
+// Deprecation 路由的弃用信息，随路由一起注册
+type Deprecation struct {
+	Since     time.Time // 弃用时间，可以是将来的时间
+	Sunset    time.Time // 下线时间，零值表示未定；到期后接口返回 410
+	Link      string    // 弃用说明的地址，以 rel="deprecation" 的 Link 下发
+	Successor string    // 替代接口的地址，以 rel="successor-version" 的 Link 下发
+}
+
+// Deprecated 为已弃用的路由或路由组添加 Deprecation、Sunset 和 Link 响应头，过了下线时间返回 410
+func Deprecated(d Deprecation) echo.MiddlewareFunc {
+	var links []string
+	if d.Link != "" {
+		links = append(links, "<"+d.Link+`>; rel="deprecation"; type="text/html"`)
+	}
+	if d.Successor != "" {
+		links = append(links, "<"+d.Successor+`>; rel="successor-version"`)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			if !d.Since.IsZero() {
+				header.Set(HeaderDeprecation, "@"+strconv.FormatInt(d.Since.Unix(), 10))
+			}
+			if !d.Sunset.IsZero() {
+				header.Set(HeaderSunset, d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			for _, link := range links {
+				header.Add(HeaderLink, link)
+			}
+			if !d.Sunset.IsZero() && !time.Now().Before(d.Sunset) {
+				return utils.HandleError(c, utils.NewAppError(http.StatusGone, "api.sunset", nil))
+			}
+			return next(c)
+		}
+	}
+}
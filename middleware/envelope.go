@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"echo-template/config"
+	"echo-template/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Envelope 按 ENVELOPE_* 配置设置请求的响应包装方式，须在其他会写出响应的中间件之前注册
+func Envelope() echo.MiddlewareFunc {
+	cfg := config.AppConfig.Envelope
+	envelope := utils.Envelope{
+		Enabled:     cfg.Enabled,
+		AllowPrefer: cfg.AllowPrefer,
+		CodeField:   cfg.CodeField,
+		DataField:   cfg.DataField,
+		MsgField:    cfg.MsgField,
+		MetaField:   cfg.MetaField,
+		Meta:        cfg.Meta,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			utils.SetEnvelope(c, envelope)
+			return next(c)
+		}
+	}
+}
+
+// EnvelopeEnabled 为路由组开启或关闭成功响应的包装，例如面向通用 REST 工具的路由组返回裸资源；
+// ENVELOPE_PREFER 开启时客户端仍可通过 Prefer: envelope=true|false 覆盖
+func EnvelopeEnabled(enabled bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			envelope := utils.CurrentEnvelope(c)
+			envelope.Enabled = enabled
+			utils.SetEnvelope(c, envelope)
+			return next(c)
+		}
+	}
+}
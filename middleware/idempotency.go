@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"echo-template/config"
+	"echo-template/utils"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 幂等相关请求/响应头
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	idempotencyPollInterval  = 50 * time.Millisecond
+)
+
+// idempotencySkippedHeaders 不保存、不回放的响应头
+// 保存的是压缩前的响应体，Content-Encoding 由外层的 Compress 在回放时按本次请求重新协商
+var idempotencySkippedHeaders = map[string]bool{
+	echo.HeaderContentLength:   true,
+	echo.HeaderContentEncoding: true,
+	"Date":                     true,
+	echo.HeaderSetCookie:       true,
+	echo.HeaderXRequestID:      true,
+	HeaderRateLimitLimit:       true,
+	HeaderRateLimitRemaining:   true,
+	HeaderRateLimitReset:       true,
+	HeaderRateLimitPolicy:      true,
+}
+
+var idempotencyStore IdempotencyStore = NewMemoryIdempotencyStore()
+
+// SetIdempotencyStore 替换幂等记录存储（多副本部署时使用共享存储）
+func SetIdempotencyStore(store IdempotencyStore) {
+	idempotencyStore = store
+}
+
+// Idempotency 幂等键中间件，作用于携带 Idempotency-Key 的 POST/PATCH 请求
+//   - 首次请求正常处理，保存状态码、响应头和响应体
+//   - 相同幂等键、相同请求体的重试直接回放保存的响应（带 Idempotent-Replayed: true）
+//   - 相同幂等键、不同请求体返回 422
+//   - 相同幂等键的请求仍在处理时，最多等待 IDEMPOTENCY_WAIT_TIMEOUT，超时返回 409
+//   - 处理失败（5xx）的请求不保存，允许客户端重试
+func Idempotency() echo.MiddlewareFunc {
+	cfg := config.AppConfig.Idempotency
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			idempotencyKey := req.Header.Get(HeaderIdempotencyKey)
+			if idempotencyKey == "" || (req.Method != http.MethodPost && req.Method != http.MethodPatch) {
+				return next(c)
+			}
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				return utils.HandleError(c, utils.ErrInvalidParam(HeaderIdempotencyKey))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return utils.HandleError(c, utils.ErrRequestTooLarge())
+				}
+				return utils.HandleError(c, utils.ErrBadRequest("request.invalid_body"))
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			key := idempotencyScope(c) + ":" + idempotencyKey
+			fingerprint := requestFingerprint(req, body)
+
+			record, acquired, err := idempotencyStore.Acquire(ctx, key, fingerprint, cfg.TTL)
+			if err != nil {
+				return utils.HandleError(c, utils.ErrInternal("idempotency.store_unavailable", err))
+			}
+
+			// 相同幂等键的请求正在处理，等待其完成
+			deadline := time.Now().Add(cfg.WaitTimeout)
+			for !acquired && record != nil && !record.Completed && record.Fingerprint == fingerprint {
+				if !time.Now().Before(deadline) {
+					return utils.Error(c, http.StatusConflict, "idempotency.in_progress")
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(idempotencyPollInterval):
+				}
+
+				if record, err = idempotencyStore.Get(ctx, key); err != nil {
+					return utils.HandleError(c, utils.ErrInternal("idempotency.store_unavailable", err))
+				}
+				if record == nil {
+					// 先前的请求失败并释放了 key，重新占用
+					if record, acquired, err = idempotencyStore.Acquire(ctx, key, fingerprint, cfg.TTL); err != nil {
+						return utils.HandleError(c, utils.ErrInternal("idempotency.store_unavailable", err))
+					}
+				}
+			}
+
+			if !acquired {
+				if record.Fingerprint != fingerprint {
+					return utils.Error(c, http.StatusUnprocessableEntity, "idempotency.key_reused")
+				}
+				return replayResponse(c, record)
+			}
+
+			// 记录响应
+			res := c.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+			err = next(c)
+			res.Writer = recorder.ResponseWriter
+
+			if err != nil || res.Status >= http.StatusInternalServerError {
+				if releaseErr := idempotencyStore.Release(ctx, key); releaseErr != nil {
+					c.Logger().Errorf("release idempotency key: %v", releaseErr)
+				}
+				return err
+			}
+
+			header := make(http.Header)
+			for name, values := range res.Header() {
+				if !idempotencySkippedHeaders[name] {
+					header[name] = values
+				}
+			}
+			// 外层的 Compress 压缩时在强 ETag 后追加了编码后缀，保存未压缩的表示对应的 ETag
+			if encoding := res.Header().Get(echo.HeaderContentEncoding); encoding != "" {
+				if etag := header.Get(utils.HeaderETag); strings.HasSuffix(etag, "-"+encoding+`"`) {
+					header.Set(utils.HeaderETag, strings.TrimSuffix(etag, "-"+encoding+`"`)+`"`)
+				}
+			}
+			completed := &IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      res.Status,
+				Header:      header,
+				Body:        recorder.body.Bytes(),
+			}
+			if err := idempotencyStore.Complete(ctx, key, completed, cfg.TTL); err != nil {
+				c.Logger().Errorf("save idempotency record: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// idempotencyScope 幂等键的作用域：同一用户（未认证时同一 IP）的同一路由
+func idempotencyScope(c echo.Context) string {
+	owner := "ip:" + c.RealIP()
+	if id, ok := utils.CurrentUserID(c); ok {
+		owner = "user:" + strconv.FormatUint(uint64(id), 10)
+	}
+	return owner + ":" + c.Request().Method + ":" + c.Path()
+}
+
+// requestFingerprint 请求指纹：方法、路径、查询参数和请求体
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayResponse(c echo.Context, record *IdempotencyRecord) error {
+	header := c.Response().Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+	c.Response().WriteHeader(record.Status)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// responseRecorder 在写出响应的同时保存响应体
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
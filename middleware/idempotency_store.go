@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord 幂等键对应的请求指纹和响应
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool // false 表示请求仍在处理中
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore 幂等记录存储接口
+// 默认使用进程内存储；多副本部署时实现该接口（例如基于 Redis SET NX），保证 Acquire 原子
+type IdempotencyStore interface {
+	// Acquire 原子地占用 key；key 已存在时返回已有记录且 acquired 为 false
+	Acquire(ctx context.Context, key, fingerprint string, ttl time.Duration) (record *IdempotencyRecord, acquired bool, err error)
+	// Get 获取记录，不存在或已过期时返回 nil
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Complete 保存响应，之后的重试直接回放
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release 释放 key（请求失败时调用，允许客户端重试）
+	Release(ctx context.Context, key string) error
+}
+
+// 确保 MemoryIdempotencyStore 实现了 IdempotencyStore
+var _ IdempotencyStore = (*MemoryIdempotencyStore)(nil)
+
+// MemoryIdempotencyStore 进程内幂等记录存储
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*IdempotencyRecord
+	lastSweep time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]*IdempotencyRecord),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Acquire(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return copyRecord(record), false, nil
+	}
+
+	s.records[key] = &IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && time.Now().Before(record.ExpiresAt) {
+		return copyRecord(record), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := copyRecord(record)
+	stored.Completed = true
+	stored.ExpiresAt = time.Now().Add(ttl)
+	s.records[key] = stored
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep 定期清理过期记录
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryStoreSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func copyRecord(record *IdempotencyRecord) *IdempotencyRecord {
+	copied := *record
+	copied.Header = record.Header.Clone()
+	copied.Body = append([]byte(nil), record.Body...)
+	return &copied
+}
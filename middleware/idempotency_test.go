@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"echo-template/config"
+	"echo-template/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestIdempotency_ReplayCompressed 压缩后的响应回放时重新压缩，不保存 Content-Encoding 和 ETag 的编码后缀
+func TestIdempotency_ReplayCompressed(t *testing.T) {
+	config.AppConfig = &config.Config{
+		Compression: config.CompressionConfig{Enabled: true, MinSize: 1024, Encodings: []string{"gzip"}},
+		Idempotency: config.IdempotencyConfig{Enabled: true, TTL: time.Minute},
+	}
+	SetIdempotencyStore(NewMemoryIdempotencyStore())
+
+	payload := `{"data":"` + strings.Repeat("x", 2*config.AppConfig.Compression.MinSize) + `"}`
+	calls := 0
+	e := echo.New()
+	e.Use(Compress(), Idempotency())
+	e.POST("/items", func(c echo.Context) error {
+		calls++
+		c.Response().Header().Set(utils.HeaderETag, `"v1"`)
+		return c.JSONBlob(http.StatusCreated, []byte(payload))
+	})
+
+	send := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		if acceptEncoding != "" {
+			req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	gunzip := func(t *testing.T, rec *httptest.ResponseRecorder) string {
+		t.Helper()
+		reader, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("gunzip: %v", err)
+		}
+		return string(body)
+	}
+
+	first := send("gzip")
+	if first.Code != http.StatusCreated || first.Header().Get(echo.HeaderContentEncoding) != "gzip" {
+		t.Fatalf("first response: status %d, encoding %q", first.Code, first.Header().Get(echo.HeaderContentEncoding))
+	}
+	if got := gunzip(t, first); got != payload {
+		t.Fatalf("first body mismatch: %d bytes", len(got))
+	}
+
+	replayed := send("gzip")
+	if replayed.Header().Get(HeaderIdempotentReplayed) != "true" || calls != 1 {
+		t.Fatalf("expected replay without calling the handler again, calls = %d", calls)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Header().Get(echo.HeaderContentEncoding) != "gzip" {
+		t.Fatalf("replay: status %d, encoding %q", replayed.Code, replayed.Header().Get(echo.HeaderContentEncoding))
+	}
+	if got := gunzip(t, replayed); got != payload {
+		t.Fatalf("replayed body mismatch: %d bytes", len(got))
+	}
+	if etag := replayed.Header().Get(utils.HeaderETag); etag != `"v1-gzip"` {
+		t.Fatalf("replay ETag = %q, want %q", etag, `"v1-gzip"`)
+	}
+	if vary := replayed.Header().Values(echo.HeaderVary); len(vary) == 0 {
+		t.Fatal("replay is missing Vary")
+	}
+
+	// 不接受压缩的重试拿到未压缩的响应体和原始 ETag
+	plain := send("")
+	if plain.Header().Get(HeaderIdempotentReplayed) != "true" || plain.Header().Get(echo.HeaderContentEncoding) != "" {
+		t.Fatalf("plain replay: replayed %q, encoding %q",
+			plain.Header().Get(HeaderIdempotentReplayed), plain.Header().Get(echo.HeaderContentEncoding))
+	}
+	if plain.Body.String() != payload {
+		t.Fatalf("plain replay body mismatch: %d bytes", plain.Body.Len())
+	}
+	if etag := plain.Header().Get(utils.HeaderETag); etag != `"v1"` {
+		t.Fatalf("plain replay ETag = %q, want %q", etag, `"v1"`)
+	}
+}
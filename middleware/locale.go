@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"echo-template/config"
+	"echo-template/i18n"
+	"echo-template/utils"
+	"log"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Locale 设置默认语言（I18N_DEFAULT_LOCALE）并记录查询参数（I18N_QUERY_PARAM）指定的语言
+// 响应消息的语言依次取自查询参数、用户偏好（认证中间件调用 utils.SetUserLocale）和 Accept-Language
+func Locale() echo.MiddlewareFunc {
+	cfg := config.AppConfig.I18n
+	if err := i18n.SetDefaultLanguage(cfg.DefaultLocale); err != nil {
+		log.Printf("I18N_DEFAULT_LOCALE %q is not supported, using %s: %v", cfg.DefaultLocale, i18n.DefaultLanguage(), err)
+	}
+	if cfg.QueryParam == "" {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if locale := c.QueryParam(cfg.QueryParam); locale != "" {
+				utils.SetRequestLocale(c, locale)
+			}
+			return next(c)
+		}
+	}
+}
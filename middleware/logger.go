@@ -7,7 +7,7 @@ import (
 
 func Logger() echo.MiddlewareFunc {
 	return middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${time_rfc3339} | ${status} | ${latency_human} | ${remote_ip} | ${method} ${uri}\n",
+		Format: "${time_rfc3339} | ${id} | ${status} | ${latency_human} | ${remote_ip} | ${method} ${uri}\n",
 	})
 }
 
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"echo-template/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContentNegotiation 在处理请求之前检查请求体格式和 Accept
+//   - 请求体的 Content-Type 既不是已注册的格式也不是表单时返回 415
+//   - 非安全方法（POST、PUT、PATCH、DELETE）的 Accept 中没有可用的格式时返回 406，避免执行了修改却无法返回结果
+//
+// GET、HEAD 等安全方法在写出响应时再协商，下载文件的接口可以返回自己的格式
+func ContentNegotiation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if contentType := req.Header.Get(echo.HeaderContentType); req.ContentLength != 0 && contentType != "" {
+				if _, ok := utils.CodecForContentType(contentType); !ok && !utils.IsFormContentType(contentType) {
+					return utils.HandleError(c, utils.ErrUnsupportedMediaType())
+				}
+			}
+
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if _, ok := utils.NegotiateCodec(req.Header.Get(echo.HeaderAccept)); !ok {
+					return utils.HandleError(c, utils.ErrNotAcceptable())
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"echo-template/config"
+	"echo-template/openapi"
+	"echo-template/utils"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+// 不一致发生的位置
+const (
+	OpenAPIMismatchRequest  = "request"
+	OpenAPIMismatchResponse = "response"
+)
+
+// OpenAPIMismatchHandler 处理请求或响应与文档不符，kind 为 OpenAPIMismatchRequest 或 OpenAPIMismatchResponse
+type OpenAPIMismatchHandler func(c echo.Context, kind string, err error)
+
+var openAPIMismatchHandler OpenAPIMismatchHandler = logOpenAPIMismatch
+
+// SetOpenAPIMismatchHandler 替换不一致的处理方式，例如在测试中直接判定失败
+func SetOpenAPIMismatchHandler(handler OpenAPIMismatchHandler) {
+	openAPIMismatchHandler = handler
+}
+
+func logOpenAPIMismatch(c echo.Context, kind string, err error) {
+	c.Logger().Errorf("openapi %s mismatch: %s %s: %v", kind, c.Request().Method, c.Path(), err)
+}
+
+// OpenAPIValidator 按 OpenAPI 文档校验请求和响应（OPENAPI_VALIDATE），用于开发和测试环境
+//   - report：请求和响应的不一致都只报告，不影响处理
+//   - strict：不符合文档的请求返回 400 并附带原因；响应已写出，仍只报告
+//
+// 未写入文档的路由和 304、406、415 响应不校验；请求体只校验 JSON 和表单，响应体只校验包装格式与文档一致的 JSON
+func OpenAPIValidator() echo.MiddlewareFunc {
+	mode := config.AppConfig.OpenAPI.Validate
+	if mode == config.OpenAPIValidateOff {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	doc, err := openapi.Document()
+	if err != nil {
+		log.Printf("openapi document is invalid, skipping validation: %v", err)
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	options := &openapi3filter.Options{
+		IncludeResponseStatus: true,
+		MultiError:            true,
+		// 只校验，不把默认值写回请求
+		SkipSettingDefaults: true,
+		// 认证由认证中间件负责
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	// 默认的错误信息附带完整的 schema 和值，只保留位置和原因
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		if pointer := err.JSONPointer(); len(pointer) > 0 {
+			return "/" + strings.Join(pointer, "/") + ": " + err.Reason
+		}
+		return err.Reason
+	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := openAPIRoute(doc, c)
+			if route == nil {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx := req.Context()
+			pathParams := make(map[string]string, len(c.ParamNames()))
+			for i, name := range c.ParamNames() {
+				pathParams[name] = c.ParamValues()[i]
+			}
+			// 请求体只校验 JSON，其他格式与 JSON 使用相同的数据模型，由绑定时的校验负责
+			requestOptions := *options
+			if contentType := req.Header.Get(echo.HeaderContentType); contentType != "" && req.ContentLength != 0 {
+				requestOptions.ExcludeRequestBody = !isJSONContentType(contentType) && !utils.IsFormContentType(contentType)
+			}
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &requestOptions,
+			}
+			if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+				openAPIMismatchHandler(c, OpenAPIMismatchRequest, err)
+				if mode == config.OpenAPIValidateStrict {
+					appErr := utils.ErrBadRequest("request.openapi_mismatch")
+					appErr.Details = openAPIErrors(err)
+					return utils.HandleError(c, appErr)
+				}
+			}
+
+			res := c.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+			err := next(c)
+			res.Writer = recorder.ResponseWriter
+			if err != nil || openAPISkippedStatus[res.Status] {
+				return err
+			}
+
+			body := recorder.body.Bytes()
+			responseOptions := *options
+			// 不包装或自定义了字段名的响应体与文档中的 utils.Response 不同，只校验状态码和响应头
+			responseOptions.ExcludeResponseBody = !isJSONContentType(res.Header().Get(echo.HeaderContentType)) || !utils.Enveloped(c)
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 res.Status,
+				Header:                 res.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(body)),
+				Options:                &responseOptions,
+			}
+			if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+				openAPIMismatchHandler(c, OpenAPIMismatchResponse, err)
+			}
+			return nil
+		}
+	}
+}
+
+// openAPISkippedStatus 不校验的响应状态：304 没有响应体，401 由认证中间件（例如无效的 API Key）、
+// 406、415 由内容协商统一返回，不在每个接口的文档中列出
+var openAPISkippedStatus = map[int]bool{
+	http.StatusUnauthorized:         true,
+	http.StatusNotModified:          true,
+	http.StatusNotAcceptable:        true,
+	http.StatusUnsupportedMediaType: true,
+}
+
+// openAPIRoute 按 Echo 匹配到的路由查找文档中的操作，未写入文档时返回 nil
+func openAPIRoute(doc *openapi3.T, c echo.Context) *routers.Route {
+	path, ok := openapi.SpecPath(c.Path())
+	if !ok || doc.Paths == nil {
+		return nil
+	}
+	item := doc.Paths.Value(path)
+	if item == nil {
+		return nil
+	}
+	method := c.Request().Method
+	operation := item.GetOperation(method)
+	if operation == nil {
+		return nil
+	}
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    method,
+		Operation: operation,
+	}
+}
+
+// openAPIErrors 将校验错误展开为原因列表
+func openAPIErrors(err error) []string {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		messages := make([]string, 0, len(multi))
+		for _, e := range multi {
+			messages = append(messages, openAPIErrors(e)...)
+		}
+		return messages
+	}
+	return []string{strings.ReplaceAll(err.Error(), "\n", " ")}
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"echo-template/config"
+	"echo-template/utils"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 限流响应头（draft-ietf-httpapi-ratelimit-headers）
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderAPIKey             = "X-API-Key"
+)
+
+var rateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+// SetRateLimitStore 替换限流存储（多副本部署时使用共享存储）
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+// RateLimit 按配置中的策略名限流
+// 策略在 RATE_LIMIT_POLICIES 中声明，未声明或限流关闭时直接放行
+func RateLimit(policyName string) echo.MiddlewareFunc {
+	cfg := config.AppConfig.RateLimit
+	policy, ok := cfg.Policies[policyName]
+	if !cfg.Enabled || !ok {
+		if cfg.Enabled {
+			log.Printf("rate limit policy %q is not configured, skipping", policyName)
+		}
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := policyName + ":" + rateLimitKey(c, policy.KeyBy)
+
+			result, err := rateLimitStore.Take(c.Request().Context(), key, policy)
+			if err != nil {
+				// 存储不可用时放行，避免限流组件故障导致整体不可用
+				c.Logger().Errorf("rate limit store error: %v", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set(HeaderRateLimitPolicy, strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Window)))
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+				return utils.Error(c, http.StatusTooManyRequests, "request.rate_limited")
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimitKey 根据策略提取限流键，无法识别用户或 API Key 时退化为按 IP 限流
+// 只使用认证中间件已验证的身份，伪造或失效的密钥不会得到新的配额
+func rateLimitKey(c echo.Context, keyBy string) string {
+	switch keyBy {
+	case config.RateLimitKeyUser:
+		if id, ok := utils.CurrentUserID(c); ok {
+			return "user:" + strconv.FormatUint(uint64(id), 10)
+		}
+	case config.RateLimitKeyAPIKey:
+		if key, ok := utils.CurrentAPIKey(c); ok {
+			return "key:" + strconv.FormatUint(uint64(key.ID), 10)
+		}
+	}
+	return "ip:" + c.RealIP()
+}
+
+// extractAPIKey 从 X-API-Key 或 Authorization: Bearer 中提取 API Key
+func extractAPIKey(c echo.Context) string {
+	if apiKey := c.Request().Header.Get(HeaderAPIKey); apiKey != "" {
+		return apiKey
+	}
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
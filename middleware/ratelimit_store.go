@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"echo-template/config"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitResult 单次限流判定结果
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 配额完全恢复所需时间
+	RetryAfter time.Duration // 被拒绝时，距离下一次可用的时间
+}
+
+// RateLimitStore 限流存储接口
+// 默认使用进程内存储；多副本部署时实现该接口（例如基于 Redis + Lua 脚本），
+// 保证同一个 key 的判定在所有副本间原子共享。
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy config.RateLimitPolicy) (RateLimitResult, error)
+}
+
+// 确保 MemoryRateLimitStore 实现了 RateLimitStore
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// MemoryRateLimitStore 进程内限流存储，支持令牌桶和滑动窗口算法
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	windows   map[string]*slidingWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	lastSeen  time.Time
+	expiresAt time.Time // 桶重新装满的时间，此后条目可以丢弃
+}
+
+type slidingWindow struct {
+	start     time.Time // 当前窗口起始时间
+	current   int
+	previous  int
+	expiresAt time.Time // 两个窗口后计数全部失效，此后条目可以丢弃
+}
+
+// memoryStoreSweepInterval 过期条目清理间隔
+const memoryStoreSweepInterval = time.Minute
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		windows: make(map[string]*slidingWindow),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy config.RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if policy.Algorithm == config.RateLimitTokenBucket {
+		return s.takeToken(key, policy, now), nil
+	}
+	return s.takeWindow(key, policy, now), nil
+}
+
+// takeToken 令牌桶：以 Limit/Window 的速率补充令牌，桶容量为 Burst
+func (s *MemoryRateLimitStore) takeToken(key string, policy config.RateLimitPolicy, now time.Time) RateLimitResult {
+	capacity := float64(policy.Burst)
+	rate := float64(policy.Limit) / policy.Window.Seconds() // 每秒补充的令牌数
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, lastSeen: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*rate)
+	bucket.lastSeen = now
+
+	result := RateLimitResult{Limit: policy.Burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = secondsToDuration((capacity - bucket.tokens) / rate)
+	bucket.expiresAt = now.Add(result.Reset)
+	return result
+}
+
+// takeWindow 滑动窗口计数：按上一窗口剩余时间占比加权估算当前窗口内的请求数
+func (s *MemoryRateLimitStore) takeWindow(key string, policy config.RateLimitPolicy, now time.Time) RateLimitResult {
+	window, ok := s.windows[key]
+	if !ok {
+		window = &slidingWindow{start: now.Truncate(policy.Window)}
+		s.windows[key] = window
+	}
+
+	// 推进窗口
+	if elapsed := now.Sub(window.start); elapsed >= policy.Window {
+		if elapsed < 2*policy.Window {
+			window.previous = window.current
+		} else {
+			window.previous = 0
+		}
+		window.current = 0
+		window.start = now.Truncate(policy.Window)
+	}
+	window.expiresAt = window.start.Add(2 * policy.Window)
+
+	elapsed := now.Sub(window.start)
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	estimated := float64(window.previous)*weight + float64(window.current)
+
+	result := RateLimitResult{Limit: policy.Limit, Reset: policy.Window - elapsed}
+	if estimated+1 <= float64(policy.Limit) {
+		window.current++
+		estimated++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = policy.Window - elapsed
+		// 上一窗口的权重衰减后可能提前释放配额
+		if window.previous > 0 {
+			excess := estimated + 1 - float64(policy.Limit)
+			wait := time.Duration(excess / float64(window.previous) * float64(policy.Window))
+			if wait < result.RetryAfter {
+				result.RetryAfter = wait
+			}
+		}
+	}
+	result.Remaining = max(0, policy.Limit-int(math.Ceil(estimated)))
+	return result
+}
+
+// sweep 定期清理长时间未访问的条目，避免内存无限增长
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryStoreSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, window := range s.windows {
+		if now.After(window.expiresAt) {
+			delete(s.windows, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
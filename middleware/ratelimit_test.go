@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"echo-template/config"
+	"echo-template/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newTestRateLimitStore 返回使用可控时钟的内存存储
+func newTestRateLimitStore(now *time.Time) *MemoryRateLimitStore {
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestRateLimitStore(&now)
+	policy := config.RateLimitPolicy{Algorithm: config.RateLimitTokenBucket, Limit: 2, Window: time.Second, Burst: 2}
+	take := func() RateLimitResult {
+		t.Helper()
+		result, err := store.Take(context.Background(), "k", policy)
+		if err != nil {
+			t.Fatalf("take: %v", err)
+		}
+		return result
+	}
+
+	if r := take(); !r.Allowed || r.Remaining != 1 {
+		t.Fatalf("first take: %+v", r)
+	}
+	if r := take(); !r.Allowed || r.Remaining != 0 || r.Reset != time.Second {
+		t.Fatalf("second take: %+v", r)
+	}
+	// 每秒补充两个令牌，下一个令牌在 500ms 后可用
+	if r := take(); r.Allowed || r.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("empty bucket: %+v", r)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if r := take(); r.Allowed || r.RetryAfter != 250*time.Millisecond {
+		t.Fatalf("half refilled: %+v", r)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if r := take(); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("refilled token: %+v", r)
+	}
+	// 长时间空闲后最多补满到 Burst
+	now = now.Add(time.Minute)
+	if r := take(); !r.Allowed || r.Remaining != 1 {
+		t.Fatalf("after idle: %+v", r)
+	}
+}
+
+func TestMemoryRateLimitStore_SlidingWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestRateLimitStore(&now)
+	policy := config.RateLimitPolicy{Algorithm: config.RateLimitSlidingWindow, Limit: 2, Window: time.Minute}
+	take := func(key string) RateLimitResult {
+		t.Helper()
+		result, err := store.Take(context.Background(), key, policy)
+		if err != nil {
+			t.Fatalf("take: %v", err)
+		}
+		return result
+	}
+
+	take("k")
+	if r := take("k"); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("second take: %+v", r)
+	}
+	if r := take("k"); r.Allowed || r.RetryAfter != time.Minute {
+		t.Fatalf("over limit: %+v", r)
+	}
+	// 不同的键分别计数
+	if r := take("other"); !r.Allowed {
+		t.Fatalf("other key: %+v", r)
+	}
+
+	// 下一个窗口过半时，上一窗口的两次请求按一半计入
+	now = now.Add(90 * time.Second)
+	if r := take("k"); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("weighted window: %+v", r)
+	}
+	if r := take("k"); r.Allowed || r.RetryAfter != 30*time.Second || r.Reset != 30*time.Second {
+		t.Fatalf("weighted window over limit: %+v", r)
+	}
+
+	// 两个窗口之后计数全部失效
+	now = now.Add(2 * time.Minute)
+	if r := take("k"); !r.Allowed || r.Remaining != 1 {
+		t.Fatalf("expired window: %+v", r)
+	}
+}
+
+// TestRateLimit_Response 超限返回 429 和 Retry-After；api_key 策略只按已认证的密钥计数
+func TestRateLimit_Response(t *testing.T) {
+	config.AppConfig = &config.Config{
+		RateLimit: config.RateLimitConfig{Enabled: true, Policies: map[string]config.RateLimitPolicy{
+			"test": {Algorithm: config.RateLimitSlidingWindow, KeyBy: config.RateLimitKeyAPIKey, Limit: 1, Window: time.Minute},
+		}},
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetRateLimitStore(newTestRateLimitStore(&now))
+
+	e := echo.New()
+	// 模拟 APIKeyAuth：只有 X-Test-Key-ID 对应已验证的密钥
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Header.Get("X-Test-Key-ID") {
+			case "1":
+				utils.SetCurrentAPIKey(c, &utils.APIKeyIdentity{ID: 1})
+			case "2":
+				utils.SetCurrentAPIKey(c, &utils.APIKeyIdentity{ID: 2})
+			}
+			return next(c)
+		}
+	})
+	e.GET("/items", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, RateLimit("test"))
+
+	send := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("X-Test-Key-ID", "1")
+	if first.Code != http.StatusNoContent || first.Header().Get(HeaderRateLimitLimit) != "1" ||
+		first.Header().Get(HeaderRateLimitRemaining) != "0" || first.Header().Get(HeaderRateLimitPolicy) != "1;w=60" {
+		t.Fatalf("first request: status %d, headers %v", first.Code, first.Header())
+	}
+	limited := send("X-Test-Key-ID", "1")
+	if limited.Code != http.StatusTooManyRequests || limited.Header().Get(echo.HeaderRetryAfter) != "60" {
+		t.Fatalf("limited request: status %d, Retry-After %q", limited.Code, limited.Header().Get(echo.HeaderRetryAfter))
+	}
+	if rec := send("X-Test-Key-ID", "2"); rec.Code != http.StatusNoContent {
+		t.Fatalf("another api key: status %d", rec.Code)
+	}
+
+	// 未通过认证的密钥按 IP 计数，更换密钥不会得到新的配额
+	if rec := send(HeaderAPIKey, "forged-1"); rec.Code != http.StatusNoContent {
+		t.Fatalf("first unauthenticated request: status %d", rec.Code)
+	}
+	for _, forged := range []string{"forged-2", "forged-3"} {
+		if rec := send(HeaderAPIKey, forged); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("forged key %q: status %d", forged, rec.Code)
+		}
+	}
+	if rec := send(echo.HeaderAuthorization, "Bearer forged-4"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("forged bearer token: status %d", rec.Code)
+	}
+}
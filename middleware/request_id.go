@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// maxRequestIDLength 客户端携带的请求ID的最大长度，UUID 为 36 个字符
+const maxRequestIDLength = 64
+
+// RequestID 为每个请求生成 X-Request-Id
+// 客户端携带的值会写入日志和审计记录，只在长度不超过 maxRequestIDLength 且仅含字母、数字、-、_、. 时沿用，否则重新生成
+func RequestID() echo.MiddlewareFunc {
+	requestID := middleware.RequestID()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := requestID(next)
+		return func(c echo.Context) error {
+			header := c.Request().Header
+			if id := header.Get(echo.HeaderXRequestID); id != "" && !validRequestID(id) {
+				header.Del(echo.HeaderXRequestID)
+			}
+			return handler(c)
+		}
+	}
+}
+
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
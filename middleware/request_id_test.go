@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestRequestID 格式合法的请求ID原样沿用，其余的由服务端重新生成
+func TestRequestID(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestID())
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Response().Header().Get(echo.HeaderXRequestID))
+	})
+
+	tests := []struct {
+		id   string
+		keep bool
+	}{
+		{"3b1f0c9e-7d1a-4c5b-9f3e-2a6d8c4b1e70", true},
+		{"trace_01.abc", true},
+		{strings.Repeat("a", maxRequestIDLength), true},
+		{"", false},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+		{"id with spaces", false},
+		{"id\"><script>", false},
+		{"请求", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.id != "" {
+			req.Header.Set(echo.HeaderXRequestID, tt.id)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(echo.HeaderXRequestID)
+		if rec.Body.String() != got {
+			t.Errorf("%q: handler saw %q, response has %q", tt.id, rec.Body.String(), got)
+		}
+		if tt.keep {
+			if got != tt.id {
+				t.Errorf("%q: request id replaced with %q", tt.id, got)
+			}
+			continue
+		}
+		if got == "" || got == tt.id || !validRequestID(got) {
+			t.Errorf("%q: generated request id %q", tt.id, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"echo-template/config"
+	"echo-template/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DocsContentSecurityPolicy Swagger UI 需要加载脚本、样式和内联脚本
+const DocsContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+// Secure 安全响应头中间件（HSTS、CSP、X-Content-Type-Options、X-Frame-Options、Referrer-Policy）
+func Secure() echo.MiddlewareFunc {
+	cfg := config.AppConfig.Security
+
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			if cfg.ContentTypeNosniff {
+				header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			}
+			if cfg.FrameOptions != "" {
+				header.Set(echo.HeaderXFrameOptions, cfg.FrameOptions)
+			}
+			if cfg.ReferrerPolicy != "" {
+				header.Set(echo.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+			}
+			if cfg.ContentSecurityPolicy != "" {
+				header.Set(echo.HeaderContentSecurityPolicy, cfg.ContentSecurityPolicy)
+			}
+			// HSTS 只能通过 HTTPS 下发
+			if hsts != "" && isHTTPS(&cfg, c.Request()) {
+				header.Set(echo.HeaderStrictTransportSecurity, hsts)
+			}
+			return next(c)
+		}
+	}
+}
+
+// isHTTPS 请求是否通过 HTTPS 到达：直接的 TLS 连接，或可信代理转发的 X-Forwarded-Proto: https
+// 不使用 c.Scheme()，它会采信任何客户端发送的 X-Forwarded-Proto
+func isHTTPS(cfg *config.SecurityConfig, req *http.Request) bool {
+	if req.TLS != nil {
+		return true
+	}
+	if !cfg.IsTrustedProxy(req.RemoteAddr) {
+		return false
+	}
+	proto, _, _ := strings.Cut(req.Header.Get(echo.HeaderXForwardedProto), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// ContentSecurityPolicy 为特定路由覆盖全局 CSP
+func ContentSecurityPolicy(policy string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderContentSecurityPolicy, policy)
+			return next(c)
+		}
+	}
+}
+
+// BodyLimit 请求体大小限制，按请求路径最长前缀匹配 BODY_LIMIT_ROUTES，未匹配时使用 BODY_LIMIT
+func BodyLimit() echo.MiddlewareFunc {
+	cfg := config.AppConfig.Security
+
+	limitFor := func(path string) int64 {
+		limit, matchedLen := cfg.BodyLimit, -1
+		for prefix, size := range cfg.BodyLimits {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if hasPathPrefix(path, prefix) && len(prefix) > matchedLen {
+				limit, matchedLen = size, len(prefix)
+			}
+		}
+		return limit
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			limit := limitFor(req.URL.Path)
+			if limit <= 0 {
+				return next(c)
+			}
+
+			if req.ContentLength > limit {
+				return utils.HandleError(c, utils.ErrRequestTooLarge())
+			}
+			// 未声明 Content-Length（分块传输）时在读取阶段截断
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// IPExtractor 返回客户端 IP 提取器
+// 未配置可信代理时直接使用连接地址，忽略 X-Forwarded-For，防止伪造
+func IPExtractor() echo.IPExtractor {
+	proxies := config.AppConfig.Security.TrustedProxies
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, ipNet := range proxies {
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
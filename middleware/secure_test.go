@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"echo-template/config"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TestSecure_HSTS 只有 TLS 连接或可信代理转发的 HTTPS 请求下发 HSTS
+func TestSecure_HSTS(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	config.AppConfig = &config.Config{
+		Security: config.SecurityConfig{HSTSMaxAge: 3600, TrustedProxies: []*net.IPNet{proxies}},
+	}
+	e := echo.New()
+	e.Use(Secure())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		proto      string
+		want       bool
+	}{
+		{"plain http", "203.0.113.1:1234", false, "", false},
+		{"tls", "203.0.113.1:1234", true, "", true},
+		{"forged forwarded proto", "203.0.113.1:1234", false, "https", false},
+		{"trusted proxy https", "10.0.0.1:1234", false, "https", true},
+		{"trusted proxy multiple hops", "10.0.0.1:1234", false, "HTTPS, http", true},
+		{"trusted proxy http", "10.0.0.1:1234", false, "http", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		if tt.proto != "" {
+			req.Header.Set(echo.HeaderXForwardedProto, tt.proto)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(echo.HeaderStrictTransportSecurity)
+		if (got != "") != tt.want {
+			t.Errorf("%s: Strict-Transport-Security = %q, want present %v", tt.name, got, tt.want)
+		}
+		if tt.want && got != "max-age=3600" {
+			t.Errorf("%s: Strict-Transport-Security = %q", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"echo-template/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TokenAuthenticator 校验用户的访问令牌，由登录服务实现
+type TokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*utils.UserIdentity, error)
+}
+
+// TokenAuth 访问令牌认证，令牌通过 OIDC 登录获得，以 Authorization: Bearer 传递
+// 注册在 APIKeyAuth 之后：请求已通过 API Key 认证或没有 Bearer 令牌时不处理；令牌无效或已过期时返回 401。
+// 认证成功后记录用户、角色和偏好的语言，每次请求重新读取用户，删除用户或修改角色立即生效
+func TokenAuth(authenticator TokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			token = strings.TrimSpace(token)
+			if !ok || token == "" || utils.Authenticated(c) {
+				return next(c)
+			}
+
+			user, err := authenticator.AuthenticateToken(c.Request().Context(), token)
+			if err != nil {
+				var appErr *utils.AppError
+				if errors.As(err, &appErr) && appErr.Code == http.StatusUnauthorized {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+				}
+				return utils.HandleError(c, err)
+			}
+
+			utils.SetCurrentUserID(c, user.ID)
+			utils.SetCurrentUserRole(c, user.Role)
+			utils.SetUserLocale(c, user.Locale)
+			return next(c)
+		}
+	}
+}
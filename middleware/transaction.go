@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"echo-template/database"
+	"echo-template/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errRollbackRequest 处理函数返回错误或错误状态码，用于回滚请求事务
+var errRollbackRequest = errors.New("rollback request transaction")
+
+// Transactional 将整个请求包在一个事务中，处理函数中的服务调用通过请求 context 自动加入该事务
+// 响应状态码 >= 400 或处理函数返回错误时回滚；响应在事务提交后才写出，提交失败时返回 500。
+// 提交时遇到序列化失败或死锁会重放请求体重新执行处理函数，因此处理函数不应有事务之外不可重复的副作用
+func Transactional() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return utils.HandleError(c, utils.ErrRequestTooLarge())
+				}
+				return utils.HandleError(c, utils.ErrBadRequest("request.invalid_body"))
+			}
+
+			res := c.Response()
+			writer := res.Writer
+			header := res.Header().Clone()
+			buffer := &bufferedResponse{header: res.Header()}
+			var handlerErr error
+
+			err = database.Transaction(req.Context(), func(ctx context.Context) error {
+				// 每次执行（包括重试）都从原始请求和空响应开始
+				buffer.reset(header)
+				res.Writer, res.Status, res.Size, res.Committed = buffer, http.StatusOK, 0, false
+				c.SetRequest(req.WithContext(ctx))
+				c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+				handlerErr = next(c)
+				if handlerErr != nil || res.Status >= http.StatusBadRequest {
+					return errRollbackRequest
+				}
+				return nil
+			})
+			res.Writer = writer
+			c.SetRequest(req)
+
+			if err != nil && !errors.Is(err, errRollbackRequest) {
+				buffer.reset(header)
+				res.Committed = false
+				return utils.HandleError(c, utils.ErrInternal("error.transaction_commit", err))
+			}
+			if err := buffer.writeTo(writer); err != nil {
+				return err
+			}
+			return handlerErr
+		}
+	}
+}
+
+// bufferedResponse 暂存状态码和响应体，响应头直接写入原始响应
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) reset(header http.Header) {
+	for name := range b.header {
+		delete(b.header, name)
+	}
+	for name, values := range header {
+		b.header[name] = values
+	}
+	b.status = 0
+	b.body.Reset()
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// Flush 事务提交前不写出任何数据
+func (b *bufferedResponse) Flush() {}
+
+func (b *bufferedResponse) writeTo(w http.ResponseWriter) error {
+	if b.status == 0 {
+		return nil
+	}
+	w.WriteHeader(b.status)
+	_, err := w.Write(b.body.Bytes())
+	return err
+}
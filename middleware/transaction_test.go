@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"echo-template/config"
+	"echo-template/database"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// flakyCommitPool 的事务在提交时按需返回数据库忙，模拟提交阶段的序列化失败
+type flakyCommitPool struct {
+	*sql.DB
+	failures int // 之后连续提交失败的次数
+}
+
+func (p *flakyCommitPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &flakyCommitTx{Tx: tx, pool: p}, nil
+}
+
+type flakyCommitTx struct {
+	*sql.Tx
+	pool *flakyCommitPool
+}
+
+func (tx *flakyCommitTx) Commit() error {
+	if tx.pool.failures > 0 {
+		tx.pool.failures--
+		tx.Tx.Rollback()
+		return sqlite3.Error{Code: sqlite3.ErrBusy}
+	}
+	return tx.Tx.Commit()
+}
+
+type transactionItem struct {
+	ID   uint
+	Name string
+}
+
+// TestTransactional 请求成功时提交，状态码 >= 400 或返回错误时回滚；提交失败时重放请求体重新执行
+func TestTransactional(t *testing.T) {
+	config.AppConfig = &config.Config{
+		Database: config.DatabaseConfig{TxMaxRetries: 2, TxRetryBackoff: time.Millisecond},
+	}
+	sqlDB, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	pool := &flakyCommitPool{DB: sqlDB}
+	db, err := gorm.Open(sqlite.Dialector{Conn: pool}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	if err := db.AutoMigrate(&transactionItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	var bodies []string
+	e := echo.New()
+	e.POST("/items", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, string(body))
+		ctx := c.Request().Context()
+		if err := database.Conn(ctx, database.DB).Create(&transactionItem{Name: string(body)}).Error; err != nil {
+			return err
+		}
+		switch c.QueryParam("result") {
+		case "conflict":
+			return c.String(http.StatusConflict, "conflict")
+		case "error":
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+		}
+		return c.String(http.StatusCreated, "created "+string(body))
+	}, Transactional())
+
+	send := func(body, result string) *httptest.ResponseRecorder {
+		bodies = nil
+		req := httptest.NewRequest(http.MethodPost, "/items?result="+result, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	committed := func() []string {
+		var names []string
+		if err := db.Model(&transactionItem{}).Order("id").Pluck("name", &names).Error; err != nil {
+			t.Fatalf("query items: %v", err)
+		}
+		return names
+	}
+
+	if rec := send("a", ""); rec.Code != http.StatusCreated || rec.Body.String() != "created a" {
+		t.Fatalf("commit: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if rec := send("b", "conflict"); rec.Code != http.StatusConflict || rec.Body.String() != "conflict" {
+		t.Fatalf("error status: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if rec := send("c", "error"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("handler error: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if got := strings.Join(committed(), ","); got != "a" {
+		t.Fatalf("committed items = %q, want a", got)
+	}
+
+	// 提交失败后重新执行处理函数，每次都能读到完整的请求体，响应只写出一次
+	pool.failures = 2
+	rec := send("d", "")
+	if rec.Code != http.StatusCreated || rec.Body.String() != "created d" {
+		t.Fatalf("retried commit: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if strings.Join(bodies, ",") != "d,d,d" {
+		t.Fatalf("handler bodies = %q, want three attempts with the full body", bodies)
+	}
+	if got := strings.Join(committed(), ","); got != "a,d" {
+		t.Fatalf("committed items = %q, want a,d", got)
+	}
+
+	// 重试次数用完后返回 500，不写出处理函数的响应
+	pool.failures = 3
+	rec = send("e", "")
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "created") {
+		t.Fatalf("failed commit: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if got := strings.Join(committed(), ","); got != "a,d" {
+		t.Fatalf("committed items = %q, want a,d", got)
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"echo-template/config"
+	"echo-template/utils"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// API 版本相关的请求头和响应头
+const (
+	HeaderAcceptVersion = "Accept-Version" // 请求的版本，例如 v2 或 2
+	HeaderAPIVersion    = "API-Version"    // 实际使用的版本
+)
+
+// contextKeyAPIVersionError 版本协商失败的原因，由 APIVersion 写入、RejectUnsupportedAPIVersion 返回
+const contextKeyAPIVersionError = "api_version_error"
+
+// 路径中的版本段，例如 v1、v2
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// APIVersion 在路由匹配之前确定 prefix 下请求使用的 API 版本，须通过 e.Pre 注册
+// 优先级：URL 中的版本（/api/v2/users）> Accept-Version 请求头 > Accept 中的厂商媒体类型
+// （application/vnd.<API_VENDOR>.v2+json）> API_DEFAULT_VERSION。
+// 后三种情况把路径改写为带版本的形式，例如 /api/users 按 v2 匹配 /api/v2/users 的路由；
+// 请求头中的版本不受支持时不改写路径，由 RejectUnsupportedAPIVersion 返回错误
+func APIVersion(prefix string, versions ...string) echo.MiddlewareFunc {
+	cfg := config.AppConfig.API
+	defaultVersion := cfg.DefaultVersion
+	if !slices.Contains(versions, defaultVersion) {
+		log.Printf("API_DEFAULT_VERSION %q is not one of %v, using %s", defaultVersion, versions, versions[0])
+		defaultVersion = versions[0]
+	}
+	mediaTypePrefix := "application/vnd." + strings.ToLower(cfg.Vendor) + "."
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			rest, ok := strings.CutPrefix(req.URL.Path, prefix+"/")
+			if !ok {
+				return next(c)
+			}
+			header := c.Response().Header()
+
+			// URL 中已有版本，不支持的版本由路由返回 404
+			if segment, _, _ := strings.Cut(rest, "/"); versionSegment.MatchString(segment) {
+				utils.SetAPIVersion(c, segment)
+				header.Set(HeaderAPIVersion, segment)
+				return next(c)
+			}
+
+			// 同一路径的响应随请求头变化
+			header.Add(echo.HeaderVary, HeaderAcceptVersion)
+			header.Add(echo.HeaderVary, echo.HeaderAccept)
+
+			version, err := requestedAPIVersion(req, mediaTypePrefix, versions)
+			if err != nil {
+				c.Set(contextKeyAPIVersionError, err)
+				return next(c)
+			}
+			if version == "" {
+				version = defaultVersion
+			}
+
+			req.URL.Path = prefix + "/" + version + "/" + rest
+			if raw, ok := strings.CutPrefix(req.URL.RawPath, prefix+"/"); ok {
+				req.URL.RawPath = prefix + "/" + version + "/" + raw
+			}
+			utils.SetAPIVersion(c, version)
+			header.Set(HeaderAPIVersion, version)
+			return next(c)
+		}
+	}
+}
+
+// requestedAPIVersion 从 Accept-Version 和厂商媒体类型中读取请求的版本，都未指定时返回空字符串
+func requestedAPIVersion(req *http.Request, mediaTypePrefix string, versions []string) (string, *utils.AppError) {
+	if value := strings.ToLower(strings.TrimSpace(req.Header.Get(HeaderAcceptVersion))); value != "" {
+		if !strings.HasPrefix(value, "v") {
+			value = "v" + value
+		}
+		if !slices.Contains(versions, value) {
+			return "", unsupportedAPIVersion(http.StatusBadRequest, versions)
+		}
+		return value, nil
+	}
+
+	requested := false
+	for _, item := range strings.Split(req.Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ := strings.Cut(item, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		value, ok := strings.CutPrefix(mediaType, mediaTypePrefix)
+		if !ok {
+			continue
+		}
+		// 结构化后缀表示格式，例如 +json
+		value, _, _ = strings.Cut(value, "+")
+		if slices.Contains(versions, value) {
+			return value, nil
+		}
+		requested = true
+	}
+	if requested {
+		return "", unsupportedAPIVersion(http.StatusNotAcceptable, versions)
+	}
+	return "", nil
+}
+
+func unsupportedAPIVersion(status int, versions []string) *utils.AppError {
+	err := utils.NewAppError(status, "api.unsupported_version", nil)
+	err.Details = map[string][]string{"supported": versions}
+	return err
+}
+
+// RejectUnsupportedAPIVersion 返回 APIVersion 记录的协商错误
+// 注册在 API 路由组上，错误响应和其他响应一样经过全局中间件（请求ID、CORS 等）
+func RejectUnsupportedAPIVersion() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err, ok := c.Get(contextKeyAPIVersionError).(*utils.AppError); ok {
+				return utils.HandleError(c, err)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"echo-template/middleware"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// negotiatedUser 各格式响应中的包装和用户字段
+type negotiatedUser struct {
+	Code int    `json:"code" msgpack:"code" cbor:"code"`
+	Msg  string `json:"msg" msgpack:"msg" cbor:"msg"`
+	Data struct {
+		ID       uint    `json:"id" msgpack:"id" cbor:"id" xml:"id"`
+		Username string  `json:"username" msgpack:"username" cbor:"username" xml:"username"`
+		Name     string  `json:"name" msgpack:"name" cbor:"name" xml:"name"`
+		Locale   *string `json:"locale" msgpack:"locale" cbor:"locale" xml:"locale"`
+		Version  uint    `json:"version" msgpack:"version" cbor:"version" xml:"version"`
+	} `json:"data" msgpack:"data" cbor:"data"`
+}
+
+// xmlUser XML 响应的根元素为 <response>
+type xmlUser struct {
+	XMLName xml.Name `xml:"response"`
+	Code    int      `xml:"code"`
+	Msg     string   `xml:"msg"`
+	Data    struct {
+		ID       uint   `xml:"id"`
+		Username string `xml:"username"`
+		Name     string `xml:"name"`
+		Locale   string `xml:"locale"`
+		Version  uint   `xml:"version"`
+	} `xml:"data"`
+}
+
+// TestContentNegotiation 请求体按 Content-Type 解析，响应按 Accept 编码，各格式的数据与 JSON 一致
+func TestContentNegotiation(t *testing.T) {
+	e, _ := newServer()
+	apiKey := createAdminAPIKey(t)
+
+	formats := []struct {
+		name      string
+		mediaType string
+		encode    func(user map[string]interface{}) []byte
+		decode    func(t *testing.T, body []byte) negotiatedUser
+	}{
+		{
+			name:      "xml",
+			mediaType: "application/xml",
+			encode: func(user map[string]interface{}) []byte {
+				var b strings.Builder
+				b.WriteString("<user>")
+				for _, field := range []string{"username", "email", "name"} {
+					b.WriteString("<" + field + ">" + user[field].(string) + "</" + field + ">")
+				}
+				b.WriteString("</user>")
+				return []byte(b.String())
+			},
+			decode: func(t *testing.T, body []byte) negotiatedUser {
+				var v xmlUser
+				if err := xml.Unmarshal(body, &v); err != nil {
+					t.Fatalf("decode xml %q: %v", body, err)
+				}
+				var user negotiatedUser
+				user.Code, user.Msg = v.Code, v.Msg
+				user.Data.ID, user.Data.Username, user.Data.Name, user.Data.Version = v.Data.ID, v.Data.Username, v.Data.Name, v.Data.Version
+				if v.Data.Locale != "" {
+					user.Data.Locale = &v.Data.Locale
+				}
+				return user
+			},
+		},
+		{
+			name:      "msgpack",
+			mediaType: "application/msgpack",
+			encode: func(user map[string]interface{}) []byte {
+				data, _ := msgpack.Marshal(user)
+				return data
+			},
+			decode: func(t *testing.T, body []byte) negotiatedUser {
+				var user negotiatedUser
+				if err := msgpack.Unmarshal(body, &user); err != nil {
+					t.Fatalf("decode msgpack: %v", err)
+				}
+				return user
+			},
+		},
+		{
+			name:      "cbor",
+			mediaType: "application/cbor",
+			encode: func(user map[string]interface{}) []byte {
+				data, _ := cbor.Marshal(user)
+				return data
+			},
+			decode: func(t *testing.T, body []byte) negotiatedUser {
+				var user negotiatedUser
+				if err := cbor.Unmarshal(body, &user); err != nil {
+					t.Fatalf("decode cbor: %v", err)
+				}
+				return user
+			},
+		},
+	}
+
+	for _, format := range formats {
+		username := "negotiate_" + format.name
+		body := format.encode(map[string]interface{}{
+			"username": username,
+			"email":    username + "@example.com",
+			"name":     "Négociation " + format.name,
+		})
+		rec := serveRequest(e, http.MethodPost, "/api/v1/users", body, middleware.HeaderAPIKey, apiKey,
+			echo.HeaderContentType, format.mediaType, echo.HeaderAccept, format.mediaType)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%s: create status %d, body %q", format.name, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, format.mediaType) {
+			t.Fatalf("%s: Content-Type %q", format.name, got)
+		}
+		created := format.decode(t, rec.Body.Bytes())
+		if created.Code != http.StatusCreated || created.Msg == "" || created.Data.ID == 0 ||
+			created.Data.Username != username || created.Data.Name != "Négociation "+format.name || created.Data.Version != 1 {
+			t.Fatalf("%s: created %+v", format.name, created)
+		}
+		// null 在各格式中都保持为空
+		if created.Data.Locale != nil && *created.Data.Locale != "" {
+			t.Fatalf("%s: locale %q", format.name, *created.Data.Locale)
+		}
+
+		// GET 的 ETag 带格式后缀，条件请求比较时忽略后缀
+		target := "/api/v1/users/" + strconv.FormatUint(uint64(created.Data.ID), 10)
+		rec = serveRequest(e, http.MethodGet, target, nil, echo.HeaderAccept, format.mediaType)
+		etag := rec.Header().Get("ETag")
+		if rec.Code != http.StatusOK || !strings.HasSuffix(etag, "-"+format.name+`"`) {
+			t.Fatalf("%s: get status %d, ETag %q", format.name, rec.Code, etag)
+		}
+		if fetched := format.decode(t, rec.Body.Bytes()); fetched.Data.Username != username {
+			t.Fatalf("%s: fetched %+v", format.name, fetched)
+		}
+		rec = serveRequest(e, http.MethodGet, target, nil, echo.HeaderAccept, "application/json",
+			"If-None-Match", etag)
+		if rec.Code != http.StatusNotModified {
+			t.Fatalf("%s: conditional get status %d", format.name, rec.Code)
+		}
+	}
+
+	// q 值高的格式优先，通配符的优先级低于具体的媒体类型
+	rec := serveRequest(e, http.MethodGet, "/api/v1/users/search?q=negotiate", nil,
+		echo.HeaderAccept, "application/cbor;q=0.5, */*;q=0.9, application/msgpack;q=0.9")
+	if got := rec.Header().Get(echo.HeaderContentType); rec.Code != http.StatusOK || !strings.HasPrefix(got, "application/msgpack") {
+		t.Fatalf("q values: status %d, Content-Type %q", rec.Code, got)
+	}
+
+	// 不支持的请求体格式返回 415，没有可用的响应格式返回 406，data.supported 列出支持的媒体类型
+	for _, tt := range []struct {
+		name        string
+		contentType string
+		accept      string
+		status      int
+	}{
+		{"unsupported content type", "text/csv", "", http.StatusUnsupportedMediaType},
+		{"not acceptable", echo.MIMEApplicationJSON, "image/png", http.StatusNotAcceptable},
+	} {
+		rec := serveRequest(e, http.MethodPost, "/api/v1/users",
+			[]byte(`{"username":"negotiate_rejected","email":"negotiate_rejected@example.com"}`),
+			middleware.HeaderAPIKey, apiKey, echo.HeaderContentType, tt.contentType, echo.HeaderAccept, tt.accept)
+		var response struct {
+			Data struct {
+				Supported []string `json:"supported"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || rec.Code != tt.status {
+			t.Fatalf("%s: status %d, body %q", tt.name, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(strings.Join(response.Data.Supported, ","), "application/cbor") {
+			t.Fatalf("%s: supported %v", tt.name, response.Data.Supported)
+		}
+	}
+	rec = serveRequest(e, http.MethodGet, "/api/v1/users/search?q=negotiate_rejected", nil)
+	if !strings.Contains(rec.Body.String(), `"total":0`) {
+		t.Fatalf("rejected request created a user: %s", rec.Body.String())
+	}
+
+	// 无法解析的请求体返回 400，错误响应同样按 Accept 编码
+	rec = serveRequest(e, http.MethodPost, "/api/v1/users", []byte{0xc1}, middleware.HeaderAPIKey, apiKey,
+		echo.HeaderContentType, "application/msgpack", echo.HeaderAccept, "application/msgpack")
+	var malformed negotiatedUser
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &malformed); err != nil || rec.Code != http.StatusBadRequest ||
+		malformed.Code != http.StatusBadRequest || malformed.Msg == "" {
+		t.Fatalf("malformed msgpack: status %d, %+v, %v", rec.Code, malformed, err)
+	}
+}
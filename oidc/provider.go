@@ -0,0 +1,211 @@
+// Package oidc 实现 OIDC 授权码 + PKCE 登录：读取发现文档、缓存签名密钥（JWKS）、校验 ID Token 和 nonce，并将声明映射为用户信息
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"echo-template/config"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// 登录流程中的错误，由调用方转换为响应
+var (
+	ErrUnavailable     = errors.New("oidc: provider unavailable")           // 无法读取发现文档
+	ErrExchange        = errors.New("oidc: code exchange failed")           // 身份提供方拒绝授权码或 PKCE 校验失败
+	ErrInvalidIDToken  = errors.New("oidc: invalid id token")               // 缺少 ID Token，或签名、签发者、受众、有效期不符
+	ErrNonceMismatch   = errors.New("oidc: nonce mismatch")                 // ID Token 的 nonce 与发起登录时的不一致
+	ErrMissingSubject  = errors.New("oidc: id token has no subject")        // ID Token 缺少 sub
+	ErrUnsupportedFlow = errors.New("oidc: provider does not support S256") // 发现文档声明不支持 S256 的 PKCE
+)
+
+// 访问身份提供方的默认超时
+const defaultTimeout = 10 * time.Second
+
+// Claims 从 ID Token 映射出的用户信息
+type Claims struct {
+	Subject       string // 用户在身份提供方中的唯一标识
+	Email         string
+	EmailVerified bool   // 身份提供方是否确认邮箱属于该用户，只有已验证的邮箱才能关联已有用户
+	Username      string // 建议的用户名
+	Name          string
+	Locale        string
+}
+
+// Provider 一个 OIDC 身份提供方
+// 首次使用时读取发现文档并缓存，失败时下次使用重试；签名密钥由 go-oidc 缓存，遇到未知的 kid 时重新获取
+type Provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider 创建身份提供方，client 为访问身份提供方使用的 HTTP 客户端，为 nil 时使用带超时的默认客户端
+func NewProvider(cfg config.OIDCConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Config 身份提供方的配置
+func (p *Provider) Config() config.OIDCConfig {
+	return p.cfg
+}
+
+// context 使 go-oidc 和 oauth2 使用指定的 HTTP 客户端
+func (p *Provider) context(ctx context.Context) context.Context {
+	return gooidc.ClientContext(ctx, p.client)
+}
+
+// discover 读取并缓存发现文档
+func (p *Provider) discover(ctx context.Context) (*gooidc.Provider, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return p.provider, p.verifier, nil
+	}
+
+	provider, err := gooidc.NewProvider(p.context(ctx), p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrUnavailable, p.cfg.Name, err)
+	}
+	var metadata struct {
+		CodeChallengeMethods []string `json:"code_challenge_methods_supported"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrUnavailable, p.cfg.Name, err)
+	}
+	// 未声明时按支持处理，部分身份提供方支持 PKCE 但不在发现文档中声明
+	if len(metadata.CodeChallengeMethods) > 0 && !slices.Contains(metadata.CodeChallengeMethods, "S256") {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedFlow, p.cfg.Name)
+	}
+
+	p.provider = provider
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return p.provider, p.verifier, nil
+}
+
+func (p *Provider) oauth2Config(provider *gooidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.cfg.Scopes,
+	}
+}
+
+// AuthCodeURL 身份提供方的授权地址，携带 state、nonce 和由 state.Verifier 计算的 S256 PKCE 挑战
+func (p *Provider) AuthCodeURL(ctx context.Context, state *LoginState) (string, error) {
+	provider, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.oauth2Config(provider).AuthCodeURL(state.State,
+		gooidc.Nonce(state.Nonce), oauth2.S256ChallengeOption(state.Verifier)), nil
+}
+
+// Exchange 用授权码和 PKCE 校验码换取令牌，校验 ID Token 的签名、签发者、受众、有效期和 nonce，返回映射后的用户信息
+func (p *Provider) Exchange(ctx context.Context, state *LoginState, code string) (*Claims, error) {
+	provider, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = p.context(ctx)
+	token, err := p.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrExchange, p.cfg.Name, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: %s: token response has no id_token", ErrInvalidIDToken, p.cfg.Name)
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidIDToken, p.cfg.Name, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(state.Nonce)) != 1 {
+		return nil, ErrNonceMismatch
+	}
+	if idToken.Subject == "" {
+		return nil, ErrMissingSubject
+	}
+
+	var raw map[string]interface{}
+	if err := idToken.Claims(&raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidIDToken, p.cfg.Name, err)
+	}
+	return p.mapClaims(idToken.Subject, raw), nil
+}
+
+// mapClaims 按配置的声明名称提取用户信息
+// email_verified 可能为布尔值或字符串；未返回时按 OIDC_<NAME>_TRUST_EMAIL 决定是否视为已验证
+func (p *Provider) mapClaims(subject string, raw map[string]interface{}) *Claims {
+	claims := &Claims{
+		Subject:  subject,
+		Email:    stringClaim(raw, p.cfg.EmailClaim),
+		Username: stringClaim(raw, p.cfg.UsernameClaim),
+		Name:     stringClaim(raw, p.cfg.NameClaim),
+		Locale:   stringClaim(raw, "locale"),
+	}
+	switch verified := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	default:
+		claims.EmailVerified = p.cfg.TrustEmail
+	}
+	if claims.Email == "" {
+		claims.EmailVerified = false
+	}
+	return claims
+}
+
+func stringClaim(raw map[string]interface{}, name string) string {
+	value, _ := raw[name].(string)
+	return value
+}
+
+// Registry 按名称查找已登记的身份提供方
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry 为每个配置创建身份提供方，client 的含义同 NewProvider
+func NewRegistry(configs map[string]config.OIDCConfig, client *http.Client) *Registry {
+	registry := &Registry{providers: make(map[string]*Provider, len(configs))}
+	for name, cfg := range configs {
+		registry.providers[name] = NewProvider(cfg, client)
+	}
+	return registry
+}
+
+// Get 按名称查找身份提供方
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// List 全部身份提供方，按名称排序
+func (r *Registry) List() []*Provider {
+	providers := make([]*Provider, 0, len(r.providers))
+	for _, provider := range r.providers {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].cfg.Name < providers[j].cfg.Name })
+	return providers
+}
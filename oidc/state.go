@@ -0,0 +1,103 @@
+package oidc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrInvalidState 登录状态无法解密、已过期，或与回调参数不一致
+var ErrInvalidState = errors.New("oidc: invalid login state")
+
+// LoginState 从跳转到身份提供方到回调之间需要保存的数据
+// 以 AES-GCM 加密后存放在 Cookie 中，服务端不保存会话；PKCE 校验码只在服务端使用，不会出现在跳转地址中
+type LoginState struct {
+	Provider   string    `json:"p"`
+	State      string    `json:"s"`
+	Nonce      string    `json:"n"`
+	Verifier   string    `json:"v"`
+	ExpiresAt  time.Time `json:"e"`
+	LinkUserID uint      `json:"u,omitempty"` // 发起关联的已登录用户，回调时将身份关联到该用户；为 0 时为普通登录
+}
+
+// NewLoginState 为一次登录生成随机的 state、nonce 和 PKCE 校验码
+func NewLoginState(provider string, ttl time.Duration) (*LoginState, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	return &LoginState{
+		Provider:  provider,
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// Seal 加密登录状态，key 须为 32 字节
+func (s *LoginState) Seal(key []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(s.Provider))), nil
+}
+
+// OpenLoginState 解密登录状态，并校验其属于 provider 且未过期
+func OpenLoginState(key []byte, provider, sealed string) (*LoginState, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrInvalidState
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(provider))
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+
+	var state LoginState
+	if err := json.Unmarshal(plaintext, &state); err != nil || state.Provider != provider || !time.Now().Before(state.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+	return &state, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// randomString 32 字节随机数的 base64url 编码
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
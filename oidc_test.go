@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/client"
+	"echo-template/config"
+	"echo-template/oidc/oidctest"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// oidcTestEnv 完整的服务和测试身份提供方：corp 允许自动注册，closed 不允许
+type oidcTestEnv struct {
+	t      *testing.T
+	idp    *oidctest.Server
+	server *httptest.Server
+	http   *http.Client // 不跟随跳转，由测试逐步完成登录流程
+	admin  *client.Client
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+	idp := oidctest.NewServer("echo-template", "secret")
+	t.Cleanup(idp.Close)
+
+	// 回调地址须在创建服务前确定，先启动 httptest 服务再设置处理器
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	providers := config.AppConfig.Auth.OIDCProviders
+	t.Cleanup(func() { config.AppConfig.Auth.OIDCProviders = providers })
+	config.AppConfig.Auth.OIDCProviders = make(map[string]config.OIDCConfig)
+	for name, allowSignup := range map[string]bool{"corp": true, "closed": false} {
+		config.AppConfig.Auth.OIDCProviders[name] = config.OIDCConfig{
+			Name:          name,
+			Issuer:        idp.Issuer(),
+			ClientID:      idp.ClientID,
+			ClientSecret:  idp.ClientSecret,
+			RedirectURL:   server.URL + "/api/v1/auth/oidc/" + name + "/callback",
+			Scopes:        []string{"openid", "email", "profile"},
+			AllowSignup:   allowSignup,
+			UsernameClaim: "preferred_username",
+			NameClaim:     "name",
+			EmailClaim:    "email",
+		}
+	}
+	e, _ := newServer()
+	handler = e
+
+	return &oidcTestEnv{
+		t:      t,
+		idp:    idp,
+		server: server,
+		http: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}},
+		admin: client.New(server.URL+"/api", client.WithAuth(client.APIKeyAuth(createAdminAPIKey(t)))),
+	}
+}
+
+// oidcResponse 登录回调的响应
+type oidcResponse struct {
+	status int
+	Msg    string             `json:"msg"`
+	Data   client.LoginResult `json:"data"`
+}
+
+// do 发送请求，返回响应和响应体
+func (env *oidcTestEnv) do(req *http.Request) (*http.Response, []byte) {
+	env.t.Helper()
+	res, err := env.http.Do(req)
+	if err != nil {
+		env.t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		env.t.Fatalf("read %s: %v", req.URL, err)
+	}
+	return res, body
+}
+
+// stateCookie 响应设置的登录状态 Cookie
+func (env *oidcTestEnv) stateCookie(res *http.Response) *http.Cookie {
+	env.t.Helper()
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "oidc_state" && cookie.Value != "" {
+			return cookie
+		}
+	}
+	env.t.Fatalf("response sets no oidc_state cookie")
+	return nil
+}
+
+// authorize 打开身份提供方的授权地址，返回身份提供方跳转回的回调地址
+func (env *oidcTestEnv) authorize(authURL string) string {
+	env.t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, authURL, nil)
+	res, _ := env.do(req)
+	if res.StatusCode != http.StatusFound {
+		env.t.Fatalf("authorize: status %d", res.StatusCode)
+	}
+	return res.Header.Get("Location")
+}
+
+// startLogin 发起登录，返回回调地址和登录状态 Cookie
+func (env *oidcTestEnv) startLogin(provider string) (string, *http.Cookie) {
+	env.t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, env.server.URL+"/api/v1/auth/oidc/"+provider+"/login", nil)
+	res, _ := env.do(req)
+	if res.StatusCode != http.StatusFound {
+		env.t.Fatalf("login: status %d", res.StatusCode)
+	}
+	return env.authorize(res.Header.Get("Location")), env.stateCookie(res)
+}
+
+// startLink 以 API Key 认证的用户发起关联，返回回调地址和登录状态 Cookie
+func (env *oidcTestEnv) startLink(provider, apiKey string) (string, *http.Cookie) {
+	env.t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, env.server.URL+"/api/v1/auth/oidc/"+provider+"/link", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	res, body := env.do(req)
+	if res.StatusCode != http.StatusOK {
+		env.t.Fatalf("link: status %d: %s", res.StatusCode, body)
+	}
+	var result struct {
+		Data struct {
+			AuthURL string `json:"auth_url"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.Data.AuthURL == "" {
+		env.t.Fatalf("link: unexpected response %s", body)
+	}
+	return env.authorize(result.Data.AuthURL), env.stateCookie(res)
+}
+
+// callback 携带登录状态 Cookie 访问回调地址
+func (env *oidcTestEnv) callback(callbackURL string, cookie *http.Cookie) oidcResponse {
+	env.t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, callbackURL, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	res, body := env.do(req)
+	result := oidcResponse{status: res.StatusCode}
+	if err := json.Unmarshal(body, &result); err != nil {
+		env.t.Fatalf("callback: decode %s: %v", body, err)
+	}
+	return result
+}
+
+// login 以 claims 完成一次登录
+func (env *oidcTestEnv) login(provider string, claims map[string]interface{}) oidcResponse {
+	env.t.Helper()
+	env.idp.SetClaims(claims)
+	return env.callback(env.startLogin(provider))
+}
+
+// expectStatus 断言登录结果的状态码
+func (r oidcResponse) expectStatus(t *testing.T, status int) oidcResponse {
+	t.Helper()
+	if r.status != status {
+		t.Fatalf("status = %d (%s), want %d", r.status, r.Msg, status)
+	}
+	return r
+}
+
+func verifiedClaims(subject, email string) map[string]interface{} {
+	return map[string]interface{}{"sub": subject, "email": email, "email_verified": true}
+}
+
+func TestOIDC_SignupAndLogin(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	claims := verifiedClaims("jit-1", "oidc_jit@example.com")
+	claims["preferred_username"] = "oidc_jit"
+	claims["name"] = "JIT User"
+
+	first := env.login("corp", claims).expectStatus(t, http.StatusOK)
+	if !first.Data.Created || first.Data.User.Username != "oidc_jit" || first.Data.User.Name != "JIT User" ||
+		first.Data.TokenType != "Bearer" || first.Data.AccessToken == "" {
+		t.Fatalf("unexpected login result: %+v", first.Data)
+	}
+
+	// 访问令牌可用于认证
+	me, err := client.New(env.server.URL+"/api", client.WithAuth(client.BearerToken(first.Data.AccessToken))).
+		GetCurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("get current user: %v", err)
+	}
+	if me.ID != first.Data.User.ID {
+		t.Fatalf("current user = %d, want %d", me.ID, first.Data.User.ID)
+	}
+
+	// 再次登录使用已关联的用户
+	again := env.login("corp", claims).expectStatus(t, http.StatusOK)
+	if again.Data.Created || again.Data.User.ID != first.Data.User.ID {
+		t.Fatalf("relogin: created %v, user %d, want user %d", again.Data.Created, again.Data.User.ID, first.Data.User.ID)
+	}
+
+	// 身份提供方拒绝登录
+	env.login("corp", nil).expectStatus(t, http.StatusUnauthorized)
+}
+
+func TestOIDC_VerifiedEmailLinksExistingUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	existing, err := env.admin.CreateUser(context.Background(),
+		client.User{Username: "oidc_linked", Email: "oidc_linked@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	// 邮箱比较不区分大小写
+	result := env.login("corp", verifiedClaims("link-1", "OIDC_Linked@example.com")).expectStatus(t, http.StatusOK)
+	if result.Data.Created || result.Data.User.ID != existing.ID {
+		t.Fatalf("linked user = %d (created %v), want %d", result.Data.User.ID, result.Data.Created, existing.ID)
+	}
+
+	// 同一用户不能通过邮箱再关联同一身份提供方的其他身份
+	env.login("corp", verifiedClaims("link-2", "oidc_linked@example.com")).expectStatus(t, http.StatusConflict)
+}
+
+func TestOIDC_UnverifiedEmailOfExistingUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	if _, err := env.admin.CreateUser(context.Background(),
+		client.User{Username: "oidc_unverified", Email: "oidc_unverified@example.com"}, nil); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	claims := verifiedClaims("unverified-1", "oidc_unverified@example.com")
+	claims["email_verified"] = false
+	env.login("corp", claims).expectStatus(t, http.StatusConflict)
+
+	// 未关联，也没有创建用户
+	users, err := env.admin.SearchUsers(context.Background(), &client.SearchUsersParams{Q: "oidc_unverified"})
+	if err != nil {
+		t.Fatalf("search users: %v", err)
+	}
+	if users.Total != 1 {
+		t.Fatalf("users with the email = %d, want 1", users.Total)
+	}
+}
+
+func TestOIDC_SignupDisabled(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.login("closed", verifiedClaims("closed-1", "oidc_closed@example.com")).expectStatus(t, http.StatusForbidden)
+}
+
+func TestOIDC_AdminRequiresExplicitLink(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	ctx := context.Background()
+	admin, err := env.admin.CreateUser(ctx, client.User{Username: "oidc_admin", Email: "oidc_admin@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := services.NewUserService().AssignRole(ctx, uint(admin.ID), models.RoleAdmin); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+
+	// 即使邮箱已验证，也不按邮箱关联管理员
+	claims := verifiedClaims("admin-1", "oidc_admin@example.com")
+	env.login("corp", claims).expectStatus(t, http.StatusConflict)
+
+	// 管理员登录后显式关联，之后可以通过身份提供方登录
+	adminKey := createUserAPIKey(t, uint(admin.ID))
+	env.idp.SetClaims(claims)
+	linked := env.callback(env.startLink("corp", adminKey)).expectStatus(t, http.StatusOK)
+	if linked.Data.Created || linked.Data.User.ID != admin.ID {
+		t.Fatalf("linked user = %d, want %d", linked.Data.User.ID, admin.ID)
+	}
+	relogin := env.login("corp", claims).expectStatus(t, http.StatusOK)
+	if relogin.Data.User.ID != admin.ID {
+		t.Fatalf("login user = %d, want %d", relogin.Data.User.ID, admin.ID)
+	}
+
+	// 已关联的身份不能再关联到其他用户
+	other, err := env.admin.CreateUser(ctx, client.User{Username: "oidc_other", Email: "oidc_other@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	env.idp.SetClaims(claims)
+	env.callback(env.startLink("corp", createUserAPIKey(t, uint(other.ID)))).expectStatus(t, http.StatusConflict)
+
+	// 未认证时不能发起关联
+	req, _ := http.NewRequest(http.MethodPost, env.server.URL+"/api/v1/auth/oidc/corp/link", nil)
+	if res, _ := env.do(req); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("anonymous link: status %d, want 401", res.StatusCode)
+	}
+}
+
+func TestOIDC_NonceMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	claims := verifiedClaims("nonce-1", "oidc_nonce@example.com")
+	claims["nonce"] = "forged"
+	result := env.login("corp", claims).expectStatus(t, http.StatusUnauthorized)
+	if result.Data.AccessToken != "" {
+		t.Fatal("access token issued for a mismatched nonce")
+	}
+}
+
+func TestOIDC_ReplayedCode(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.idp.SetClaims(verifiedClaims("replay-1", "oidc_replay@example.com"))
+	callbackURL, cookie := env.startLogin("corp")
+	env.callback(callbackURL, cookie).expectStatus(t, http.StatusOK)
+
+	// 同一授权码和登录状态再次使用，身份提供方拒绝兑换
+	env.callback(callbackURL, cookie).expectStatus(t, http.StatusUnauthorized)
+
+	// 没有登录状态或 state 不一致
+	env.callback(callbackURL, nil).expectStatus(t, http.StatusBadRequest)
+	callbackURL, cookie = env.startLogin("corp")
+	tampered, _ := url.Parse(callbackURL)
+	query := tampered.Query()
+	query.Set("state", "tampered")
+	tampered.RawQuery = query.Encode()
+	env.callback(tampered.String(), cookie).expectStatus(t, http.StatusBadRequest)
+}
+
+func TestOIDC_RotateKey(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	claims := verifiedClaims("rotate-1", "oidc_rotate@example.com")
+
+	env.login("corp", claims).expectStatus(t, http.StatusOK)
+	fetched := env.idp.JWKSRequests()
+	if fetched == 0 {
+		t.Fatal("JWKS was not fetched")
+	}
+	// 签名密钥已缓存
+	env.login("corp", claims).expectStatus(t, http.StatusOK)
+	if got := env.idp.JWKSRequests(); got != fetched {
+		t.Fatalf("JWKS requests = %d, want %d (cached)", got, fetched)
+	}
+
+	// 新的 kid 触发重新获取
+	env.idp.RotateKey()
+	env.login("corp", claims).expectStatus(t, http.StatusOK)
+	if got := env.idp.JWKSRequests(); got != fetched+1 {
+		t.Fatalf("JWKS requests after rotation = %d, want %d", got, fetched+1)
+	}
+}
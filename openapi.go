@@ -0,0 +1,84 @@
+package main
+
+import (
+	"echo-template/openapi"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/yaml"
+)
+
+var openAPICommand = &cli.Command{
+	Name:  "openapi",
+	Usage: "导出 OpenAPI 3.1 文档，检查文档与路由是否一致",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "export",
+			Usage: "导出由 Swagger 2.0 文档转换的 OpenAPI 3.1 文档",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "format", Value: "json", Usage: "输出格式：json | yaml"},
+				&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "输出文件，默认输出到标准输出"},
+				&cli.StringSliceFlag{Name: "server", Usage: "写入文档的服务地址，例如 https://api.example.com/api，可重复"},
+			},
+			Action: exportOpenAPI,
+		},
+		{
+			Name:  "check",
+			Usage: "检查文档中的接口与注册的路由是否一致，不一致时以非零状态退出，用于 CI",
+			Action: func(c *cli.Context) error {
+				doc, err := openapi.Document()
+				if err != nil {
+					return err
+				}
+
+				_, table := newServer()
+				var routes []openapi.Route
+				for _, route := range table.Routes() {
+					routes = append(routes, openapi.Route{Method: route.Method, Path: route.Path})
+				}
+
+				mismatches := openapi.CheckRoutes(doc, routes)
+				for _, m := range mismatches {
+					fmt.Fprintln(c.App.Writer, m)
+				}
+				if len(mismatches) > 0 {
+					return cli.Exit(fmt.Sprintf("%d mismatch(es) between the API document and the routes, "+
+						"add or fix the swag annotations and regenerate docs", len(mismatches)), 1)
+				}
+				fmt.Fprintln(c.App.Writer, "API document matches the routes")
+				return nil
+			},
+		},
+	},
+}
+
+func exportOpenAPI(c *cli.Context) error {
+	doc, err := openapi.Document()
+	if err != nil {
+		return err
+	}
+	doc = openapi.WithServers(doc, c.StringSlice("server")...)
+
+	var data []byte
+	switch c.String("format") {
+	case "json":
+		if data, err = json.MarshalIndent(doc, "", "  "); err == nil {
+			data = append(data, '\n')
+		}
+	case "yaml":
+		data, err = yaml.Marshal(doc)
+	default:
+		return fmt.Errorf("unknown format %q", c.String("format"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if output := c.String("output"); output != "" {
+		return os.WriteFile(output, data, 0o644)
+	}
+	_, err = c.App.Writer.Write(data)
+	return err
+}
@@ -0,0 +1,250 @@
+// Package openapi 由 swag 生成的 Swagger 2.0 文档转换 OpenAPI 3.1 文档，并提供文档与路由的一致性检查
+package openapi
+
+import (
+	"context"
+	"echo-template/docs"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Version 输出的 OpenAPI 版本
+const Version = "3.1.0"
+
+var (
+	document     *openapi3.T
+	documentErr  error
+	documentOnce sync.Once
+)
+
+// Document 返回转换后的 OpenAPI 3.1 文档，首次调用时转换并缓存
+// 返回的文档是共享的，调用方不能修改；需要按请求设置 servers 时使用 WithServers
+func Document() (*openapi3.T, error) {
+	documentOnce.Do(func() {
+		document, documentErr = Convert([]byte(docs.SwaggerInfo.ReadDoc()))
+	})
+	return document, documentErr
+}
+
+// BasePath 文档中接口路径的前缀，即 swag 注释中的 @BasePath
+func BasePath() string {
+	return docs.SwaggerInfo.BasePath
+}
+
+// Convert 将 Swagger 2.0 文档（JSON）转换为 OpenAPI 3.1 文档
+// 先由 kin-openapi 转换为 3.0，再把 3.0 的 nullable 改写为 3.1 的 type 数组
+func Convert(swagger2 []byte) (*openapi3.T, error) {
+	var doc2 openapi2.T
+	if err := json.Unmarshal(swagger2, &doc2); err != nil {
+		return nil, fmt.Errorf("parse swagger 2.0 document: %w", err)
+	}
+	// host 和 schemes 按请求生成，不写入转换后的文档
+	doc2.Host = ""
+	doc2.Schemes = nil
+
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("convert to openapi 3: %w", err)
+	}
+	// kin-openapi 的文档校验只支持 3.0 的写法，在改写为 3.1 之前校验
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi document: %w", err)
+	}
+
+	doc.OpenAPI = Version
+	upgrade := &schemaUpgrader{visited: map[*openapi3.Schema]bool{}}
+	upgrade.document(doc)
+	return doc, nil
+}
+
+// WithServers 返回设置了 servers 的文档副本（浅拷贝）
+func WithServers(doc *openapi3.T, urls ...string) *openapi3.T {
+	copied := *doc
+	copied.Servers = make(openapi3.Servers, len(urls))
+	for i, url := range urls {
+		copied.Servers[i] = &openapi3.Server{URL: url}
+	}
+	return &copied
+}
+
+// schemaUpgrader 遍历文档中的全部 schema，nullable: true 改写为 type 中包含 "null"
+type schemaUpgrader struct {
+	visited map[*openapi3.Schema]bool
+}
+
+func (u *schemaUpgrader) document(doc *openapi3.T) {
+	if doc.Components != nil {
+		for _, ref := range doc.Components.Schemas {
+			u.schema(ref)
+		}
+		for _, ref := range doc.Components.Parameters {
+			if ref.Value != nil {
+				u.parameter(ref.Value)
+			}
+		}
+		for _, ref := range doc.Components.RequestBodies {
+			if ref.Value != nil {
+				u.content(ref.Value.Content)
+			}
+		}
+		for _, ref := range doc.Components.Responses {
+			if ref.Value != nil {
+				u.content(ref.Value.Content)
+			}
+		}
+	}
+	if doc.Paths == nil {
+		return
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, ref := range item.Parameters {
+			if ref.Value != nil {
+				u.parameter(ref.Value)
+			}
+		}
+		for _, op := range item.Operations() {
+			for _, ref := range op.Parameters {
+				if ref.Value != nil {
+					u.parameter(ref.Value)
+				}
+			}
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				u.content(op.RequestBody.Value.Content)
+			}
+			if op.Responses != nil {
+				for _, ref := range op.Responses.Map() {
+					if ref.Value != nil {
+						u.content(ref.Value.Content)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (u *schemaUpgrader) parameter(p *openapi3.Parameter) {
+	u.schema(p.Schema)
+	u.content(p.Content)
+}
+
+func (u *schemaUpgrader) content(content openapi3.Content) {
+	for _, media := range content {
+		u.schema(media.Schema)
+	}
+}
+
+func (u *schemaUpgrader) schema(ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Value == nil || u.visited[ref.Value] {
+		return
+	}
+	s := ref.Value
+	u.visited[s] = true
+
+	if s.Nullable {
+		s.Nullable = false
+		if s.Type != nil && !s.Type.Includes(openapi3.TypeNull) {
+			types := append(s.Type.Slice(), openapi3.TypeNull)
+			s.Type = (*openapi3.Types)(&types)
+		}
+	}
+
+	for _, list := range []openapi3.SchemaRefs{s.OneOf, s.AnyOf, s.AllOf} {
+		for _, item := range list {
+			u.schema(item)
+		}
+	}
+	u.schema(s.Not)
+	u.schema(s.Items)
+	u.schema(s.AdditionalProperties.Schema)
+	for _, prop := range s.Properties {
+		u.schema(prop)
+	}
+}
+
+// Route 注册的路由
+type Route struct {
+	Method string
+	Path   string // Echo 路由路径，例如 /api/v1/users/:id
+}
+
+// Mismatch 文档与路由不一致之处
+type Mismatch struct {
+	Method string
+	Path   string // 文档中的路径，例如 /v1/users/{id}
+	Reason string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s %s: %s", m.Method, m.Path, m.Reason)
+}
+
+// 不一致的原因
+const (
+	ReasonUndocumented = "route is not documented"
+	ReasonNoRoute      = "documented operation has no route"
+)
+
+var routeParam = regexp.MustCompile(`:([^/]+)`)
+
+// SpecPath 将 Echo 路由路径转换为文档中的路径，例如 /api/v1/users/:id 转换为 /v1/users/{id}
+// 路由不在 BasePath 下时返回 false
+func SpecPath(routePath string) (string, bool) {
+	base := strings.TrimSuffix(BasePath(), "/")
+	if base != "" {
+		if routePath != base && !strings.HasPrefix(routePath, base+"/") {
+			return "", false
+		}
+		routePath = strings.TrimPrefix(routePath, base)
+	}
+	if routePath == "" {
+		routePath = "/"
+	}
+	return routeParam.ReplaceAllString(routePath, "{$1}"), true
+}
+
+// CheckRoutes 对比文档中的操作和 BasePath 下注册的路由，返回按路径和方法排序的不一致之处
+// BasePath 之外的路由（例如 /health、/swagger）不参与检查
+func CheckRoutes(doc *openapi3.T, routes []Route) []Mismatch {
+	registered := map[[2]string]bool{}
+	for _, route := range routes {
+		if path, ok := SpecPath(route.Path); ok {
+			registered[[2]string{route.Method, path}] = true
+		}
+	}
+
+	documented := map[[2]string]bool{}
+	if doc.Paths != nil {
+		for path, item := range doc.Paths.Map() {
+			for method := range item.Operations() {
+				documented[[2]string{method, path}] = true
+			}
+		}
+	}
+
+	var mismatches []Mismatch
+	for key := range registered {
+		if !documented[key] {
+			mismatches = append(mismatches, Mismatch{Method: key[0], Path: key[1], Reason: ReasonUndocumented})
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			mismatches = append(mismatches, Mismatch{Method: key[0], Path: key[1], Reason: ReasonNoRoute})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].Path != mismatches[j].Path {
+			return mismatches[i].Path < mismatches[j].Path
+		}
+		return mismatches[i].Method < mismatches[j].Method
+	})
+	return mismatches
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"echo-template/openapi"
+	"testing"
+)
+
+// TestOpenAPIMatchesRoutes 文档中的接口与注册的路由一致，与 openapi check 命令相同
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	doc, err := openapi.Document()
+	if err != nil {
+		t.Fatalf("load API document: %v", err)
+	}
+
+	_, table := newServer()
+	var routes []openapi.Route
+	for _, route := range table.Routes() {
+		routes = append(routes, openapi.Route{Method: route.Method, Path: route.Path})
+	}
+	if len(routes) == 0 {
+		t.Fatal("no routes registered")
+	}
+
+	for _, mismatch := range openapi.CheckRoutes(doc, routes) {
+		t.Error(mismatch)
+	}
+}
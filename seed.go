@@ -0,0 +1,39 @@
+package main
+
+import (
+	"echo-template/app/seeders"
+	"echo-template/config"
+	"echo-template/database"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var seedCommand = &cli.Command{
+	Name:      "seed",
+	Usage:     "填充数据，重复执行不会产生重复数据",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: "set", Usage: "数据集，默认为当前运行环境"},
+		&cli.StringSliceFlag{Name: "only", Usage: "只执行指定的填充器，可重复或逗号分隔"},
+		&cli.BoolFlag{Name: "list", Usage: "列出数据集中的填充器"},
+	},
+	Action: func(c *cli.Context) error {
+		set := c.String("set")
+		if set == "" {
+			set = config.AppConfig.Env
+		}
+
+		if c.Bool("list") {
+			for _, seeder := range seeders.All(set) {
+				fmt.Fprintln(c.App.Writer, seeder.Name)
+			}
+			return nil
+		}
+
+		if err := setupDatabase(true); err != nil {
+			return err
+		}
+		return seeders.Run(c.Context, database.GetDB(), set, c.StringSlice("only")...)
+	},
+}
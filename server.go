@@ -1,24 +1,22 @@
 package main
 
 import (
-	"echo-template/app/models"
+	"context"
+	"echo-template/app/jobs"
 	"echo-template/app/routes"
+	"echo-template/app/services"
 	"echo-template/config"
-	"echo-template/database"
-	"echo-template/docs"
 	"echo-template/middleware"
+	"echo-template/utils"
+	"errors"
 	"log"
+	"net/http"
+	"os"
 
 	"github.com/labstack/echo/v4"
+	"github.com/urfave/cli/v2"
 )
 
-func init() {
-	// 初始化 Swagger 文档
-	docs.SwaggerInfo.Host = "localhost:1323"
-	docs.SwaggerInfo.BasePath = "/api"
-	docs.SwaggerInfo.Schemes = []string{"http", "https"}
-}
-
 // @title           Echo Template API
 // @version         1.0
 // @description     基于 Echo 框架的 MVC 架构 API 文档
@@ -31,41 +29,84 @@ func init() {
 // @license.name  Apache 2.0
 // @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
 
-// @host      localhost:1323
 // @BasePath  /api
 
-// @schemes   http https
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API Key，格式为 <前缀>_<标识>_<密钥>，例如 etk_3f9a1c2b_...
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Bearer 令牌：Authorization: Bearer <API Key 或 OIDC 登录获得的访问令牌>
 
 func main() {
-	// 加载配置
-	if err := config.LoadConfig(); err != nil {
-		log.Fatal("Failed to load config:", err)
+	if err := newApp().Run(os.Args); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	// 初始化数据库
-	if err := database.InitDB(); err != nil {
-		log.Fatal("Failed to initialize database:", err)
+var serveCommand = &cli.Command{
+	Name:  "serve",
+	Usage: "启动 HTTP 服务",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "migrate", Value: true, Usage: "启动前自动迁移数据库"},
+	},
+	Action: serve,
+}
+
+func serve(c *cli.Context) error {
+	if err := setupDatabase(c.Bool("migrate")); err != nil {
+		return err
 	}
 
-	// 自动迁移数据库表
-	db := database.GetDB()
-	if err := db.AutoMigrate(&models.User{}); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	// 后台任务
+	jobs.StartPurgeDeletedUsers(context.Background(), services.NewUserService())
+
+	e, _ := newServer()
+
+	// 启动服务器
+	serverAddr := config.AppConfig.Server.Host + ":" + config.AppConfig.Server.Port
+	log.Printf("Server starting on %s", serverAddr)
+	if err := e.Start(serverAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
+}
 
-	// 创建 Echo 实例
+// newServer 创建 Echo 实例，注册中间件和路由，并返回记录了路由信息的路由表
+func newServer() (*echo.Echo, *routes.RouteTable) {
 	e := echo.New()
+	e.IPExtractor = middleware.IPExtractor()
+	// 请求体按 Content-Type 解析 JSON、XML、MessagePack 和 CBOR
+	e.Binder = &utils.Binder{}
+
+	// 慢速客户端防护
+	e.Server.ReadTimeout = config.AppConfig.Server.ReadTimeout
+	e.Server.ReadHeaderTimeout = config.AppConfig.Server.ReadHeaderTimeout
+	e.Server.WriteTimeout = config.AppConfig.Server.WriteTimeout
+	e.Server.IdleTimeout = config.AppConfig.Server.IdleTimeout
 
 	// 注册中间件
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	global := []echo.MiddlewareFunc{
+		middleware.RequestID(),
+		middleware.Envelope(),
+		middleware.Locale(),
+		middleware.Logger(),
+		middleware.Recover(),
+		middleware.Secure(),
+		middleware.CORS(),
+		middleware.BodyLimit(),
+		middleware.Compress(),
+		middleware.AuditContext(),
+		middleware.APIKeyAuth(services.NewAPIKeyService()),
+		middleware.TokenAuth(services.NewAuthService()),
+	}
+	e.Use(global...)
 
 	// 注册路由
+	table := routes.NewRouteTable(e, global...)
 	routes.InitRoutes(e)
-
-	// 启动服务器
-	serverAddr := config.AppConfig.Server.Host + ":" + config.AppConfig.Server.Port
-	log.Printf("Server starting on %s", serverAddr)
-	e.Logger.Fatal(e.Start(serverAddr))
+	return e, table
 }
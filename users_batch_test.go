@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"echo-template/app/models"
+	"echo-template/app/services"
+	"echo-template/client"
+	"echo-template/database"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// failUserUpdates 让之后第 n 次更新 users 表的语句返回数据库忙，模拟需要整体重试的事务错误
+func failUserUpdates(t *testing.T, n int) *int {
+	t.Helper()
+	name := "test:" + t.Name()
+	count := 0
+	err := database.DB.Callback().Update().After("gorm:update").Register(name, func(db *gorm.DB) {
+		if db.Statement.Table != "users" {
+			return
+		}
+		count++
+		if count == n {
+			db.AddError(sqlite3.Error{Code: sqlite3.ErrBusy})
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	t.Cleanup(func() { database.DB.Callback().Update().Remove(name) })
+	return &count
+}
+
+// TestUsers_BatchRetry 事务重试时从原始数据重新执行，已执行过的更新不会因为版本号被改写而冲突
+func TestUsers_BatchRetry(t *testing.T) {
+	ctx := context.Background()
+	userService := services.NewUserService()
+	alice := &models.User{Username: "batch_retry_alice", Email: "batch_retry_alice@example.com"}
+	bob := &models.User{Username: "batch_retry_bob", Email: "batch_retry_bob@example.com"}
+	for _, user := range []*models.User{alice, bob} {
+		if err := userService.CreateUser(ctx, user); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	update := func(user *models.User, name string) services.BatchUserOperation {
+		changed := *user
+		changed.Name = name
+		return services.BatchUserOperation{Op: services.BatchOpUpdate, ID: user.ID, User: &changed}
+	}
+	ops := []services.BatchUserOperation{
+		update(alice, "Alice"),
+		{Op: services.BatchOpCreate, User: &models.User{Username: "batch_retry_carol", Email: "batch_retry_carol@example.com"}},
+		update(bob, "Bob"),
+	}
+	// 第一次尝试中 bob 的更新失败，整个事务重试
+	updates := failUserUpdates(t, 2)
+
+	result, err := userService.BatchUsers(ctx, ops, services.BatchOptions{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("batch users: %v", err)
+	}
+	if *updates != 4 {
+		t.Fatalf("users updated %d times, want 4 (two attempts)", *updates)
+	}
+	if result.Succeeded != len(ops) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// 调用方的数据没有被修改
+	if ops[0].User.Version != alice.Version || ops[2].User.Version != bob.Version || ops[1].User.ID != 0 {
+		t.Fatalf("operations were modified: %+v, %+v", ops[0].User, ops[1].User)
+	}
+
+	for _, want := range []struct {
+		user *models.User
+		name string
+	}{{alice, "Alice"}, {bob, "Bob"}} {
+		current, err := userService.GetUserByID(ctx, want.user.ID)
+		if err != nil {
+			t.Fatalf("get user: %v", err)
+		}
+		if current.Name != want.name || current.Version != want.user.Version+1 {
+			t.Fatalf("user %d: name %q, version %d, want %q, %d", current.ID, current.Name, current.Version, want.name, want.user.Version+1)
+		}
+	}
+	var created int64
+	database.DB.Model(&models.User{}).Where("username = ?", "batch_retry_carol").Count(&created)
+	if created != 1 || result.Items[1].ID == 0 {
+		t.Fatalf("created %d users, id %d", created, result.Items[1].ID)
+	}
+}
+
+// TestUsers_BatchModes 原子模式下任一项失败全部回滚，部分成功模式下只回滚失败项
+func TestUsers_BatchModes(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	if _, err := c.CreateUser(ctx, client.User{Username: "batch_mode_taken", Email: "batch_mode_taken@example.com"}, nil); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	operations := []client.BatchUserOperation{
+		{Op: client.BatchOpCreate, User: &client.User{Username: "batch_mode_new", Email: "batch_mode_new@example.com"}},
+		{Op: client.BatchOpCreate, User: &client.User{Username: "batch_mode_taken", Email: "batch_mode_other@example.com"}},
+		{Op: client.BatchOpDelete, ID: 1 << 30},
+	}
+	statuses := func(items []client.BatchItemResult) []int64 {
+		result := make([]int64, len(items))
+		for i, item := range items {
+			result[i] = item.Status
+		}
+		return result
+	}
+
+	// 原子模式：用户名冲突导致整批回滚，其余项为 424
+	_, err := c.BatchUsers(ctx, client.BatchUsersRequest{Operations: operations}, nil)
+	var atomic client.BatchResult
+	if err := json.Unmarshal(apiError(t, err, http.StatusUnprocessableEntity).Data, &atomic); err != nil {
+		t.Fatalf("decode batch result: %v", err)
+	}
+	if got := fmt.Sprint(statuses(atomic.Items)); got != "[424 409 424]" || !atomic.Atomic || atomic.Failed != 3 {
+		t.Fatalf("atomic result: statuses %s, %+v", got, atomic)
+	}
+	if atomic.Items[0].ID != 0 || atomic.Items[1].Error == "" {
+		t.Fatalf("atomic items: %+v", atomic.Items)
+	}
+
+	// 校验失败时不执行任何操作
+	invalid := append([]client.BatchUserOperation{}, operations[0],
+		client.BatchUserOperation{Op: client.BatchOpCreate, User: &client.User{Username: "batch_mode_invalid", Email: "not-an-email"}})
+	_, err = c.BatchUsers(ctx, client.BatchUsersRequest{Mode: "atomic", Operations: invalid}, nil)
+	var skipped client.BatchResult
+	if err := json.Unmarshal(apiError(t, err, http.StatusUnprocessableEntity).Data, &skipped); err != nil {
+		t.Fatalf("decode batch result: %v", err)
+	}
+	if got := fmt.Sprint(statuses(skipped.Items)); got != "[424 400]" {
+		t.Fatalf("invalid batch statuses = %s", got)
+	}
+
+	// 部分成功模式：第一项已回滚，可以重新创建；失败项逐项报告
+	partial, err := c.BatchUsers(ctx, client.BatchUsersRequest{Mode: "partial", Operations: operations}, nil)
+	if err != nil {
+		t.Fatalf("partial batch: %v", err)
+	}
+	if got := fmt.Sprint(statuses(partial.Items)); got != "[201 409 404]" || partial.Atomic ||
+		partial.Succeeded != 1 || partial.Failed != 2 {
+		t.Fatalf("partial result: statuses %s, %+v", got, partial)
+	}
+	created := partial.Items[0]
+	if created.ID == 0 || created.Data == nil || created.Data.Username != "batch_mode_new" {
+		t.Fatalf("created item: %+v", created)
+	}
+	if _, err := c.GetUser(ctx, created.ID); err != nil {
+		t.Fatalf("get created user: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"echo-template/client"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestUsers_Authorization 修改用户需要认证，非管理员只能修改和删除自己，恢复、批量操作和查询已删除用户仅管理员
+func TestUsers_Authorization(t *testing.T) {
+	admin, _ := newTestClient(t)
+	ctx := context.Background()
+
+	alice, err := admin.CreateUser(ctx, client.User{Username: "authz_alice", Email: "authz_alice@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	bob, err := admin.CreateUser(ctx, client.User{Username: "authz_bob", Email: "authz_bob@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	e, _ := newServer()
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	anonymous := client.New(server.URL+"/api", client.WithRetry(client.NoRetry))
+	asAlice := client.New(server.URL+"/api", client.WithRetry(client.NoRetry),
+		client.WithAuth(client.APIKeyAuth(createUserAPIKey(t, uint(alice.ID)))))
+
+	// 未认证的请求可以查询，不能修改
+	if _, err := anonymous.GetUser(ctx, alice.ID); err != nil {
+		t.Fatalf("anonymous get user: %v", err)
+	}
+	_, err = anonymous.CreateUser(ctx, client.User{Username: "authz_anon", Email: "authz_anon@example.com"}, nil)
+	apiError(t, err, http.StatusUnauthorized)
+	takeover := *alice
+	takeover.Email = "attacker@example.com"
+	_, err = anonymous.UpdateUser(ctx, alice.ID, takeover, nil)
+	apiError(t, err, http.StatusUnauthorized)
+	_, err = anonymous.V2UpdateUser(ctx, alice.ID, client.UpdateUserRequest{Email: "attacker@example.com"}, nil)
+	apiError(t, err, http.StatusUnauthorized)
+	apiError(t, anonymous.DeleteUser(ctx, alice.ID, nil), http.StatusUnauthorized)
+	apiError(t, anonymous.V2DeleteUser(ctx, alice.ID, nil), http.StatusUnauthorized)
+
+	// 非管理员不能修改或删除其他用户
+	bobUpdate := *bob
+	bobUpdate.Name = "Hijacked"
+	_, err = asAlice.UpdateUser(ctx, bob.ID, bobUpdate, nil)
+	apiError(t, err, http.StatusForbidden)
+	_, err = asAlice.V2UpdateUser(ctx, bob.ID, client.UpdateUserRequest{DisplayName: "Hijacked", Version: bob.Version}, nil)
+	apiError(t, err, http.StatusForbidden)
+	apiError(t, asAlice.DeleteUser(ctx, bob.ID, nil), http.StatusForbidden)
+	apiError(t, asAlice.V2DeleteUser(ctx, bob.ID, nil), http.StatusForbidden)
+
+	// 仅管理员
+	_, err = asAlice.RestoreUser(ctx, bob.ID)
+	apiError(t, err, http.StatusForbidden)
+	_, err = asAlice.BatchUsers(ctx, client.BatchUsersRequest{}, nil)
+	apiError(t, err, http.StatusForbidden)
+	_, err = asAlice.ListUsers(ctx, &client.ListUsersParams{Trashed: "only"})
+	apiError(t, err, http.StatusForbidden)
+	_, err = anonymous.V2ListUsers(ctx, &client.V2ListUsersParams{Trashed: "with"})
+	apiError(t, err, http.StatusForbidden)
+	if _, err := admin.ListUsers(ctx, &client.ListUsersParams{Trashed: "only"}); err != nil {
+		t.Fatalf("admin list trashed users: %v", err)
+	}
+
+	// 用户可以修改和删除自己
+	self := *alice
+	self.Name = "Alice"
+	if _, err := asAlice.UpdateUser(ctx, alice.ID, self, nil); err != nil {
+		t.Fatalf("update self: %v", err)
+	}
+	if err := asAlice.DeleteUser(ctx, alice.ID, nil); err != nil {
+		t.Fatalf("delete self: %v", err)
+	}
+	if _, err := admin.RestoreUser(ctx, alice.ID); err != nil {
+		t.Fatalf("admin restore user: %v", err)
+	}
+}
+
+// TestUsers_VersionConflict 携带过期的 version 更新返回 409，data.current_version 为当前版本号，数据保持不变
+func TestUsers_VersionConflict(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	user, err := c.CreateUser(ctx, client.User{Username: "version_user", Email: "version_user@example.com"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if user.Version != 1 {
+		t.Fatalf("new user version = %d, want 1", user.Version)
+	}
+	currentVersion := func(err error) int64 {
+		t.Helper()
+		var data struct {
+			CurrentVersion int64 `json:"current_version"`
+		}
+		if err := json.Unmarshal(apiError(t, err, http.StatusConflict).Data, &data); err != nil {
+			t.Fatalf("decode conflict data: %v", err)
+		}
+		return data.CurrentVersion
+	}
+
+	first := *user
+	first.Name = "First"
+	updated, err := c.UpdateUser(ctx, user.ID, first, nil)
+	if err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("updated version = %d, want 2", updated.Version)
+	}
+
+	// 基于旧版本的修改被拒绝
+	stale := *user
+	stale.Name = "Stale"
+	_, err = c.UpdateUser(ctx, user.ID, stale, nil)
+	if got := currentVersion(err); got != 2 {
+		t.Fatalf("current_version = %d, want 2", got)
+	}
+	_, err = c.V2UpdateUser(ctx, user.ID, client.UpdateUserRequest{Username: user.Username, Email: user.Email,
+		DisplayName: "Stale", Version: user.Version}, nil)
+	if got := currentVersion(err); got != 2 {
+		t.Fatalf("v2 current_version = %d, want 2", got)
+	}
+	if current, err := c.GetUser(ctx, user.ID); err != nil || current.Name != "First" || current.Version != 2 {
+		t.Fatalf("user after conflicts: %+v, %v", current, err)
+	}
+
+	// 删除后恢复同样使版本号加一
+	if err := c.DeleteUser(ctx, user.ID, nil); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	restored, err := c.RestoreUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("restore user: %v", err)
+	}
+	if restored.Version != 3 {
+		t.Fatalf("restored version = %d, want 3", restored.Version)
+	}
+	_, err = c.UpdateUser(ctx, user.ID, *updated, nil)
+	if got := currentVersion(err); got != 3 {
+		t.Fatalf("current_version after restore = %d, want 3", got)
+	}
+}
+
+// TestUsers_Search 检索结果只包含匹配的用户，highlights 中匹配部分以 <mark> 标记，其余部分已做 HTML 转义
+func TestUsers_Search(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	for _, user := range []client.User{
+		{Username: "zyx_search", Email: "zyx_search@example.com", Name: "<Zyx> Smith"},
+		{Username: "search_other", Email: "search_other@example.com", Name: "Other"},
+	} {
+		if _, err := c.CreateUser(ctx, user, nil); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	// 3 个字符以上的检索词在启用 FTS5 时走全文索引，"zy" 在任何构建下都走 LIKE
+	for _, q := range []string{"zyx", "ZY"} {
+		page, err := c.SearchUsers(ctx, &client.SearchUsersParams{Q: q})
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		if page.Total != 1 || len(page.Items) != 1 || page.Items[0].User.Username != "zyx_search" {
+			t.Fatalf("search %q: %+v", q, page)
+		}
+		highlights := page.Items[0].Highlights
+		if !strings.HasPrefix(highlights["username"], "<mark>zy") || !strings.HasPrefix(highlights["email"], "<mark>zy") ||
+			!strings.HasPrefix(highlights["name"], "&lt;<mark>Zy") {
+			t.Fatalf("search %q highlights: %v", q, highlights)
+		}
+	}
+
+	_, err := c.SearchUsers(ctx, &client.SearchUsersParams{})
+	apiError(t, err, http.StatusBadRequest)
+}
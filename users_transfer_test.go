@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"echo-template/client"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestUsers_ExportImportRoundTrip 导出的文件修改后重新导入，只有修改的列发生变化；文件中没有的列保持原值
+func TestUsers_ExportImportRoundTrip(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	user, err := c.CreateUser(ctx, client.User{Username: "transfer_user", Email: "transfer_user@example.com",
+		Name: "=Transfer", Locale: "en-US"}, nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	id := strconv.FormatInt(user.ID, 10)
+
+	// CSV：导出后只修改姓名
+	file, err := c.ExportUsers(ctx, &client.ExportUsersParams{Format: "csv"})
+	if err != nil {
+		t.Fatalf("export csv: %v", err)
+	}
+	records, err := csv.NewReader(file.Body).ReadAll()
+	file.Body.Close()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	header := records[0]
+	column := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("export is missing column %q: %v", name, header)
+		return -1
+	}
+	var row []string
+	for _, record := range records[1:] {
+		if record[column("id")] == id {
+			row = record
+		}
+	}
+	if row == nil {
+		t.Fatalf("user %s not exported", id)
+	}
+	if row[column("locale")] != "en-US" {
+		t.Fatalf("exported locale = %q", row[column("locale")])
+	}
+	row[column("name")] = "Edited"
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.WriteAll([][]string{header, row})
+	importFile(t, c, "users.csv", &buf)
+
+	updated, err := c.GetUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if updated.Name != "Edited" || updated.Locale != "en-US" || updated.Email != user.Email ||
+		updated.Username != user.Username || updated.Role != user.Role || updated.Version != user.Version+1 {
+		t.Fatalf("unexpected user after csv import: %+v", updated)
+	}
+
+	// JSONL：删除 name 和 locale 后只修改邮箱，缺少的列不会被清空
+	file, err = c.ExportUsers(ctx, &client.ExportUsersParams{Format: "jsonl"})
+	if err != nil {
+		t.Fatalf("export jsonl: %v", err)
+	}
+	content, err := io.ReadAll(file.Body)
+	file.Body.Close()
+	if err != nil {
+		t.Fatalf("read jsonl: %v", err)
+	}
+	var line map[string]interface{}
+	for _, text := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			t.Fatalf("decode jsonl line %q: %v", text, err)
+		}
+		if object["id"] == float64(user.ID) {
+			line = object
+		}
+	}
+	if line == nil {
+		t.Fatalf("user %s not exported", id)
+	}
+	delete(line, "name")
+	delete(line, "locale")
+	line["email"] = "transfer_edited@example.com"
+	data, _ := json.Marshal(line)
+	importFile(t, c, "users.jsonl", bytes.NewReader(data))
+
+	updated, err = c.GetUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if updated.Email != "transfer_edited@example.com" || updated.Name != "Edited" || updated.Locale != "en-US" ||
+		updated.Version != user.Version+2 {
+		t.Fatalf("unexpected user after jsonl import: %+v", updated)
+	}
+}
+
+// importFile 以原子模式导入文件，要求全部行成功
+func importFile(t *testing.T, c *client.Client, name string, content io.Reader) *client.ImportResult {
+	t.Helper()
+	result, err := c.ImportUsers(context.Background(), client.ImportUsersForm{File: client.Upload{Name: name, Content: content}})
+	if err != nil {
+		t.Fatalf("import %s: %v", name, err)
+	}
+	if result.Failed != 0 || result.Succeeded == 0 {
+		t.Fatalf("import %s: %+v", name, result)
+	}
+	return result
+}
+
+// TestUsers_ImportErrors 每行单独报告结果，line 为文件中的行号；无法解析的行不影响其他行的行号
+func TestUsers_ImportErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+	lines := func(result *client.ImportResult) string {
+		parts := make([]string, len(result.Lines))
+		for i, line := range result.Lines {
+			if !line.Success && line.Error == "" {
+				t.Errorf("line %d failed without an error message", line.Line)
+			}
+			parts[i] = fmt.Sprintf("%d:%d", line.Line, line.Status)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	csvContent := strings.Join([]string{
+		"id,username,email,name,version",
+		",import_ok,import_ok@example.com,OK,",
+		"abc,import_bad_id,import_bad_id@example.com,,",
+		"5,import_no_version,import_no_version@example.com,,",
+		`,import_"quote,import_quote@example.com,,`,
+		",import_bad_email,not-an-email,,",
+		`,import_multiline,import_multiline@example.com,"two`,
+		`lines",`,
+		",import_ok,import_duplicate@example.com,,",
+	}, "\n")
+	result, err := c.ImportUsers(ctx, client.ImportUsersForm{
+		File: client.Upload{Name: "users.csv", Content: strings.NewReader(csvContent)},
+		Mode: "partial",
+	})
+	if err != nil {
+		t.Fatalf("import csv: %v", err)
+	}
+	if got, want := lines(result), "2:201 3:400 4:400 5:400 6:400 7:201 9:409"; got != want {
+		t.Fatalf("csv lines = %s, want %s", got, want)
+	}
+	if result.Succeeded != 2 || result.Failed != 5 || result.Atomic {
+		t.Fatalf("csv result: %+v", result)
+	}
+	if multiline := result.Lines[5].Data; multiline == nil || multiline.Name != "two\nlines" {
+		t.Fatalf("multiline record: %+v", multiline)
+	}
+
+	// 原子模式：有无法解析的行时不执行任何操作，其余行为 424；空行被跳过但计入行号
+	jsonl := strings.Join([]string{
+		`{"username":"import_atomic","email":"import_atomic@example.com"}`,
+		``,
+		`{"username":"import_broken",`,
+		`{"username":"import_atomic2","email":"import_atomic2@example.com"}`,
+	}, "\n")
+	_, err = c.ImportUsers(ctx, client.ImportUsersForm{File: client.Upload{Name: "users.jsonl", Content: strings.NewReader(jsonl)}})
+	var atomic client.ImportResult
+	if err := json.Unmarshal(apiError(t, err, http.StatusUnprocessableEntity).Data, &atomic); err != nil {
+		t.Fatalf("decode import result: %v", err)
+	}
+	if got, want := lines(&atomic), "1:424 3:400 4:424"; got != want {
+		t.Fatalf("jsonl lines = %s, want %s", got, want)
+	}
+	for q, want := range map[string]int64{"import_ok": 1, "import_atomic": 0} {
+		users, err := c.SearchUsers(ctx, &client.SearchUsersParams{Q: q})
+		if err != nil {
+			t.Fatalf("search users: %v", err)
+		}
+		if users.Total != want {
+			t.Fatalf("found %d users matching %q, want %d", users.Total, q, want)
+		}
+	}
+
+	// 缺少必需的列时整个文件被拒绝
+	_, err = c.ImportUsers(ctx, client.ImportUsersForm{File: client.Upload{Name: "users.csv",
+		Content: strings.NewReader("username,name\nimport_no_email,x\n")}})
+	apiError(t, err, http.StatusBadRequest)
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// API Key 格式：<前缀>_<标识>_<密钥>，例如 etk_3f9a1c2b_Jx...；
+// <前缀>_<标识> 为可见部分，明文保存并用于查找，密钥部分只保存 SHA-256 哈希
+const (
+	apiKeyIDBytes     = 4  // 标识的随机字节数，十六进制编码后为 8 个字符
+	apiKeySecretBytes = 32 // 密钥的随机字节数，熵足够高，无需加盐或使用 bcrypt
+)
+
+// GeneratedAPIKey 新生成的 API Key
+type GeneratedAPIKey struct {
+	Key        string // 完整的明文密钥，只在创建时返回给调用方
+	Prefix     string // 可见部分：<前缀>_<标识>
+	SecretHash string // 密钥部分的 SHA-256 哈希（十六进制）
+}
+
+// GenerateAPIKey 生成带有指定前缀的 API Key
+func GenerateAPIKey(prefix string) (GeneratedAPIKey, error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return GeneratedAPIKey{}, err
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return GeneratedAPIKey{}, err
+	}
+
+	visible := prefix + "_" + hex.EncodeToString(id)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return GeneratedAPIKey{
+		Key:        visible + "_" + encoded,
+		Prefix:     visible,
+		SecretHash: HashAPIKeySecret(encoded),
+	}, nil
+}
+
+// ParseAPIKey 拆分 API Key 的可见部分和密钥部分，格式不符或前缀不同时返回 false
+func ParseAPIKey(prefix, key string) (visible, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(key, prefix+"_")
+	if !ok {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(id) != hex.EncodedLen(apiKeyIDBytes) || secret == "" {
+		return "", "", false
+	}
+	return prefix + "_" + id, secret, true
+}
+
+// HashAPIKeySecret 计算密钥部分的哈希
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckAPIKeySecret 以固定耗时比较密钥部分与保存的哈希
+func CheckAPIKeySecret(hash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashAPIKeySecret(secret))) == 1
+}
+
+// ScopeAll 授予全部权限的范围
+const ScopeAll = "*"
+
+// APIKeyIdentity 通过 API Key 认证的调用方
+type APIKeyIdentity struct {
+	ID             uint     // 密钥ID
+	Prefix         string   // 密钥的可见部分
+	UserID         uint     // 所属用户，服务账号的密钥为 0
+	ServiceAccount string   // 所属服务账号
+	Role           string   // 所属用户或服务账号的角色
+	Locale         string   // 所属用户偏好的语言
+	Scopes         []string // 权限范围：<资源>:read、<资源>:write 或 *
+}
+
+// Allows 密钥是否具有资源的读或写权限，write 包含 read
+func (k *APIKeyIdentity) Allows(resource string, write bool) bool {
+	if slices.Contains(k.Scopes, ScopeAll) || slices.Contains(k.Scopes, resource+":write") {
+		return true
+	}
+	return !write && slices.Contains(k.Scopes, resource+":read")
+}
+
+// Covers 密钥是否具有 scopes 中的全部权限，用于限制通过 API Key 创建的密钥不超出自身的权限
+func (k *APIKeyIdentity) Covers(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == ScopeAll {
+			if !slices.Contains(k.Scopes, ScopeAll) {
+				return false
+			}
+			continue
+		}
+		resource, access, _ := strings.Cut(scope, ":")
+		if !k.Allows(resource, access == "write") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Binder 按 Content-Type 选择编解码器解析请求体，表单仍由 echo.DefaultBinder 处理
+// 路径参数和查询参数的绑定与 echo.DefaultBinder 相同
+type Binder struct {
+	echo.DefaultBinder
+}
+
+// Bind 依次绑定路径参数、查询参数（仅 GET/DELETE/HEAD）和请求体
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	method := c.Request().Method
+	if method == http.MethodGet || method == http.MethodDelete || method == http.MethodHead {
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	return b.BindBody(c, i)
+}
+
+// BindBody 解析请求体，没有对应的编解码器时返回 echo.ErrUnsupportedMediaType
+func (b *Binder) BindBody(c echo.Context, i interface{}) error {
+	req := c.Request()
+	if req.ContentLength == 0 {
+		return nil
+	}
+
+	contentType := req.Header.Get(echo.HeaderContentType)
+	if IsFormContentType(contentType) {
+		return b.DefaultBinder.BindBody(c, i)
+	}
+	codec, ok := CodecForContentType(contentType)
+	if !ok {
+		return echo.ErrUnsupportedMediaType
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(data, i); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+// IsFormContentType 是否为 application/x-www-form-urlencoded 或 multipart/form-data
+func IsFormContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == echo.MIMEApplicationForm || mediaType == echo.MIMEMultipartForm
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec 请求体和响应体的编解码器
+type Codec interface {
+	Name() string         // 格式名称，同时作为结构化语法后缀匹配 application/vnd.xxx+<name>
+	MediaTypes() []string // 支持的媒体类型，第一个用作响应的 Content-Type
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// 内置的格式
+const (
+	CodecJSON        = "json"
+	CodecXML         = "xml"
+	CodecMessagePack = "msgpack"
+	CodecCBOR        = "cbor"
+)
+
+// codecs 按优先级排列，第一个为默认格式（Accept 为空或 */* 时使用）
+// JSON 以外的内置格式都经由 JSON 的数据模型转换：字段名、可空字段和时间格式与 JSON 一致，
+// 结构体只需 json 标签，API 文档中的 schema 对每种格式都适用
+var codecs = []Codec{jsonCodec{}, xmlCodec{}, msgpackCodec{}, cborCodec{}}
+
+// RegisterCodec 注册编解码器，与已有格式同名时替换，须在启动服务之前调用
+func RegisterCodec(codec Codec) {
+	for i, existing := range codecs {
+		if existing.Name() == codec.Name() {
+			codecs[i] = codec
+			return
+		}
+	}
+	codecs = append(codecs, codec)
+}
+
+// MediaTypes 所有编解码器支持的媒体类型
+func MediaTypes() []string {
+	var types []string
+	for _, codec := range codecs {
+		types = append(types, codec.MediaTypes()...)
+	}
+	return types
+}
+
+// acceptRange Accept 中的一项
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// NegotiateCodec 按 Accept 请求头选择响应格式，按 q 值从高到低匹配，q 值相同时更具体的优先；
+// 支持 type/*、*/* 和结构化语法后缀（例如 application/vnd.echo-template.v2+json）。没有可用的格式时返回 false
+func NegotiateCodec(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return codecs[0], true
+	}
+
+	var ranges []acceptRange
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+
+	for _, r := range ranges {
+		if codec, ok := matchCodec(r.mediaType, true); ok {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// CodecForContentType 按请求的 Content-Type 选择编解码器
+func CodecForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	return matchCodec(mediaType, false)
+}
+
+// matchCodec 查找支持 mediaType 的编解码器，wildcard 为 true 时 mediaType 可以是 type/* 或 */*
+func matchCodec(mediaType string, wildcard bool) (Codec, bool) {
+	if wildcard && mediaType == "*/*" {
+		return codecs[0], true
+	}
+	for _, codec := range codecs {
+		for _, supported := range codec.MediaTypes() {
+			if supported == mediaType {
+				return codec, true
+			}
+			if prefix, ok := strings.CutSuffix(mediaType, "/*"); ok && wildcard && strings.HasPrefix(supported, prefix+"/") {
+				return codec, true
+			}
+		}
+	}
+	if _, suffix, ok := strings.Cut(mediaType, "+"); ok {
+		for _, codec := range codecs {
+			if codec.Name() == suffix {
+				return codec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// jsonValue 将 v 转换为 JSON 的数据模型（map[string]interface{}、[]interface{}、string、int64、float64、bool、nil）
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return jsonNumbers(value), nil
+}
+
+// jsonNumbers 整数转换为 int64，其余数字转换为 float64
+func jsonNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonNumbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// fromJSONValue 将 JSON 数据模型的值解析到 v，与直接解析 JSON 请求体的结果一致
+func fromJSONValue(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string         { return CodecJSON }
+func (jsonCodec) MediaTypes() []string { return []string{"application/json"} }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return CodecMessagePack }
+func (msgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(value)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	if err := msgpack.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return fromJSONValue(value, v)
+}
+
+// cborDecMode 解码为 map[string]interface{}，与 JSON 的数据模型一致
+var cborDecMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string         { return CodecCBOR }
+func (cborCodec) MediaTypes() []string { return []string{"application/cbor"} }
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(value)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	if err := cborDecMode.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return fromJSONValue(value, v)
+}
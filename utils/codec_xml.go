@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// XML 格式的元素名
+const (
+	xmlRootElement  = "response" // 根元素，请求体的根元素名不限
+	xmlItemElement  = "item"     // 数组的元素
+	xmlEntryElement = "entry"    // 键不是合法元素名的对象成员，键写在 key 属性中
+)
+
+// xmlCodec 将 JSON 的数据模型映射为 XML：对象的成员为子元素，数组的元素为 <item>，null 为带 nil="true" 属性的空元素
+// 解析请求体时按目标结构体的字段类型还原数字、布尔值和数组，未知的元素忽略
+type xmlCodec struct{}
+
+func (xmlCodec) Name() string         { return CodecXML }
+func (xmlCodec) MediaTypes() []string { return []string{"application/xml", "text/xml"} }
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := writeXML(encoder, xmlRootElement, "", value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	target := reflect.TypeOf(v)
+	if target == nil || target.Kind() != reflect.Pointer {
+		return fmt.Errorf("xml: Unmarshal(non-pointer %T)", v)
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("xml: missing root element")
+			}
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := readXML(decoder, start)
+			if err != nil {
+				return err
+			}
+			return fromJSONValue(root.value(target.Elem()), v)
+		}
+	}
+}
+
+// writeXML 写出名为 name 的元素，key 不为空时写入 key 属性
+func writeXML(encoder *xml.Encoder, name, key string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if key != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "key"}, Value: key})
+	}
+	if value == nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var err error
+			if isXMLName(k) {
+				err = writeXML(encoder, k, "", v[k])
+			} else {
+				err = writeXML(encoder, xmlEntryElement, k, v[k])
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := writeXML(encoder, xmlItemElement, "", item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case int64:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatInt(v, 10))); err != nil {
+			return err
+		}
+	case float64:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatFloat(v, 'g', -1, 64))); err != nil {
+			return err
+		}
+	case bool:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatBool(v))); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("xml: unsupported value %T", value)
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// isXMLName 是否可以直接用作元素名（不以 xml 开头、只含字母数字和 _-.）
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// xmlNode 解析后的 XML 元素
+type xmlNode struct {
+	name     string
+	key      string // entry 元素的 key 属性
+	null     bool   // nil="true"
+	text     string
+	children []*xmlNode
+}
+
+func readXML(decoder *xml.Decoder, start xml.StartElement) (*xmlNode, error) {
+	node := &xmlNode{name: start.Name.Local}
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "key":
+			node.key = attr.Value
+		case "nil":
+			node.null = attr.Value == "true"
+		}
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := readXML(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			node.text = strings.TrimSpace(text.String())
+			return node, nil
+		}
+	}
+}
+
+// memberName 作为对象成员时的名称
+func (n *xmlNode) memberName() string {
+	if n.name == xmlEntryElement && n.key != "" {
+		return n.key
+	}
+	return n.name
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// value 按目标类型 t 将元素转换为 JSON 的数据模型
+func (n *xmlNode) value(t reflect.Type) interface{} {
+	if n.null {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// time.Time、gorm.DeletedAt 等自定义解析的类型使用文本
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return n.text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		object := make(map[string]interface{}, len(n.children))
+		for _, child := range n.children {
+			if field, ok := fields[child.memberName()]; ok {
+				object[child.memberName()] = child.value(field)
+			}
+		}
+		return object
+	case reflect.Map:
+		object := make(map[string]interface{}, len(n.children))
+		for _, child := range n.children {
+			object[child.memberName()] = child.value(t.Elem())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return n.text
+		}
+		list := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			list = append(list, child.value(t.Elem()))
+		}
+		return list
+	case reflect.Interface:
+		if len(n.children) == 0 {
+			return n.text
+		}
+		if n.children[0].name == xmlItemElement {
+			return n.value(reflect.TypeOf([]interface{}(nil)))
+		}
+		return n.value(reflect.TypeOf(map[string]interface{}(nil)))
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		// 不是合法的数字或布尔值时保留为字符串，由 JSON 解析报告类型错误
+		if n.text != "" && json.Valid([]byte(n.text)) {
+			return json.RawMessage(n.text)
+		}
+		return n.text
+	default:
+		return n.text
+	}
+}
+
+// jsonFields 结构体按 JSON 字段名索引的字段类型，包括嵌入结构体提升的字段
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	// 外层字段优先于嵌入结构体中的同名字段
+	for _, ft := range embedded {
+		for name, fieldType := range jsonFields(ft) {
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldType
+			}
+		}
+	}
+	return fields
+}
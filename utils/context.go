@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"echo-template/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 上下文键（由认证等中间件写入）
+const (
+	ContextKeyUserID     = "user_id"
+	ContextKeyUserRole   = "user_role"
+	ContextKeyAPIKey     = "api_key"
+	ContextKeyAPIVersion = "api_version"
+	ContextKeyLocale     = "locale"      // 查询参数指定的语言
+	ContextKeyUserLocale = "user_locale" // 当前认证用户偏好的语言
+	contextKeyLocalizer  = "localizer"
+)
+
+// SetCurrentUserID 记录当前认证用户ID
+func SetCurrentUserID(c echo.Context, id uint) {
+	c.Set(ContextKeyUserID, id)
+}
+
+// CurrentUserID 获取当前认证用户ID，未认证时返回 false
+func CurrentUserID(c echo.Context) (uint, bool) {
+	id, ok := c.Get(ContextKeyUserID).(uint)
+	return id, ok && id != 0
+}
+
+// SetCurrentUserRole 记录当前认证用户的角色
+func SetCurrentUserRole(c echo.Context, role string) {
+	c.Set(ContextKeyUserRole, role)
+}
+
+// HasRole 当前认证用户是否具有指定角色
+func HasRole(c echo.Context, role string) bool {
+	current, _ := c.Get(ContextKeyUserRole).(string)
+	return current != "" && current == role
+}
+
+// SetCurrentAPIKey 记录请求使用的 API Key
+func SetCurrentAPIKey(c echo.Context, key *APIKeyIdentity) {
+	c.Set(ContextKeyAPIKey, key)
+}
+
+// CurrentAPIKey 请求使用的 API Key，未通过 API Key 认证时返回 false
+func CurrentAPIKey(c echo.Context) (*APIKeyIdentity, bool) {
+	key, ok := c.Get(ContextKeyAPIKey).(*APIKeyIdentity)
+	return key, ok && key != nil
+}
+
+// Authenticated 请求是否已认证：已识别用户，或使用了服务账号的 API Key
+func Authenticated(c echo.Context) bool {
+	if _, ok := CurrentUserID(c); ok {
+		return true
+	}
+	_, ok := CurrentAPIKey(c)
+	return ok
+}
+
+// SetAPIVersion 记录请求使用的 API 版本
+func SetAPIVersion(c echo.Context, version string) {
+	c.Set(ContextKeyAPIVersion, version)
+}
+
+// APIVersion 请求使用的 API 版本，例如 v2；不在 API 路径下时为空
+func APIVersion(c echo.Context) string {
+	version, _ := c.Get(ContextKeyAPIVersion).(string)
+	return version
+}
+
+// SetRequestLocale 记录查询参数指定的语言
+func SetRequestLocale(c echo.Context, locale string) {
+	c.Set(ContextKeyLocale, locale)
+	c.Set(contextKeyLocalizer, nil)
+}
+
+// SetUserLocale 记录当前认证用户偏好的语言，由认证中间件在加载用户后调用
+func SetUserLocale(c echo.Context, locale string) {
+	c.Set(ContextKeyUserLocale, locale)
+	c.Set(contextKeyLocalizer, nil)
+}
+
+// Localizer 按请求的语言渲染消息，语言的优先级：查询参数 > 用户偏好 > Accept-Language > 默认语言
+func Localizer(c echo.Context) *i18n.Localizer {
+	if localizer, ok := c.Get(contextKeyLocalizer).(*i18n.Localizer); ok {
+		return localizer
+	}
+	query, _ := c.Get(ContextKeyLocale).(string)
+	user, _ := c.Get(ContextKeyUserLocale).(string)
+	localizer := i18n.NewLocalizer(query, user, c.Request().Header.Get(HeaderAcceptLanguage))
+	c.Set(contextKeyLocalizer, localizer)
+	return localizer
+}
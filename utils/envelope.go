@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 响应包装相关的请求头和响应头
+const (
+	HeaderPrefer            = "Prefer"             // RFC 7240，Prefer: envelope=false 请求不包装的响应
+	HeaderPreferenceApplied = "Preference-Applied" // 已采纳的偏好
+)
+
+// preferEnvelope Prefer 中控制响应包装的偏好名
+const preferEnvelope = "envelope"
+
+// 上下文键
+const (
+	contextKeyEnvelope  = "envelope"
+	contextKeyEnveloped = "enveloped"
+)
+
+// Envelope 响应包装方式：{code, data, msg[, meta]}
+type Envelope struct {
+	Enabled     bool   // 成功响应是否包装；关闭时响应体即 data，错误响应始终包装
+	AllowPrefer bool   // 是否允许客户端以 Prefer: envelope=true|false 覆盖 Enabled
+	CodeField   string // 为空时不输出 code（与 HTTP 状态码重复）
+	DataField   string
+	MsgField    string
+	MetaField   string // Meta 为 true 时输出 meta：请求ID和分页信息
+	Meta        bool
+}
+
+// DefaultEnvelope 默认的包装方式，与 API 文档中的 utils.Response 一致
+var DefaultEnvelope = Envelope{
+	Enabled:     true,
+	AllowPrefer: true,
+	CodeField:   "code",
+	DataField:   "data",
+	MsgField:    "msg",
+	MetaField:   "meta",
+}
+
+// documented 字段名是否与 API 文档一致
+func (e Envelope) documented() bool {
+	return e.CodeField == DefaultEnvelope.CodeField &&
+		e.DataField == DefaultEnvelope.DataField &&
+		e.MsgField == DefaultEnvelope.MsgField
+}
+
+// SetEnvelope 设置当前请求的包装方式
+func SetEnvelope(c echo.Context, envelope Envelope) {
+	c.Set(contextKeyEnvelope, envelope)
+}
+
+// CurrentEnvelope 当前请求的包装方式，未设置时为 DefaultEnvelope
+func CurrentEnvelope(c echo.Context) Envelope {
+	if envelope, ok := c.Get(contextKeyEnvelope).(Envelope); ok {
+		return envelope
+	}
+	return DefaultEnvelope
+}
+
+// Enveloped 已写出的响应是否为 API 文档中的包装格式（包装且字段名未自定义）
+func Enveloped(c echo.Context) bool {
+	enveloped, ok := c.Get(contextKeyEnveloped).(bool)
+	return !ok || enveloped
+}
+
+// Meta 响应的 meta 块
+type Meta struct {
+	RequestID  string          `json:"request_id,omitempty"`
+	Pagination *PaginationMeta `json:"pagination,omitempty"`
+}
+
+// PaginationMeta 分页信息
+type PaginationMeta struct {
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// envelopeField 包装中的一个字段
+type envelopeField struct {
+	name  string
+	value interface{}
+}
+
+// envelopeBody 按字段顺序序列化的包装对象
+type envelopeBody []envelopeField
+
+func (b envelopeBody) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range b {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// applies 成功响应是否包装，客户端的 Prefer 优先于路由组和全局配置
+func (e Envelope) applies(c echo.Context) bool {
+	if !e.AllowPrefer {
+		return e.Enabled
+	}
+	c.Response().Header().Add(echo.HeaderVary, HeaderPrefer)
+	preferred, ok := envelopePreference(c.Request().Header.Values(HeaderPrefer))
+	if !ok {
+		return e.Enabled
+	}
+	c.Response().Header().Set(HeaderPreferenceApplied, preferEnvelope+"="+strconv.FormatBool(preferred))
+	return preferred
+}
+
+// envelopePreference 解析 Prefer 中的 envelope 偏好，例如 Prefer: envelope=false, wait=10
+func envelopePreference(values []string) (bool, bool) {
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			token, _, _ := strings.Cut(item, ";")
+			name, v, _ := strings.Cut(token, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), preferEnvelope) {
+				continue
+			}
+			if enabled, err := strconv.ParseBool(strings.Trim(strings.TrimSpace(v), `"`)); err == nil {
+				return enabled, true
+			}
+		}
+	}
+	return false, false
+}
+
+// wrap 构造包装对象，withData 为 false 时不输出 data（删除等没有数据的响应）；
+// omitNil 为 true 时 data 为 nil 也不输出（错误响应）
+func (e Envelope) wrap(c echo.Context, statusCode int, data interface{}, withData, omitNil bool, msg string) envelopeBody {
+	body := make(envelopeBody, 0, 4)
+	if e.CodeField != "" {
+		body = append(body, envelopeField{e.CodeField, statusCode})
+	}
+	if withData && !(omitNil && data == nil) {
+		body = append(body, envelopeField{e.DataField, data})
+	}
+	body = append(body, envelopeField{e.MsgField, msg})
+	if e.Meta {
+		if meta := responseMeta(c, data); meta != (Meta{}) {
+			body = append(body, envelopeField{e.MetaField, meta})
+		}
+	}
+	return body
+}
+
+// responseMeta 请求ID取自 X-Request-Id 响应头，分页信息取自 PageResult
+func responseMeta(c echo.Context, data interface{}) Meta {
+	meta := Meta{RequestID: c.Response().Header().Get(echo.HeaderXRequestID)}
+	var page *PageResult
+	switch v := data.(type) {
+	case PageResult:
+		page = &v
+	case *PageResult:
+		page = v
+	}
+	if page != nil {
+		meta.Pagination = &PaginationMeta{Total: page.Total, Page: page.Page, PageSize: page.PageSize}
+	}
+	return meta
+}
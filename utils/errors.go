@@ -1,56 +1,124 @@
 package utils
 
 import (
+	"echo-template/i18n"
 	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
 
-// AppError 应用错误
+// AppError 应用错误，消息在写出响应时按请求的语言渲染
 type AppError struct {
 	Code    int
-	Message string
+	Key     string    // 消息键，见 i18n/locales
+	Args    i18n.Args // 消息的插值参数
 	Err     error
+	Details interface{} // 附加信息，随错误响应一并返回
 }
 
 func (e *AppError) Error() string {
 	if e.Err != nil {
 		return e.Err.Error()
 	}
-	return e.Message
+	return e.Message()
 }
 
-// NewAppError 创建应用错误
-func NewAppError(code int, message string, err error) *AppError {
-	return &AppError{
-		Code:    code,
-		Message: message,
-		Err:     err,
+// Unwrap 返回底层错误，便于 errors.As 判断驱动错误
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Message 按默认语言渲染的消息，用于日志和命令行
+func (e *AppError) Message() string {
+	return i18n.T(e.Key, e.Args)
+}
+
+// I18nMessage 错误的消息键和插值参数
+func (e *AppError) I18nMessage() i18n.Message {
+	return i18n.Message{Key: e.Key, Args: e.Args}
+}
+
+// NewAppError 创建应用错误，key 为消息键
+func NewAppError(code int, key string, err error, args ...i18n.Args) *AppError {
+	appErr := &AppError{
+		Code: code,
+		Key:  key,
+		Err:  err,
 	}
+	for _, a := range args {
+		if appErr.Args == nil {
+			appErr.Args = make(i18n.Args, len(a))
+		}
+		for name, value := range a {
+			appErr.Args[name] = value
+		}
+	}
+	return appErr
 }
 
 // ErrBadRequest 400 错误
-func ErrBadRequest(message string) *AppError {
-	return NewAppError(http.StatusBadRequest, message, nil)
+func ErrBadRequest(key string, args ...i18n.Args) *AppError {
+	return NewAppError(http.StatusBadRequest, key, nil, args...)
+}
+
+// ErrInvalidParam 400 错误，参数 name 的值无效
+func ErrInvalidParam(name string) *AppError {
+	return ErrBadRequest("request.invalid_param", i18n.Args{"Param": name})
+}
+
+// ErrUnauthorized 401 错误
+func ErrUnauthorized(key string, args ...i18n.Args) *AppError {
+	return NewAppError(http.StatusUnauthorized, key, nil, args...)
+}
+
+// ErrForbidden 403 错误
+func ErrForbidden(key string, args ...i18n.Args) *AppError {
+	return NewAppError(http.StatusForbidden, key, nil, args...)
 }
 
 // ErrNotFound 404 错误
-func ErrNotFound(message string) *AppError {
-	return NewAppError(http.StatusNotFound, message, nil)
+func ErrNotFound(key string, args ...i18n.Args) *AppError {
+	return NewAppError(http.StatusNotFound, key, nil, args...)
+}
+
+// ErrConflict 409 错误，details 会作为错误响应的 data 返回
+func ErrConflict(key string, details interface{}, args ...i18n.Args) *AppError {
+	appErr := NewAppError(http.StatusConflict, key, nil, args...)
+	appErr.Details = details
+	return appErr
 }
 
 // ErrInternal 500 错误
-func ErrInternal(message string, err error) *AppError {
-	return NewAppError(http.StatusInternalServerError, message, err)
+func ErrInternal(key string, err error) *AppError {
+	return NewAppError(http.StatusInternalServerError, key, err)
+}
+
+// ErrNotAcceptable 406 错误，Accept 中没有可用的响应格式
+func ErrNotAcceptable() *AppError {
+	err := NewAppError(http.StatusNotAcceptable, "request.not_acceptable", nil)
+	err.Details = map[string][]string{"supported": MediaTypes()}
+	return err
+}
+
+// ErrUnsupportedMediaType 415 错误，请求体的格式不受支持
+func ErrUnsupportedMediaType() *AppError {
+	err := NewAppError(http.StatusUnsupportedMediaType, "request.unsupported_media_type", nil)
+	err.Details = map[string][]string{"supported": MediaTypes()}
+	return err
+}
+
+// ErrRequestTooLarge 413 错误，请求体超过大小上限
+func ErrRequestTooLarge() *AppError {
+	return NewAppError(http.StatusRequestEntityTooLarge, "request.too_large", nil)
 }
 
 // HandleError 处理错误并返回响应
 func HandleError(c echo.Context, err error) error {
 	var appErr *AppError
 	if errors.As(err, &appErr) {
-		return Error(c, appErr.Code, appErr.Message)
+		return ErrorWithData(c, appErr.Code, appErr.Key, appErr.Details, appErr.Args)
 	}
 	// 默认返回 500 错误
-	return ErrorInternal(c, "内部服务器错误")
+	return ErrorInternal(c, "error.internal")
 }
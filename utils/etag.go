@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 条件请求相关请求头
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// ETagEncodingSuffixes 压缩中间件会在强 ETag 后追加编码后缀（如 "xxx-gzip"），比较时忽略
+var ETagEncodingSuffixes = []string{"-gzip", "-br", "-zstd"}
+
+// formatETag JSON 以外的响应格式在 ETag 后追加格式后缀（如 "xxx-msgpack"），不同表示的强 ETag 不同；
+// 数据相同时各格式视为同一版本，比较时忽略
+func formatETag(c echo.Context, etag string) string {
+	codec, ok := NegotiateCodec(c.Request().Header.Get(echo.HeaderAccept))
+	if !ok || codec.Name() == CodecJSON {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + codec.Name() + `"`
+}
+
+// ETag 根据序列化后的数据计算强 ETag
+// 只使用 Response 中的 data 部分，提示信息变化不会影响缓存和并发控制
+func ETag(data interface{}) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// CheckIfMatch 校验 If-Match 请求头，current 为资源当前的数据
+// 未携带 If-Match 时直接通过；不匹配时返回 412，防止覆盖他人的修改
+func CheckIfMatch(c echo.Context, current interface{}) error {
+	ifMatch := c.Request().Header.Get(HeaderIfMatch)
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag, err := ETag(current)
+	if err != nil {
+		return ErrInternal("error.etag", err)
+	}
+	if !etagMatches(ifMatch, etag, false) {
+		return NewAppError(http.StatusPreconditionFailed, "resource.precondition_failed", nil)
+	}
+	return nil
+}
+
+// notModified 判断 GET/HEAD 请求的 If-None-Match 是否命中
+func notModified(c echo.Context, etag string) bool {
+	method := c.Request().Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	ifNoneMatch := c.Request().Header.Get(HeaderIfNoneMatch)
+	return ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true)
+}
+
+// etagMatches 比较请求头中的 ETag 列表，weak 为 true 时使用弱比较（If-None-Match）
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if trimETagSuffix(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// trimETagSuffix 去掉编码后缀和格式后缀，例如 "xxx-msgpack-gzip" -> "xxx"
+func trimETagSuffix(etag string) string {
+	for _, suffix := range ETagEncodingSuffixes {
+		if trimmed, ok := strings.CutSuffix(etag, suffix+`"`); ok {
+			etag = trimmed + `"`
+			break
+		}
+	}
+	for _, codec := range codecs {
+		if trimmed, ok := strings.CutSuffix(etag, "-"+codec.Name()+`"`); ok {
+			return trimmed + `"`
+		}
+	}
+	return etag
+}
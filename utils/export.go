@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// 导入导出格式
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// 导出格式对应的 Content-Type
+var exportContentTypes = map[string]string{
+	FormatCSV:   "text/csv; charset=utf-8",
+	FormatJSONL: "application/x-ndjson",
+	FormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ExportContentType 返回导出格式的 Content-Type，不支持的格式返回空字符串
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
+
+// RowWriter 按行写出表格数据，写完后必须调用 Close
+// 单元格支持字符串、整数、浮点数、布尔值、time.Time 和 nil
+type RowWriter interface {
+	WriteRow(values []interface{}) error
+	Flush() error
+	Close() error
+}
+
+// NewRowWriter 创建指定格式的 RowWriter，columns 为表头（JSONL 中作为字段名）
+func NewRowWriter(w io.Writer, format string, columns []string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowWriter(w, columns)
+	case FormatJSONL:
+		return &jsonlRowWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXRowWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// formatCell 将单元格转换为文本，时间统一使用 RFC 3339
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvFormulaPrefixes 以这些字符开头的单元格会被电子表格当作公式执行（CSV 注入）；
+// 以 ' 开头的单元格同样需要转义，否则导入时无法区分原值中的 ' 和转义添加的前缀
+const csvFormulaPrefixes = "=+-@\t\r'"
+
+// EscapeCSVCell 单元格以公式字符开头时在前面加 '，电子表格会将其作为文本显示
+func EscapeCSVCell(s string) string {
+	if s != "" && strings.IndexByte(csvFormulaPrefixes, s[0]) >= 0 {
+		return "'" + s
+	}
+	return s
+}
+
+// UnescapeCSVCell 去掉 EscapeCSVCell 添加的前缀，导出的 CSV 编辑后再导入时还原原值
+func UnescapeCSVCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.IndexByte(csvFormulaPrefixes, s[1]) >= 0 {
+		return s[1:]
+	}
+	return s
+}
+
+// csvRowWriter 字符串单元格经 EscapeCSVCell 转义，数值等其他类型不会被当作公式
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer, columns []string) (*csvRowWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvRowWriter{w: cw}, nil
+}
+
+func (cw *csvRowWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatCell(value)
+		if _, ok := value.(string); ok {
+			record[i] = EscapeCSVCell(record[i])
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvRowWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvRowWriter) Close() error {
+	return cw.Flush()
+}
+
+// jsonlRowWriter 每行一个 JSON 对象，字段顺序与表头一致
+type jsonlRowWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (jw *jsonlRowWriter) WriteRow(values []interface{}) error {
+	jw.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(jw.columns[i])
+		val, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(key)
+		jw.w.WriteByte(':')
+		jw.w.Write(val)
+	}
+	jw.w.WriteString("}\n")
+	return nil
+}
+
+func (jw *jsonlRowWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+func (jw *jsonlRowWriter) Close() error {
+	return jw.Flush()
+}
+
+// xlsxRowWriter 流式生成只有一个工作表的 xlsx 文件
+// 字符串使用内联字符串，不需要共享字符串表，因此无需在内存中保留所有行
+type xlsxRowWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+// xlsx 固定的包结构
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func newXLSXRowWriter(w io.Writer, columns []string) (*xlsxRowWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// 工作表必须是最后一个条目，之后的行直接追加到其中
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxRowWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	xw.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := xw.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxRowWriter) WriteRow(values []interface{}) error {
+	xw.row++
+	fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.row)
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			xw.sheet.WriteString(`<c/>`)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			fmt.Fprintf(xw.sheet, `<c t="n"><v>%v</v></c>`, v)
+		case bool:
+			bit := "0"
+			if v {
+				bit = "1"
+			}
+			xw.sheet.WriteString(`<c t="b"><v>` + bit + `</v></c>`)
+		default:
+			var escaped strings.Builder
+			if err := xml.EscapeText(&escaped, []byte(formatCell(v))); err != nil {
+				return err
+			}
+			xw.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">` + escaped.String() + `</t></is></c>`)
+		}
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxRowWriter) Flush() error {
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Flush()
+}
+
+func (xw *xlsxRowWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+// TestCSVRowWriter_FormulaInjection 公式字符开头的字符串加 ' 前缀，去掉前缀后与原值一致
+func TestCSVRowWriter_FormulaInjection(t *testing.T) {
+	cells := []string{
+		"=HYPERLINK(\"http://evil\")", "+1", "-2+3", "@SUM(A1)", "\tcmd", "\rcmd",
+		"'quoted", "''", "'", "plain", "a=b", "",
+	}
+	escaped := map[string]string{
+		"=HYPERLINK(\"http://evil\")": "'=HYPERLINK(\"http://evil\")",
+		"+1":                          "'+1",
+		"-2+3":                        "'-2+3",
+		"@SUM(A1)":                    "'@SUM(A1)",
+		"\tcmd":                       "'\tcmd",
+		"\rcmd":                       "'\rcmd",
+		"'quoted":                     "''quoted",
+		"''":                          "'''",
+		"'":                           "''",
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewRowWriter(&buf, FormatCSV, []string{"value", "number"})
+	if err != nil {
+		t.Fatalf("NewRowWriter: %v", err)
+	}
+	for _, cell := range cells {
+		if err := writer.WriteRow([]interface{}{cell, -5}); err != nil {
+			t.Fatalf("WriteRow(%q): %v", cell, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != len(cells)+1 {
+		t.Fatalf("got %d records, want %d", len(records), len(cells)+1)
+	}
+	for i, cell := range cells {
+		record := records[i+1]
+		want, ok := escaped[cell]
+		if !ok {
+			want = cell
+		}
+		if record[0] != want {
+			t.Errorf("exported %q as %q, want %q", cell, record[0], want)
+		}
+		if got := UnescapeCSVCell(record[0]); got != cell {
+			t.Errorf("round trip of %q = %q", cell, got)
+		}
+		// 数值不会被当作公式，保持原样
+		if record[1] != "-5" {
+			t.Errorf("number exported as %q, want -5", record[1])
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"echo-template/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 语言相关的请求头和响应头
+const (
+	HeaderAcceptLanguage  = "Accept-Language"
+	HeaderContentLanguage = "Content-Language"
+)
+
+// Localizable 响应数据中包含按请求语言渲染的消息（例如批量操作的逐项错误），写出响应前调用
+type Localizable interface {
+	Localize(localizer *i18n.Localizer)
+}
+
+// localize 渲染响应数据中的消息，并设置 Content-Language
+func localize(c echo.Context, data interface{}) *i18n.Localizer {
+	localizer := Localizer(c)
+	header := c.Response().Header()
+	header.Set(HeaderContentLanguage, localizer.Language().String())
+	header.Add(echo.HeaderVary, HeaderAcceptLanguage)
+	if l, ok := data.(Localizable); ok {
+		l.Localize(localizer)
+	}
+	return localizer
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 分页默认值
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Pagination 分页参数
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+// Offset 当前页的偏移量
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// PageResult 分页响应数据
+// @Description 分页数据
+type PageResult struct {
+	Items    interface{} `json:"items"`
+	Total    int64       `json:"total" example:"100"`
+	Page     int         `json:"page" example:"1"`
+	PageSize int         `json:"page_size" example:"20"`
+}
+
+// NewPageResult 创建分页响应数据
+func NewPageResult(items interface{}, total int64, page Pagination) PageResult {
+	return PageResult{
+		Items:    items,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	}
+}
+
+// ParsePagination 解析 page、page_size 查询参数
+func ParsePagination(c echo.Context) (Pagination, error) {
+	page := Pagination{Page: 1, PageSize: DefaultPageSize}
+
+	if value := c.QueryParam("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return page, ErrInvalidParam("page")
+		}
+		page.Page = n
+	}
+	if value := c.QueryParam("page_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxPageSize {
+			return page, ErrInvalidParam("page_size")
+		}
+		page.PageSize = n
+	}
+	return page, nil
+}
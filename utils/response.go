@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"echo-template/i18n"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -24,42 +25,53 @@ type SuccessResponse struct {
 // ErrorResponse 错误响应
 // @Description 错误响应
 type ErrorResponse struct {
-	Code int    `json:"code" example:"400"`
-	Msg  string `json:"msg" example:"操作失败"`
+	Code int         `json:"code" example:"400"`
+	Data interface{} `json:"data,omitempty"`
+	Msg  string      `json:"msg" example:"操作失败"`
 }
 
-// Success 成功响应（通用，适用于任何数据类型）
-func Success(c echo.Context, data interface{}, msg string) error {
-	return c.JSON(http.StatusOK, Response{
-		Code: http.StatusOK,
-		Data: data,
-		Msg:  msg,
-	})
+// Success 成功响应（通用，适用于任何数据类型），msg 为消息键，args 为插值参数
+// 响应携带强 ETag，GET/HEAD 请求的 If-None-Match 命中时返回 304
+func Success(c echo.Context, data interface{}, msg string, args ...i18n.Args) error {
+	localizer := localize(c, data)
+	etag, err := ETag(data)
+	if err != nil {
+		return HandleError(c, ErrInternal("error.serialize_response", err))
+	}
+	c.Response().Header().Set(HeaderETag, formatETag(c, etag))
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return renderSuccess(c, http.StatusOK, data, true, localizer.T(msg, args...))
 }
 
 // SuccessCreated 创建成功响应（通用）
-func SuccessCreated(c echo.Context, data interface{}, msg string) error {
-	return c.JSON(http.StatusCreated, Response{
-		Code: http.StatusCreated,
-		Data: data,
-		Msg:  msg,
-	})
+func SuccessCreated(c echo.Context, data interface{}, msg string, args ...i18n.Args) error {
+	localizer := localize(c, data)
+	if etag, err := ETag(data); err == nil {
+		c.Response().Header().Set(HeaderETag, formatETag(c, etag))
+	}
+	return renderSuccess(c, http.StatusCreated, data, true, localizer.T(msg, args...))
+}
+
+// SuccessNoContent 无内容成功响应（用于删除等操作），不包装时返回 204
+func SuccessNoContent(c echo.Context, msg string, args ...i18n.Args) error {
+	return renderSuccess(c, http.StatusOK, nil, false, localize(c, nil).T(msg, args...))
 }
 
-// SuccessNoContent 无内容成功响应（用于删除等操作）
-func SuccessNoContent(c echo.Context, msg string) error {
-	return c.JSON(http.StatusOK, SuccessResponse{
-		Code: http.StatusOK,
-		Msg:  msg,
-	})
+// Error 错误响应，msg 为消息键
+func Error(c echo.Context, statusCode int, msg string, args ...i18n.Args) error {
+	return ErrorWithData(c, statusCode, msg, nil, args...)
 }
 
-// Error 错误响应
-func Error(c echo.Context, statusCode int, msg string) error {
-	return c.JSON(statusCode, ErrorResponse{
-		Code: statusCode,
-		Msg:  msg,
-	})
+// ErrorWithData 带附加信息的错误响应（例如版本冲突时返回当前版本号）
+// 错误响应不受 Prefer 和路由组设置影响，始终包装
+func ErrorWithData(c echo.Context, statusCode int, msg string, data interface{}, args ...i18n.Args) error {
+	localizer := localize(c, data)
+	envelope := CurrentEnvelope(c)
+	c.Set(contextKeyEnveloped, envelope.documented())
+	return render(c, statusCode, envelope.wrap(c, statusCode, data, true, true, localizer.T(msg, args...)))
 }
 
 // ErrorBadRequest 400 错误响应
@@ -76,3 +88,37 @@ func ErrorNotFound(c echo.Context, msg string) error {
 func ErrorInternal(c echo.Context, msg string) error {
 	return Error(c, http.StatusInternalServerError, msg)
 }
+
+// renderSuccess 按当前请求的包装方式写出成功响应，不包装时响应体即 data
+func renderSuccess(c echo.Context, statusCode int, data interface{}, withData bool, msg string) error {
+	envelope := CurrentEnvelope(c)
+	if envelope.applies(c) {
+		c.Set(contextKeyEnveloped, envelope.documented())
+		return render(c, statusCode, envelope.wrap(c, statusCode, data, withData, false, msg))
+	}
+	c.Set(contextKeyEnveloped, false)
+	if !withData {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return render(c, statusCode, data)
+}
+
+// render 按 Accept 协商的格式写出响应
+// 没有可用的格式时，成功响应改为 406；错误响应仍以默认格式（JSON）返回
+func render(c echo.Context, statusCode int, body interface{}) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	codec, ok := NegotiateCodec(c.Request().Header.Get(echo.HeaderAccept))
+	if !ok {
+		if statusCode < http.StatusBadRequest {
+			c.Response().Header().Del(HeaderETag)
+			return HandleError(c, ErrNotAcceptable())
+		}
+		codec = codecs[0]
+	}
+
+	data, err := codec.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.Blob(statusCode, codec.MediaTypes()[0], data)
+}
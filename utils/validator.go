@@ -1,17 +1,49 @@
 package utils
 
 import (
+	"errors"
+	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
+// validate 使用模型上的 binding 标签校验，例如 binding:"required,email"
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("binding")
+	// 错误中使用 JSON 字段名
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// FieldError 字段校验错误
+// @Description 字段校验错误
+type FieldError struct {
+	Field string `json:"field" example:"email"`
+	Rule  string `json:"rule" example:"email"`
+}
+
 // ParseUintParam 解析路径中的 uint 参数
 func ParseUintParam(c echo.Context, paramName string) (uint, error) {
 	idStr := c.Param(paramName)
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		return 0, ErrBadRequest("Invalid " + paramName)
+		return 0, ErrInvalidParam(paramName)
 	}
 	return uint(id), nil
 }
@@ -19,8 +51,37 @@ func ParseUintParam(c echo.Context, paramName string) (uint, error) {
 // BindAndValidate 绑定并验证请求体
 func BindAndValidate(c echo.Context, dest interface{}) error {
 	if err := c.Bind(dest); err != nil {
-		return ErrBadRequest("Invalid request body")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrRequestTooLarge()
+		}
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return ErrUnsupportedMediaType()
+		}
+		return ErrBadRequest("request.invalid_body")
+	}
+	return Validate(dest)
+}
+
+// Validate 按 binding 标签校验结构体，失败时返回 400，Details 为 []FieldError
+func Validate(value interface{}) error {
+	err := validate.Struct(value)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return ErrBadRequest("request.invalid_body")
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// 去掉顶层结构体名，保留嵌套路径，例如 operations[0].user.email
+		_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+		fields = append(fields, FieldError{Field: path, Rule: fieldErr.Tag()})
 	}
-	// 可以在这里添加验证逻辑，例如使用 validator 库
-	return nil
+	appErr := ErrBadRequest("request.validation_failed")
+	appErr.Details = fields
+	return appErr
 }